@@ -1,7 +1,7 @@
 package main
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -9,6 +9,7 @@ import (
 	"github.com/urfave/cli/v2"
 	"github.com/yetanotherco/aligned_layer/aggregator/internal/pkg"
 	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/utils"
 )
 
 var (
@@ -49,15 +50,14 @@ func aggregatorMain(ctx *cli.Context) error {
 		return err
 	}
 
-	// Listen for new task created in the ServiceManager contract in a separate goroutine
-	go func() {
-		listenErr := aggregator.SubscribeToNewTasks()
-		if listenErr != nil {
-			aggregatorConfig.BaseConfig.Logger.Fatal("Error subscribing for new tasks", "err", listenErr)
-		}
-	}()
+	// SIGINT and SIGTERM stop the aggregator after the work in process is finished
+	shutdownCtx, stop := utils.NewShutdownContext()
+	defer stop()
 
-	err = aggregator.Start(context.Background())
+	err = aggregator.Start(shutdownCtx)
+	if errors.Is(err, utils.ErrShutdownTimeout) {
+		return cli.Exit(err.Error(), utils.ExitCodeShutdownTimeout)
+	}
 	if err != nil {
 		return err
 	}
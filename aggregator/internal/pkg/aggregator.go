@@ -3,21 +3,26 @@ package pkg
 import (
 	"context"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"math/big"
 	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yetanotherco/aligned_layer/aggregator/internal/store"
 	"github.com/yetanotherco/aligned_layer/metrics"
 
-	"github.com/Layr-Labs/eigensdk-go/chainio/clients"
-	sdkclients "github.com/Layr-Labs/eigensdk-go/chainio/clients"
 	"github.com/Layr-Labs/eigensdk-go/logging"
 	"github.com/Layr-Labs/eigensdk-go/services/avsregistry"
+	"github.com/Layr-Labs/eigensdk-go/signer"
 	blsagg "github.com/Layr-Labs/eigensdk-go/services/bls_aggregation"
 	oppubkeysserv "github.com/Layr-Labs/eigensdk-go/services/operatorpubkeys"
 	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/common/lru"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
 	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
 	"github.com/yetanotherco/aligned_layer/core/chainio"
 	"github.com/yetanotherco/aligned_layer/core/config"
@@ -25,10 +30,6 @@ import (
 	"github.com/yetanotherco/aligned_layer/core/utils"
 )
 
-// FIXME(marian): Read this from Aligned contract directly
-const QUORUM_NUMBER = byte(0)
-const QUORUM_THRESHOLD = byte(67)
-
 // Aggregator stores TaskResponse for a task here
 type TaskResponses = []types.SignedTaskResponse
 
@@ -43,26 +44,57 @@ type Aggregator struct {
 
 	// BLS Signature Service returns an Index
 	// Since our ID is not an idx, we build this cache
-	// Note: In case of a reboot, this doesn't need to be loaded,
-	// and can start from zero
+	// Note: In case of a reboot, unfinished tasks are loaded from the task store
 	batchesRootByIdx map[uint32][32]byte
 
 	// This is the counterpart,
 	// to use when we have the batch but not the index
-	// Note: In case of a reboot, this doesn't need to be loaded,
-	// and can start from zero
+	// Note: In case of a reboot, unfinished tasks are loaded from the task store.
+	// Tasks are removed once responded or expired
 	batchesIdxByRoot map[[32]byte]uint32
 
 	// This task index is to communicate with the local BLS
 	// Service.
-	// Note: In case of a reboot it can start from 0 again
+	// Note: In case of a reboot it is loaded from the task store
 	nextBatchIndex uint32
 
-	// Mutex to protect batchesRootByIdx, batchesIdxByRoot and nextBatchIndex
+	// Persists tasks, received signatures and responses so in-flight
+	// batches are not lost on a restart
+	taskStore *store.TaskStore
+
+	// Serializes the signatures of each task, so an operator can't be added twice.
+	// Created with the task, like batchesIdxByRoot
+	taskSignatures map[[32]byte]*taskSignatures
+
+	// How long an RPC call waits for the BLS aggregation service to process a signature
+	blsSignatureTimeout time.Duration
+
+	// Signatures received before the NewBatch event of their batch, by merkle root and operator.
+	// Processed once the task is created
+	pendingSignatures map[[32]byte]map[eigentypes.OperatorId]*pendingSignature
+
+	// Mutex to protect batchesRootByIdx, batchesIdxByRoot, taskSignatures,
+	// pendingSignatures and nextBatchIndex. It is only held to access them
 	taskMutex *sync.Mutex
 
-	// Mutex to protect ethereum wallet
-	walletMutex *sync.Mutex
+	// Wallets that send the aggregated responses, each one replaces its
+	// transactions until they are confirmed
+	walletPool *walletPool
+
+	// Quorums new tasks are signed in, the ones the service manager checks
+	quorums *quorumTracker
+
+	// RPC calls and aggregated responses in process, finished before shutting down.
+	// workMutex protects shuttingDown, so no work is added once the shutdown started
+	pendingWork  *sync.WaitGroup
+	workMutex    *sync.Mutex
+	shuttingDown bool
+	shutdownChan chan struct{}
+
+	// Address of the registered operators, by operator id
+	registeredOperators *lru.Cache[eigentypes.OperatorId, registeredOperator]
+	// Deployment the operator request signatures must be made for
+	requestDomain types.RequestDomain
 
 	logger logging.Logger
 
@@ -73,7 +105,7 @@ type Aggregator struct {
 func NewAggregator(aggregatorConfig config.AggregatorConfig) (*Aggregator, error) {
 	newBatchChan := make(chan *servicemanager.ContractAlignedLayerServiceManagerNewBatch)
 
-	avsReader, err := chainio.NewAvsReaderFromConfig(aggregatorConfig.BaseConfig, aggregatorConfig.EcdsaConfig)
+	avsReader, err := chainio.NewAvsReaderFromConfig(aggregatorConfig.BaseConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -91,25 +123,14 @@ func NewAggregator(aggregatorConfig config.AggregatorConfig) (*Aggregator, error
 	batchesRootByIdx := make(map[uint32][32]byte)
 	batchesIdxByRoot := make(map[[32]byte]uint32)
 
-	chainioConfig := sdkclients.BuildAllConfig{
-		EthHttpUrl:                 aggregatorConfig.BaseConfig.EthRpcUrl,
-		EthWsUrl:                   aggregatorConfig.BaseConfig.EthWsUrl,
-		RegistryCoordinatorAddr:    aggregatorConfig.BaseConfig.AlignedLayerDeploymentConfig.AlignedLayerRegistryCoordinatorAddr.Hex(),
-		OperatorStateRetrieverAddr: aggregatorConfig.BaseConfig.AlignedLayerDeploymentConfig.AlignedLayerOperatorStateRetrieverAddr.Hex(),
-		AvsName:                    "AlignedLayer",
-		PromMetricsIpPortAddress:   ":9090",
-	}
-
-	aggregatorPrivateKey := aggregatorConfig.EcdsaConfig.PrivateKey
-
 	logger := aggregatorConfig.BaseConfig.Logger
-	clients, err := clients.BuildAll(chainioConfig, aggregatorPrivateKey, logger)
+	avsRegistrySubscriber, err := chainio.NewAvsRegistrySubscriberFromConfig(aggregatorConfig.BaseConfig)
 	if err != nil {
-		logger.Errorf("Cannot create sdk clients", "err", err)
+		logger.Errorf("Cannot create avs registry subscriber", "err", err)
 		return nil, err
 	}
 
-	operatorPubkeysService := oppubkeysserv.NewOperatorPubkeysServiceInMemory(context.Background(), clients.AvsRegistryChainSubscriber, clients.AvsRegistryChainReader, logger)
+	operatorPubkeysService := oppubkeysserv.NewOperatorPubkeysServiceInMemory(context.Background(), avsRegistrySubscriber, avsReader.AvsRegistryReader, logger)
 	avsRegistryService := avsregistry.NewAvsRegistryServiceChainCaller(avsReader.AvsRegistryReader, operatorPubkeysService, logger)
 	blsAggregationService := blsagg.NewBlsAggregatorService(avsRegistryService, logger)
 
@@ -117,7 +138,23 @@ func NewAggregator(aggregatorConfig config.AggregatorConfig) (*Aggregator, error
 	reg := prometheus.NewRegistry()
 	aggregatorMetrics := metrics.NewMetrics(aggregatorConfig.Aggregator.MetricsIpPortAddress, reg, logger)
 
-	nextBatchIndex := uint32(0)
+	taskStore, err := store.NewLevelDbTaskStore(aggregatorConfig.Aggregator.TaskStorePath)
+	if err != nil {
+		logger.Error("Cannot open task store", "path", aggregatorConfig.Aggregator.TaskStorePath, "err", err)
+		return nil, err
+	}
+
+	txManagerConfig, err := newTxManagerConfig(&aggregatorConfig)
+	if err != nil {
+		logger.Error("Invalid transaction manager config", "err", err)
+		return nil, err
+	}
+
+	nextBatchIndex, err := taskStore.GetNextBatchIndex()
+	if err != nil {
+		logger.Error("Cannot read next batch index from task store", "err", err)
+		return nil, err
+	}
 
 	aggregator := Aggregator{
 		AggregatorConfig: &aggregatorConfig,
@@ -129,8 +166,23 @@ func NewAggregator(aggregatorConfig config.AggregatorConfig) (*Aggregator, error
 		batchesRootByIdx: batchesRootByIdx,
 		batchesIdxByRoot: batchesIdxByRoot,
 		nextBatchIndex:   nextBatchIndex,
+		taskStore:        taskStore,
 		taskMutex:        &sync.Mutex{},
-		walletMutex:      &sync.Mutex{},
+		walletPool:       newResponseWalletPool(&aggregatorConfig, txManagerConfig, aggregatorMetrics),
+		quorums:          newQuorumTracker(avsReader, aggregatorConfig.Aggregator.QuorumThresholdPercentage, logger, aggregatorMetrics),
+		pendingWork:      &sync.WaitGroup{},
+		workMutex:        &sync.Mutex{},
+		shutdownChan:     make(chan struct{}),
+
+		taskSignatures:      make(map[[32]byte]*taskSignatures),
+		blsSignatureTimeout: defaultBlsSignatureTimeout,
+		pendingSignatures:   make(map[[32]byte]map[eigentypes.OperatorId]*pendingSignature),
+
+		registeredOperators: newRegisteredOperatorsCache(),
+		requestDomain: types.RequestDomain{
+			ChainId:               aggregatorConfig.BaseConfig.ChainId,
+			ServiceManagerAddress: aggregatorConfig.BaseConfig.AlignedLayerDeploymentConfig.AlignedLayerServiceManagerAddr,
+		},
 
 		blsAggregationService: blsAggregationService,
 		logger:                logger,
@@ -141,16 +193,48 @@ func NewAggregator(aggregatorConfig config.AggregatorConfig) (*Aggregator, error
 	return &aggregator, nil
 }
 
+// Start runs the aggregator until ctx is done, then finishes the work in process
+// and returns. ErrShutdownTimeout is returned if it doesn't finish within the
+// configured shutdown timeout.
 func (agg *Aggregator) Start(ctx context.Context) error {
 	agg.logger.Infof("Starting aggregator...")
 
+	// Tasks can't be initialized without their quorums
+	if err := agg.quorums.refresh(ctx); err != nil {
+		agg.logger.Error("Could not read quorums", "err", err)
+		return err
+	}
+	go agg.quorums.monitor(ctx, agg.AggregatorConfig.Aggregator.QuorumRefreshInterval)
+
+	// Underfunded wallets are removed from rotation before responding to any task
+	agg.walletPool.checkBalances(ctx)
+	go agg.walletPool.monitorBalances(ctx, agg.AggregatorConfig.Aggregator.WalletBalanceCheckInterval)
+
+	serverDone := make(chan struct{})
 	go func() {
-		err := agg.ServeOperators()
+		defer close(serverDone)
+		err := agg.ServeOperators(ctx)
 		if err != nil {
 			agg.logger.Fatal("Error listening for tasks", "err", err)
 		}
 	}()
 
+	err := agg.restoreUnfinishedTasks()
+	if err != nil {
+		agg.logger.Error("Could not restore unfinished tasks", "err", err)
+		return err
+	}
+
+	// Listen for new task created in the ServiceManager contract in a separate goroutine
+	subscriberDone := make(chan struct{})
+	go func() {
+		defer close(subscriberDone)
+		listenErr := agg.SubscribeToNewTasks(ctx)
+		if listenErr != nil {
+			agg.logger.Fatal("Error subscribing for new tasks", "err", listenErr)
+		}
+	}()
+
 	var metricsErrChan <-chan error
 	if agg.AggregatorConfig.Aggregator.EnableMetrics {
 		metricsErrChan = agg.metrics.Start(ctx, agg.metricsReg)
@@ -161,23 +245,96 @@ func (agg *Aggregator) Start(ctx context.Context) error {
 	for {
 		select {
 		case <-ctx.Done():
-			return nil
+			agg.logger.Info("Aggregator shutting down...")
+			return agg.shutdown(serverDone, subscriberDone)
 		case err := <-metricsErrChan:
 			agg.logger.Fatal("Metrics server failed", "err", err)
 		case blsAggServiceResp := <-agg.blsAggregationService.GetResponseChannel():
 			agg.logger.Info("Received response from BLS aggregation service",
 				"taskIndex", blsAggServiceResp.TaskIndex)
 
-			go agg.handleBlsAggServiceResponse(blsAggServiceResp)
+			if !agg.beginWork() {
+				agg.logger.Warn("Aggregator is shutting down, response will not be sent", "taskIndex", blsAggServiceResp.TaskIndex)
+				continue
+			}
+			go func() {
+				defer agg.endWork()
+				agg.handleBlsAggServiceResponse(blsAggServiceResp)
+			}()
+		}
+	}
+}
+
+// shutdown stops accepting new work, waits for the RPC calls and aggregated
+// responses in process and closes the task store
+func (agg *Aggregator) shutdown(serverDone <-chan struct{}, subscriberDone <-chan struct{}) error {
+	deadline := time.Now().Add(agg.AggregatorConfig.Aggregator.ShutdownTimeout)
+
+	agg.workMutex.Lock()
+	agg.shuttingDown = true
+	close(agg.shutdownChan)
+	agg.workMutex.Unlock()
+
+	var err error
+	for _, done := range []<-chan struct{}{serverDone, subscriberDone} {
+		select {
+		case <-done:
+		case <-time.After(time.Until(deadline)):
+			err = utils.ErrShutdownTimeout
 		}
 	}
+	if waitErr := utils.WaitWithTimeout(agg.pendingWork, time.Until(deadline)); waitErr != nil {
+		err = waitErr
+	}
+	if errors.Is(err, utils.ErrShutdownTimeout) {
+		agg.logger.Warn("Work in process did not finish before the shutdown timeout, it will be resumed on restart")
+	}
+
+	// Unfinished tasks and received signatures are restored from the store on restart
+	if closeErr := agg.taskStore.Close(); closeErr != nil {
+		agg.logger.Warn("Could not close task store", "err", closeErr)
+	}
+
+	agg.logger.Info("Aggregator stopped")
+	return err
+}
+
+// beginWork registers work that must finish before shutting down.
+// It returns false if the aggregator is already shutting down.
+func (agg *Aggregator) beginWork() bool {
+	agg.workMutex.Lock()
+	defer agg.workMutex.Unlock()
+
+	if agg.shuttingDown {
+		return false
+	}
+	agg.pendingWork.Add(1)
+	return true
+}
+
+func (agg *Aggregator) endWork() {
+	agg.pendingWork.Done()
 }
 
 const MaxSentTxRetries = 5
 
+// Time operators have to sign a task before the BLS aggregation service expires it
+const taskResponseWindow = 100 * time.Second
+
 func (agg *Aggregator) handleBlsAggServiceResponse(blsAggServiceResp blsagg.BlsAggregationServiceResponse) {
 	if blsAggServiceResp.Err != nil {
-		agg.logger.Warn("BlsAggregationServiceResponse contains an error", "err", blsAggServiceResp.Err)
+		agg.taskMutex.Lock()
+		batchMerkleRoot, ok := agg.batchesRootByIdx[blsAggServiceResp.TaskIndex]
+		agg.taskMutex.Unlock()
+
+		agg.logger.Warn("BlsAggregationServiceResponse contains an error",
+			"taskIndex", blsAggServiceResp.TaskIndex,
+			"merkleRoot", hex.EncodeToString(batchMerkleRoot[:]),
+			"err", blsAggServiceResp.Err)
+		// The task is not collecting signatures anymore, so it is not restored
+		if ok {
+			agg.markExpired(batchMerkleRoot)
+		}
 		return
 	}
 
@@ -212,56 +369,163 @@ func (agg *Aggregator) handleBlsAggServiceResponse(blsAggServiceResp blsagg.BlsA
 		"merkleRoot", hex.EncodeToString(batchMerkleRoot[:]))
 
 	var err error
+	wallet := agg.walletPool.acquire()
+	// Retries keep replacing the response at the nonce it was sent with, so the
+	// responses sent after it by the wallet don't get stuck behind it
+	tx := agg.avsWriter.NewAggregatedResponseTx(batchMerkleRoot, nonSignerStakesAndSignature)
 
 	for i := 0; i < MaxSentTxRetries; i++ {
-		_, err = agg.sendAggregatedResponse(batchMerkleRoot, nonSignerStakesAndSignature)
+		_, err = agg.sendAggregatedResponse(wallet, tx, batchMerkleRoot)
 		if err == nil {
+			agg.walletPool.release(wallet, nil)
 			agg.logger.Info("Aggregator successfully responded to task",
 				"taskIndex", blsAggServiceResp.TaskIndex,
 				"merkleRoot", hex.EncodeToString(batchMerkleRoot[:]))
 
 			return
 		}
+		if errors.Is(err, chainio.ErrBatchAlreadyResponded) {
+			agg.walletPool.release(wallet, nil)
+			agg.logger.Info("Task was already responded by another transaction",
+				"taskIndex", blsAggServiceResp.TaskIndex,
+				"merkleRoot", hex.EncodeToString(batchMerkleRoot[:]))
+			agg.markResponded(batchMerkleRoot)
+			return
+		}
+
+		agg.logger.Warn("Could not respond to task, retrying",
+			"err", err,
+			"taskIndex", blsAggServiceResp.TaskIndex,
+			"merkleRoot", hex.EncodeToString(batchMerkleRoot[:]))
+
+		// A response that holds no nonce can be sent by another wallet,
+		// so a wallet without funds is not used again
+		if !tx.Pending() {
+			agg.walletPool.release(wallet, err)
+			wallet = agg.walletPool.acquire()
+		}
 
 		// Sleep for a bit before retrying
 		time.Sleep(2 * time.Second)
 	}
 
+	agg.abandonAggregatedResponse(wallet, tx)
+	agg.walletPool.release(wallet, err)
 	agg.logger.Error("Aggregator failed to respond to task, this batch will be lost",
 		"err", err,
 		"taskIndex", blsAggServiceResp.TaskIndex,
 		"merkleRoot", hex.EncodeToString(batchMerkleRoot[:]))
 }
 
+// Sends response to contract and waits for it to be confirmed.
+// The transaction manager replaces it with higher fees if it is not included in time
+func (agg *Aggregator) sendAggregatedResponse(wallet *responseWallet, tx *chainio.Tx, batchMerkleRoot [32]byte) (*gethtypes.Receipt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), agg.AggregatorConfig.Aggregator.TxSendTimeout)
+	defer cancel()
 
-
-/// Sends response to contract and waits for transaction receipt
-/// Returns error if it fails to send tx or receipt is not found
-func (agg *Aggregator) sendAggregatedResponse(batchMerkleRoot [32]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) (*gethtypes.Receipt, error) {
-	agg.walletMutex.Lock()
-	agg.logger.Infof("- Locked Wallet Resources: Sending aggregated response for batch %s", hex.EncodeToString(batchMerkleRoot[:]))
-
-	txHash, err := agg.avsWriter.SendAggregatedResponse(batchMerkleRoot, nonSignerStakesAndSignature)
+	agg.logger.Infof("Sending aggregated response for batch %s with wallet %s", hex.EncodeToString(batchMerkleRoot[:]), wallet.address.Hex())
+	receipt, err := agg.avsWriter.SendAggregatedResponse(ctx, wallet.txManager, tx, batchMerkleRoot)
 	if err != nil {
-		agg.walletMutex.Unlock()
-		agg.logger.Infof("- Unlocked Wallet Resources: Error sending aggregated response for batch %s. Error: %s", hex.EncodeToString(batchMerkleRoot[:]), err)
 		return nil, err
 	}
 
-	agg.walletMutex.Unlock()
-	agg.logger.Infof("- Unlocked Wallet Resources: Sending aggregated response for batch %s", hex.EncodeToString(batchMerkleRoot[:]))
-
-	receipt, err := utils.WaitForTransactionReceipt(
-		agg.AggregatorConfig.BaseConfig.EthRpcClient, context.Background(), *txHash)
+	err = agg.taskStore.SetResponseTxHash(batchMerkleRoot, receipt.TxHash)
 	if err != nil {
-		return nil, err
+		agg.logger.Warn("Could not store response tx hash", "merkleRoot", hex.EncodeToString(batchMerkleRoot[:]), "err", err)
 	}
+	agg.markResponded(batchMerkleRoot)
 
 	agg.metrics.IncAggregatedResponses()
 
 	return receipt, nil
 }
 
+// abandonAggregatedResponse cancels a response that is still pending, so the nonce it holds
+// doesn't block the responses sent after it by the wallet
+func (agg *Aggregator) abandonAggregatedResponse(wallet *responseWallet, tx *chainio.Tx) {
+	if !tx.Pending() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), agg.AggregatorConfig.Aggregator.TxSendTimeout)
+	defer cancel()
+
+	if err := wallet.txManager.Abandon(ctx, tx); err != nil {
+		agg.logger.Error("Could not cancel the pending response, the following responses of the wallet may be stuck",
+			"address", wallet.address.Hex(), "err", err)
+	}
+}
+
+// markResponded and markExpired finish the task in the task store, and forget it since it
+// doesn't collect signatures anymore
+func (agg *Aggregator) markResponded(batchMerkleRoot [32]byte) {
+	agg.taskMutex.Lock()
+	agg.forgetTask(batchMerkleRoot)
+	agg.taskMutex.Unlock()
+
+	err := agg.taskStore.MarkResponded(batchMerkleRoot)
+	if err != nil {
+		agg.logger.Warn("Could not mark task as responded in task store", "merkleRoot", hex.EncodeToString(batchMerkleRoot[:]), "err", err)
+	}
+}
+
+func (agg *Aggregator) markExpired(batchMerkleRoot [32]byte) {
+	agg.taskMutex.Lock()
+	agg.forgetTask(batchMerkleRoot)
+	agg.taskMutex.Unlock()
+
+	err := agg.taskStore.MarkExpired(batchMerkleRoot)
+	if err != nil {
+		agg.logger.Warn("Could not mark task as expired in task store", "merkleRoot", hex.EncodeToString(batchMerkleRoot[:]), "err", err)
+	}
+}
+
+// forgetTask removes the task from the in-memory maps, isKnownBatch still finds it in the task store.
+// Must be called with taskMutex held.
+func (agg *Aggregator) forgetTask(batchMerkleRoot [32]byte) {
+	batchIndex, ok := agg.batchesIdxByRoot[batchMerkleRoot]
+	if !ok {
+		return
+	}
+	delete(agg.batchesIdxByRoot, batchMerkleRoot)
+	delete(agg.batchesRootByIdx, batchIndex)
+	delete(agg.taskSignatures, batchMerkleRoot)
+}
+
+// newResponseWalletPool uses the aggregator ECDSA key and the configured response wallets
+func newResponseWalletPool(aggregatorConfig *config.AggregatorConfig, txManagerConfig chainio.TxManagerConfig, aggregatorMetrics *metrics.Metrics) *walletPool {
+	signers := []signer.Signer{aggregatorConfig.EcdsaConfig.Signer}
+	for _, wallet := range aggregatorConfig.ResponseWallets {
+		signers = append(signers, wallet.Signer)
+	}
+	minBalance := new(big.Int).Mul(new(big.Int).SetUint64(aggregatorConfig.Aggregator.WalletMinBalanceGwei), big.NewInt(params.GWei))
+
+	return newWalletPool(aggregatorConfig.BaseConfig.EthRpcClient, signers, txManagerConfig,
+		aggregatorConfig.Aggregator.WalletAssignment, minBalance, aggregatorConfig.BaseConfig.Logger, aggregatorMetrics)
+}
+
+// newTxManagerConfig uses the transaction manager defaults for the settings that are not configured
+func newTxManagerConfig(aggregatorConfig *config.AggregatorConfig) (chainio.TxManagerConfig, error) {
+	txManagerConfig := chainio.DefaultTxManagerConfig()
+
+	if aggregatorConfig.Aggregator.TxConfirmationBlocks != 0 {
+		txManagerConfig.ConfirmationBlocks = aggregatorConfig.Aggregator.TxConfirmationBlocks
+	}
+	if aggregatorConfig.Aggregator.TxReplacementTimeout != 0 {
+		txManagerConfig.ReplacementTimeout = aggregatorConfig.Aggregator.TxReplacementTimeout
+	}
+	if aggregatorConfig.Aggregator.TxFeeBumpPercentage != 0 {
+		if aggregatorConfig.Aggregator.TxFeeBumpPercentage < chainio.MinTxFeeBumpPercentage {
+			return chainio.TxManagerConfig{}, fmt.Errorf("tx_fee_bump_percentage must be at least %d", chainio.MinTxFeeBumpPercentage)
+		}
+		txManagerConfig.FeeBumpPercentage = aggregatorConfig.Aggregator.TxFeeBumpPercentage
+	}
+	if aggregatorConfig.Aggregator.TxMaxFeePerGasGwei != 0 {
+		txManagerConfig.MaxFeePerGas = new(big.Int).Mul(
+			new(big.Int).SetUint64(aggregatorConfig.Aggregator.TxMaxFeePerGasGwei), big.NewInt(params.GWei))
+	}
+
+	return txManagerConfig, nil
+}
 
 func (agg *Aggregator) AddNewTask(batchMerkleRoot [32]byte, taskCreatedBlock uint32) {
 	agg.AggregatorConfig.BaseConfig.Logger.Info("Adding new task",
@@ -289,18 +553,138 @@ func (agg *Aggregator) AddNewTask(batchMerkleRoot [32]byte, taskCreatedBlock uin
 
 	agg.batchesIdxByRoot[batchMerkleRoot] = batchIndex
 	agg.batchesRootByIdx[batchIndex] = batchMerkleRoot
+	signatures := newTaskSignatures()
+	agg.taskSignatures[batchMerkleRoot] = signatures
 	agg.nextBatchIndex += 1
+	nextBatchIndex := agg.nextBatchIndex
+	quorums := agg.quorums.current()
+	pendingSignatures := agg.takePendingSignatures(batchMerkleRoot)
+	// Signatures received from now on wait until the BLS task is initialized
+	signatures.mutex.Lock()
 
-	quorumNums := eigentypes.QuorumNums{eigentypes.QuorumNum(QUORUM_NUMBER)}
-	quorumThresholdPercentages := eigentypes.QuorumThresholdPercentages{eigentypes.QuorumThresholdPercentage(QUORUM_THRESHOLD)}
+	agg.taskMutex.Unlock()
+	agg.AggregatorConfig.BaseConfig.Logger.Info("- Unlocked Resources: Adding new task")
 
-	err := agg.blsAggregationService.InitializeNewTask(batchIndex, taskCreatedBlock, quorumNums, quorumThresholdPercentages, 100*time.Second)
+	// --- PERSIST TASK ---
+	// Tasks are added one at a time by the subscriber, so the next batch index is stored in order
+	err := agg.taskStore.SaveTask(&store.Task{
+		BatchMerkleRoot:            batchMerkleRoot,
+		TaskCreatedBlock:           taskCreatedBlock,
+		BatchIndex:                 batchIndex,
+		QuorumNumbers:              quorums.numbers,
+		QuorumThresholdPercentages: quorums.thresholdPercentages,
+		InitializedAt:              time.Now(),
+	})
+	if err == nil {
+		err = agg.taskStore.SetNextBatchIndex(nextBatchIndex)
+	}
+	if err != nil {
+		agg.logger.Warn("Could not persist task, it will be lost on restart", "batchIndex", batchIndex, "err", err)
+	}
+
+	err = agg.initializeBlsTask(batchIndex, taskCreatedBlock, quorums, taskResponseWindow)
 	// FIXME(marian): When this errors, should we retry initializing new task? Logging fatal for now.
 	if err != nil {
 		agg.logger.Fatalf("BLS aggregation service error when initializing new task: %s", err)
 	}
+	signatures.mutex.Unlock()
 
-	agg.taskMutex.Unlock()
-	agg.AggregatorConfig.BaseConfig.Logger.Info("- Unlocked Resources: Adding new task")
-	agg.logger.Info("New task added", "batchIndex", batchIndex, "batchMerkleRoot", hex.EncodeToString(batchMerkleRoot[:]))
+	agg.logger.Info("New task added", "batchIndex", batchIndex, "batchMerkleRoot", hex.EncodeToString(batchMerkleRoot[:]),
+		"quorumNumbers", quorums.numbers)
+
+	agg.processPendingSignatures(batchIndex, signatures, pendingSignatures)
+}
+
+func (agg *Aggregator) initializeBlsTask(batchIndex uint32, taskCreatedBlock uint32, quorums taskQuorums, timeToExpiry time.Duration) error {
+	return agg.blsAggregationService.InitializeNewTask(batchIndex, taskCreatedBlock, quorums.numbers, quorums.thresholdPercentages, timeToExpiry)
+}
+
+// restoreUnfinishedTasks re-initializes in the BLS aggregation service the tasks
+// that were not responded before the aggregator stopped, for what is left of their
+// response window, and replays the operator signatures that were already received for them
+func (agg *Aggregator) restoreUnfinishedTasks() error {
+	tasks, err := agg.taskStore.UnfinishedTasks()
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		batchMerkleRoot := task.BatchMerkleRoot
+		timeToExpiry := taskResponseWindow - time.Since(task.InitializedAt)
+
+		// The response may have landed after the aggregator stopped waiting for it,
+		// with any of the transactions sent to replace it
+		responded, err := agg.avsReader.IsBatchResponded(batchMerkleRoot)
+		if err != nil {
+			agg.logger.Warn("Could not check if task was responded", "merkleRoot", hex.EncodeToString(batchMerkleRoot[:]), "err", err)
+		} else if responded {
+			agg.logger.Info("Task was already responded", "merkleRoot", hex.EncodeToString(batchMerkleRoot[:]))
+			agg.markResponded(batchMerkleRoot)
+			continue
+		}
+		if timeToExpiry <= 0 {
+			agg.logger.Info("Task response window is over, not restoring it", "merkleRoot", hex.EncodeToString(batchMerkleRoot[:]))
+			agg.markExpired(batchMerkleRoot)
+			continue
+		}
+
+		agg.taskMutex.Lock()
+		agg.batchesIdxByRoot[batchMerkleRoot] = task.BatchIndex
+		agg.batchesRootByIdx[task.BatchIndex] = batchMerkleRoot
+		signatures := newTaskSignatures()
+		// The stored signatures are replayed, so the operators can't sign again
+		for _, signedTaskResponse := range task.Signatures {
+			signatures.operators[signedTaskResponse.OperatorId] = true
+		}
+		agg.taskSignatures[batchMerkleRoot] = signatures
+		if task.BatchIndex >= agg.nextBatchIndex {
+			agg.nextBatchIndex = task.BatchIndex + 1
+		}
+		// The RPC server is already running, operators may have sent signatures for it.
+		// They are not added until the BLS task is initialized
+		pendingSignatures := agg.takePendingSignatures(batchMerkleRoot)
+		signatures.mutex.Lock()
+		agg.taskMutex.Unlock()
+
+		quorums := taskQuorums{numbers: task.QuorumNumbers, thresholdPercentages: task.QuorumThresholdPercentages}
+		if len(quorums.numbers) == 0 {
+			quorums = agg.quorums.current()
+		}
+		err = agg.initializeBlsTask(task.BatchIndex, task.TaskCreatedBlock, quorums, timeToExpiry)
+		signatures.mutex.Unlock()
+		if err != nil {
+			agg.logger.Warn("BLS aggregation service error when restoring task, expiring it", "batchIndex", task.BatchIndex, "err", err)
+			agg.taskMutex.Lock()
+			agg.forgetTask(batchMerkleRoot)
+			agg.returnPendingSignatures(batchMerkleRoot, pendingSignatures)
+			agg.taskMutex.Unlock()
+			agg.markExpired(batchMerkleRoot)
+			continue
+		}
+
+		agg.logger.Info("Restored unfinished task",
+			"batchIndex", task.BatchIndex,
+			"merkleRoot", hex.EncodeToString(batchMerkleRoot[:]),
+			"signatures", len(task.Signatures))
+
+		for _, signedTaskResponse := range task.Signatures {
+			go agg.replaySignature(task.BatchIndex, signedTaskResponse)
+		}
+		agg.processPendingSignatures(task.BatchIndex, signatures, pendingSignatures)
+	}
+
+	return nil
+}
+
+func (agg *Aggregator) replaySignature(batchIndex uint32, signedTaskResponse types.SignedTaskResponse) {
+	err := agg.blsAggregationService.ProcessNewSignature(
+		context.Background(), batchIndex, signedTaskResponse.BatchMerkleRoot,
+		&signedTaskResponse.BlsSignature, signedTaskResponse.OperatorId,
+	)
+	if err != nil {
+		agg.logger.Warn("Could not replay stored signature",
+			"batchIndex", batchIndex,
+			"operatorId", hex.EncodeToString(signedTaskResponse.OperatorId[:]),
+			"err", err)
+	}
 }
@@ -0,0 +1,364 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	sdkavsregistry "github.com/Layr-Labs/eigensdk-go/chainio/clients/avsregistry"
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	blsagg "github.com/Layr-Labs/eigensdk-go/services/bls_aggregation"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yetanotherco/aligned_layer/aggregator/internal/store"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/types"
+	"github.com/yetanotherco/aligned_layer/metrics"
+)
+
+// fakeBlsAggregationService records the tasks initialized and the signatures processed
+type fakeBlsAggregationService struct {
+	mutex        sync.Mutex
+	timeToExpiry map[uint32]time.Duration
+	signatures   map[uint32][]eigentypes.OperatorId
+	processErr   error
+	initErr      error
+	// Time signatures take to be processed
+	processDelay time.Duration
+	responses    chan blsagg.BlsAggregationServiceResponse
+}
+
+func newFakeBlsAggregationService() *fakeBlsAggregationService {
+	return &fakeBlsAggregationService{
+		timeToExpiry: make(map[uint32]time.Duration),
+		signatures:   make(map[uint32][]eigentypes.OperatorId),
+		responses:    make(chan blsagg.BlsAggregationServiceResponse),
+	}
+}
+
+func (s *fakeBlsAggregationService) InitializeNewTask(taskIndex uint32, taskCreatedBlock uint32, quorumNumbers eigentypes.QuorumNums,
+	quorumThresholdPercentages eigentypes.QuorumThresholdPercentages, timeToExpiry time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.initErr != nil {
+		return s.initErr
+	}
+	s.timeToExpiry[taskIndex] = timeToExpiry
+	return nil
+}
+
+func (s *fakeBlsAggregationService) ProcessNewSignature(ctx context.Context, taskIndex uint32, taskResponseDigest eigentypes.TaskResponseDigest,
+	blsSignature *bls.Signature, operatorId eigentypes.OperatorId) error {
+	s.mutex.Lock()
+	processDelay := s.processDelay
+	s.mutex.Unlock()
+	time.Sleep(processDelay)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.processErr != nil {
+		return s.processErr
+	}
+	s.signatures[taskIndex] = append(s.signatures[taskIndex], operatorId)
+	return nil
+}
+
+func (s *fakeBlsAggregationService) GetResponseChannel() <-chan blsagg.BlsAggregationServiceResponse {
+	return s.responses
+}
+
+func (s *fakeBlsAggregationService) setProcessErr(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.processErr = err
+}
+
+func (s *fakeBlsAggregationService) initialized(taskIndex uint32) (time.Duration, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	timeToExpiry, ok := s.timeToExpiry[taskIndex]
+	return timeToExpiry, ok
+}
+
+func (s *fakeBlsAggregationService) signatureCount(taskIndex uint32) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.signatures[taskIndex])
+}
+
+// fakeServiceManagerClient answers the batchesState calls of the service manager,
+// and has no events in the queried blocks
+type fakeServiceManagerClient struct {
+	eth.Client
+	responded     map[[32]byte]bool
+	blockNumber   uint64
+	filterQueries []ethereum.FilterQuery
+}
+
+func (c *fakeServiceManagerClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.blockNumber, ctx.Err()
+}
+
+func (c *fakeServiceManagerClient) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]gethtypes.Log, error) {
+	c.filterQueries = append(c.filterQueries, query)
+	return nil, ctx.Err()
+}
+
+func (c *fakeServiceManagerClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	serviceManagerAbi, err := servicemanager.ContractAlignedLayerServiceManagerMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	method, err := serviceManagerAbi.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	if method.Name != "batchesState" {
+		return nil, errors.New("unexpected call to " + method.Name)
+	}
+	var batchMerkleRoot [32]byte
+	copy(batchMerkleRoot[:], call.Data[4:])
+	return method.Outputs.Pack(uint32(10), c.responded[batchMerkleRoot])
+}
+
+// fakeAvsRegistryReader has the addresses of the registered operators
+type fakeAvsRegistryReader struct {
+	sdkavsregistry.AvsRegistryReader
+	mutex     sync.Mutex
+	operators map[eigentypes.OperatorId]gethcommon.Address
+	lookups   int
+}
+
+func (r *fakeAvsRegistryReader) GetOperatorFromId(opts *bind.CallOpts, operatorId eigentypes.OperatorId) (gethcommon.Address, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.lookups++
+	return r.operators[operatorId], nil
+}
+
+func (r *fakeAvsRegistryReader) IsOperatorRegistered(opts *bind.CallOpts, operatorAddress gethcommon.Address) (bool, error) {
+	return operatorAddress != (gethcommon.Address{}), nil
+}
+
+func (r *fakeAvsRegistryReader) lookupCount() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.lookups
+}
+
+func newTestAvsReader(t *testing.T, client eth.Client, registryReader sdkavsregistry.AvsRegistryReader) *chainio.AvsReader {
+	bindings, err := chainio.NewAvsServiceBindings(gethcommon.Address{1}, gethcommon.Address{2}, client, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &chainio.AvsReader{AvsRegistryReader: registryReader, AvsContractBindings: bindings}
+}
+
+// newTestAggregator creates an aggregator with an in-memory task store that signs tasks in quorum 0
+func newTestAggregator(t *testing.T, avsReader *chainio.AvsReader, blsAggregationService blsagg.BlsAggregationService) *Aggregator {
+	taskStore := store.NewInMemoryTaskStore()
+	t.Cleanup(func() { _ = taskStore.Close() })
+
+	aggregatorMetrics := metrics.NewMetrics("", prometheus.NewRegistry(), logging.NewNoopLogger())
+	quorums := newQuorumTracker(&fakeQuorumReader{quorumNumbers: eigentypes.QuorumNums{0}, thresholdPercentage: 67}, 67,
+		logging.NewNoopLogger(), aggregatorMetrics)
+	if err := quorums.refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return &Aggregator{
+		AggregatorConfig:      &config.AggregatorConfig{BaseConfig: &config.BaseConfig{Logger: logging.NewNoopLogger()}},
+		avsReader:             avsReader,
+		blsAggregationService: blsAggregationService,
+		batchesRootByIdx:      make(map[uint32][32]byte),
+		batchesIdxByRoot:      make(map[[32]byte]uint32),
+		taskStore:             taskStore,
+		taskSignatures:        make(map[[32]byte]*taskSignatures),
+		blsSignatureTimeout:   defaultBlsSignatureTimeout,
+		pendingSignatures:     make(map[[32]byte]map[eigentypes.OperatorId]*pendingSignature),
+		taskMutex:             &sync.Mutex{},
+		quorums:               quorums,
+		pendingWork:           &sync.WaitGroup{},
+		workMutex:             &sync.Mutex{},
+		shutdownChan:          make(chan struct{}),
+		registeredOperators:   newRegisteredOperatorsCache(),
+		logger:                logging.NewNoopLogger(),
+		metrics:               aggregatorMetrics,
+	}
+}
+
+func TestRestoreUnfinishedTasks(t *testing.T) {
+	inWindowRoot := [32]byte{1}
+	expiredRoot := [32]byte{2}
+	respondedRoot := [32]byte{3}
+
+	blsAggregationService := newFakeBlsAggregationService()
+	client := &fakeServiceManagerClient{responded: map[[32]byte]bool{respondedRoot: true}}
+	agg := newTestAggregator(t, newTestAvsReader(t, client, nil), blsAggregationService)
+
+	initializedAt := map[[32]byte]time.Time{
+		inWindowRoot:  time.Now().Add(-30 * time.Second),
+		expiredRoot:   time.Now().Add(-2 * taskResponseWindow),
+		respondedRoot: time.Now(),
+	}
+	for i, root := range [][32]byte{inWindowRoot, expiredRoot, respondedRoot} {
+		err := agg.taskStore.SaveTask(&store.Task{
+			BatchMerkleRoot:            root,
+			TaskCreatedBlock:           10,
+			BatchIndex:                 uint32(i),
+			QuorumNumbers:              eigentypes.QuorumNums{0},
+			QuorumThresholdPercentages: eigentypes.QuorumThresholdPercentages{67},
+			InitializedAt:              initializedAt[root],
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := agg.taskStore.AddSignature(inWindowRoot, types.SignedTaskResponse{BatchMerkleRoot: inWindowRoot, OperatorId: eigentypes.OperatorId{1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := agg.restoreUnfinishedTasks(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the task in its window is restored, for what is left of it
+	timeToExpiry, ok := blsAggregationService.initialized(0)
+	if !ok || timeToExpiry > taskResponseWindow-30*time.Second || timeToExpiry < taskResponseWindow-time.Minute {
+		t.Fatalf("expected the task to be restored with the rest of its window, got %v", timeToExpiry)
+	}
+	for _, taskIndex := range []uint32{1, 2} {
+		if _, ok := blsAggregationService.initialized(taskIndex); ok {
+			t.Errorf("task %d should not be restored", taskIndex)
+		}
+	}
+	if agg.batchesIdxByRoot[inWindowRoot] != 0 || agg.nextBatchIndex != 1 {
+		t.Errorf("unexpected batch index %d and next batch index %d", agg.batchesIdxByRoot[inWindowRoot], agg.nextBatchIndex)
+	}
+
+	// Its stored signature is replayed
+	deadline := time.Now().Add(time.Second)
+	for blsAggregationService.signatureCount(0) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if blsAggregationService.signatureCount(0) != 1 {
+		t.Fatal("expected the stored signature to be replayed")
+	}
+
+	// The others are finished and not restored again
+	tasks, err := agg.taskStore.UnfinishedTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].BatchMerkleRoot != inWindowRoot {
+		t.Fatalf("expected only the restored task to be unfinished, got %d tasks", len(tasks))
+	}
+	expiredTask, err := agg.taskStore.GetTask(expiredRoot)
+	if err != nil || !expiredTask.Expired {
+		t.Error("expected the task out of its window to be expired")
+	}
+	respondedTask, err := agg.taskStore.GetTask(respondedRoot)
+	if err != nil || !respondedTask.Responded {
+		t.Error("expected the responded task to be marked as responded")
+	}
+}
+
+func TestRestoreUnfinishedTasksExpiresTaskNotInitialized(t *testing.T) {
+	batchMerkleRoot := [32]byte{1}
+	blsAggregationService := newFakeBlsAggregationService()
+	blsAggregationService.initErr = errors.New("task already initialized")
+	client := &fakeServiceManagerClient{responded: map[[32]byte]bool{}}
+	agg := newTestAggregator(t, newTestAvsReader(t, client, nil), blsAggregationService)
+
+	err := agg.taskStore.SaveTask(&store.Task{
+		BatchMerkleRoot:            batchMerkleRoot,
+		TaskCreatedBlock:           10,
+		BatchIndex:                 0,
+		QuorumNumbers:              eigentypes.QuorumNums{0},
+		QuorumThresholdPercentages: eigentypes.QuorumThresholdPercentages{67},
+		InitializedAt:              time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err := bufferTestSignature(agg, batchMerkleRoot, eigentypes.OperatorId{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := agg.restoreUnfinishedTasks(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The task is forgotten, and the signature received for it is kept
+	if _, ok := agg.batchesIdxByRoot[batchMerkleRoot]; ok {
+		t.Error("expected the task to be removed from the batch indexes")
+	}
+	if _, ok := agg.batchesRootByIdx[0]; ok {
+		t.Error("expected the task to be removed from the batch merkle roots")
+	}
+	if _, ok := agg.taskSignatures[batchMerkleRoot]; ok {
+		t.Error("expected the signatures of the task to be removed")
+	}
+	if agg.pendingSignatures[batchMerkleRoot][eigentypes.OperatorId{1}] != pending {
+		t.Error("expected the pending signature to be kept")
+	}
+	task, err := agg.taskStore.GetTask(batchMerkleRoot)
+	if err != nil || !task.Expired {
+		t.Error("expected the task that could not be restored to be expired")
+	}
+}
+
+func TestBlsAggregationErrorExpiresTask(t *testing.T) {
+	agg := newTestAggregator(t, nil, newFakeBlsAggregationService())
+	batchMerkleRoot := [32]byte{1}
+	agg.AddNewTask(batchMerkleRoot, 10)
+
+	agg.handleBlsAggServiceResponse(blsagg.BlsAggregationServiceResponse{TaskIndex: 0, Err: blsagg.TaskExpiredError})
+
+	task, err := agg.taskStore.GetTask(batchMerkleRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !task.Expired || task.InitializedAt.IsZero() {
+		t.Fatalf("expected the task to be expired, got %+v", task)
+	}
+	tasks, err := agg.taskStore.UnfinishedTasks()
+	if err != nil || len(tasks) != 0 {
+		t.Fatalf("expected no unfinished tasks, got %d", len(tasks))
+	}
+}
+
+func TestFinishedTaskIsForgotten(t *testing.T) {
+	agg := newTestAggregator(t, nil, newFakeBlsAggregationService())
+	batchMerkleRoot := [32]byte{1}
+	agg.AddNewTask(batchMerkleRoot, 10)
+
+	agg.markResponded(batchMerkleRoot)
+
+	if len(agg.batchesIdxByRoot) != 0 || len(agg.batchesRootByIdx) != 0 || len(agg.taskSignatures) != 0 {
+		t.Fatal("expected the responded task to be removed from the maps")
+	}
+	if !agg.isKnownBatch(batchMerkleRoot) {
+		t.Error("expected the responded task to be found in the task store")
+	}
+	// Late signatures are not kept waiting for the task
+	signedTaskResponse := &types.SignedTaskResponse{BatchMerkleRoot: batchMerkleRoot, OperatorId: eigentypes.OperatorId{1}}
+	if result := agg.processSignedTaskResponse(signedTaskResponse, 0); result.Status != types.TaskResponseTaskExpired {
+		t.Errorf("expected a signature of the responded task to be rejected, got %+v", result)
+	}
+	if len(agg.pendingSignatures) != 0 {
+		t.Error("expected the signature of the responded task not to be kept")
+	}
+}
@@ -0,0 +1,125 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+// Requests carry a single signature or report, anything bigger is rejected
+const maxJsonRpcRequestSize = 64 * 1024
+
+// serveJsonRpc serves the aggregator JSON-RPC 2.0 API. Batch requests are not supported.
+func (agg *Aggregator) serveJsonRpc(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request types.JsonRpcRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJsonRpcRequestSize))
+	if err := decoder.Decode(&request); err != nil {
+		writeJsonRpcResponse(w, nil, nil, types.NewJsonRpcError(types.JsonRpcParseError, err.Error()))
+		return
+	}
+	if request.JsonRpc != types.JsonRpcVersion || request.Method == "" {
+		writeJsonRpcResponse(w, request.Id, nil, types.NewJsonRpcError(types.JsonRpcInvalidRequest, "invalid json-rpc 2.0 request"))
+		return
+	}
+
+	result, rpcErr := agg.handleJsonRpc(request.Method, request.Params)
+
+	// Notifications are processed but not answered
+	if len(request.Id) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJsonRpcResponse(w, request.Id, result, rpcErr)
+}
+
+func (agg *Aggregator) handleJsonRpc(method string, params json.RawMessage) (any, *types.JsonRpcError) {
+	switch method {
+	case types.MethodSubmitSignedTaskResponse:
+		var signedTaskResponseParams types.SignedTaskResponseParams
+		if err := decodeJsonRpcParams(params, &signedTaskResponseParams); err != nil {
+			return nil, err
+		}
+		signedTaskResponse, err := signedTaskResponseParams.SignedTaskResponse()
+		if err != nil {
+			return nil, types.NewJsonRpcError(types.JsonRpcInvalidParams, err.Error())
+		}
+		// The outcome is given by the result status, so the operator knows whether to send it again
+		return agg.submitSignedTaskResponse(signedTaskResponse, signedTaskResponseParams.SigningHash(agg.requestDomain),
+			signedTaskResponseParams.Timestamp, signedTaskResponseParams.Signature, pendingSignatureWait), nil
+
+	case types.MethodReportVerificationFailure:
+		if !agg.beginWork() {
+			return nil, types.NewJsonRpcError(types.AggregatorErrShuttingDown, ErrShuttingDown.Error())
+		}
+		defer agg.endWork()
+
+		var verificationFailureParams types.VerificationFailureParams
+		if err := decodeJsonRpcParams(params, &verificationFailureParams); err != nil {
+			return nil, err
+		}
+		report := verificationFailureParams.VerificationFailureReport()
+		err := agg.authenticateOperator(report.OperatorId, verificationFailureParams.SigningHash(agg.requestDomain),
+			verificationFailureParams.Timestamp, verificationFailureParams.Signature)
+		if err != nil {
+			return nil, authenticationError(err)
+		}
+		agg.processVerificationFailure(report)
+		return types.VerificationFailureResult{}, nil
+
+	default:
+		return nil, types.NewJsonRpcError(types.JsonRpcMethodNotFound, "method not found: "+method)
+	}
+}
+
+func authenticationError(err error) *types.JsonRpcError {
+	switch {
+	case errors.Is(err, ErrOperatorNotRegistered):
+		return types.NewJsonRpcError(types.AggregatorErrOperatorNotRegistered, err.Error())
+	case errors.Is(err, ErrInvalidSignature):
+		return types.NewJsonRpcError(types.AggregatorErrInvalidSignature, err.Error())
+	default:
+		return types.NewJsonRpcError(types.JsonRpcInternalError, err.Error())
+	}
+}
+
+// decodeJsonRpcParams decodes params given by name. Unknown fields are ignored,
+// so clients can send fields added in newer versions of the API.
+func decodeJsonRpcParams(params json.RawMessage, dst any) *types.JsonRpcError {
+	if len(params) == 0 {
+		return types.NewJsonRpcError(types.JsonRpcInvalidParams, "missing params")
+	}
+	if err := json.Unmarshal(params, dst); err != nil {
+		return types.NewJsonRpcError(types.JsonRpcInvalidParams, err.Error())
+	}
+	return nil
+}
+
+func writeJsonRpcResponse(w http.ResponseWriter, id json.RawMessage, result any, rpcErr *types.JsonRpcError) {
+	response := types.JsonRpcResponse{
+		JsonRpc: types.JsonRpcVersion,
+		Error:   rpcErr,
+		Id:      id,
+	}
+	if response.Id == nil {
+		response.Id = json.RawMessage("null")
+	}
+	if rpcErr == nil {
+		encodedResult, err := json.Marshal(result)
+		if err != nil {
+			response.Error = types.NewJsonRpcError(types.JsonRpcInternalError, err.Error())
+		} else {
+			response.Result = encodedResult
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
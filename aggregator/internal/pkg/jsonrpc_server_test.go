@@ -0,0 +1,78 @@
+package pkg
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+func serveTestJsonRpc(t *testing.T, agg *Aggregator, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	agg.serveJsonRpc(recorder, httptest.NewRequest(http.MethodPost, types.AggregatorJsonRpcPath, strings.NewReader(body)))
+	return recorder
+}
+
+func decodeTestJsonRpcResponse(t *testing.T, recorder *httptest.ResponseRecorder) types.JsonRpcResponse {
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("unexpected http status %d", recorder.Code)
+	}
+	var response types.JsonRpcResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+func TestJsonRpcNotificationIsProcessedWithoutResponse(t *testing.T) {
+	operatorId := eigentypes.OperatorId{1}
+	registryReader := &fakeAvsRegistryReader{operators: map[eigentypes.OperatorId]gethcommon.Address{operatorId: {2}}}
+	agg := newTestAggregator(t, newTestAvsReader(t, nil, registryReader), newFakeBlsAggregationService())
+
+	params, err := json.Marshal(types.VerificationFailureParams{OperatorId: gethcommon.Hash(operatorId), Reason: "invalid_proof"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := serveTestJsonRpc(t, agg, `{"jsonrpc":"2.0","method":"`+types.MethodReportVerificationFailure+`","params":`+string(params)+`}`)
+
+	if recorder.Code != http.StatusNoContent || recorder.Body.Len() != 0 {
+		t.Fatalf("expected an empty response, got %d %q", recorder.Code, recorder.Body.String())
+	}
+	if registryReader.lookupCount() != 1 {
+		t.Fatal("expected the notification to be processed")
+	}
+}
+
+func TestJsonRpcErrors(t *testing.T) {
+	agg := newTestAggregator(t, nil, newFakeBlsAggregationService())
+
+	tests := []struct {
+		name string
+		body string
+		code int
+		id   string
+	}{
+		{"parse error", `{"jsonrpc":"2.0",`, types.JsonRpcParseError, "null"},
+		{"invalid request", `{"jsonrpc":"1.0","method":"` + types.MethodSubmitSignedTaskResponse + `","id":1}`, types.JsonRpcInvalidRequest, "1"},
+		{"method not found", `{"jsonrpc":"2.0","method":"aggregator_unknown","params":{},"id":"a"}`, types.JsonRpcMethodNotFound, `"a"`},
+		{"missing params", `{"jsonrpc":"2.0","method":"` + types.MethodSubmitSignedTaskResponse + `","id":2}`, types.JsonRpcInvalidParams, "2"},
+		{"params of the wrong type", `{"jsonrpc":"2.0","method":"` + types.MethodReportVerificationFailure + `","params":{"batch_index":"first"},"id":3}`, types.JsonRpcInvalidParams, "3"},
+		{"invalid bls signature", `{"jsonrpc":"2.0","method":"` + types.MethodSubmitSignedTaskResponse + `","params":{"bls_signature":"0x0102"},"id":4}`, types.JsonRpcInvalidParams, "4"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := decodeTestJsonRpcResponse(t, serveTestJsonRpc(t, agg, test.body))
+			if response.Error == nil || response.Error.Code != test.code {
+				t.Fatalf("expected error code %d, got %+v", test.code, response.Error)
+			}
+			if string(response.Id) != test.id || response.Result != nil {
+				t.Errorf("unexpected id %s and result %s", response.Id, response.Result)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+const (
+	// Registered operators are cached to avoid querying the registry coordinator on every request
+	registeredOperatorsCacheSize = 1024
+	registeredOperatorsCacheTtl  = 10 * time.Minute
+	// Unknown operator ids are cached for a short time, so requests with made up ids don't each
+	// query the registry coordinator, while a new registration is still seen soon
+	unregisteredOperatorsCacheTtl = 30 * time.Second
+	// Signed requests are rejected if their timestamp is further than this from the aggregator clock,
+	// so a captured request can't be replayed later
+	maxRequestTimestampSkew = 5 * time.Minute
+)
+
+var (
+	ErrOperatorNotRegistered = errors.New("operator not registered")
+	ErrInvalidSignature      = errors.New("invalid request signature")
+)
+
+type registeredOperator struct {
+	address ethcommon.Address
+	// False for the operator ids that are not registered
+	registered bool
+	expiresAt  time.Time
+}
+
+func newRegisteredOperatorsCache() *lru.Cache[eigentypes.OperatorId, registeredOperator] {
+	return lru.NewCache[eigentypes.OperatorId, registeredOperator](registeredOperatorsCacheSize)
+}
+
+// authenticateOperator checks the operator id is registered in the AVS and, if a
+// signature is given, that it was made by the registered operator address at a time
+// close to now. Requests without signature are rejected when operator signatures are required.
+func (agg *Aggregator) authenticateOperator(operatorId eigentypes.OperatorId, signingHash ethcommon.Hash, timestamp uint64, signature []byte) error {
+	operatorAddress, err := agg.registeredOperatorAddress(operatorId)
+	if err != nil {
+		return err
+	}
+
+	if len(signature) == 0 {
+		if agg.AggregatorConfig.Aggregator.RequireOperatorSignatures {
+			return fmt.Errorf("%w: missing signature", ErrInvalidSignature)
+		}
+		return nil
+	}
+
+	signedAt := time.Unix(int64(timestamp), 0)
+	if skew := time.Since(signedAt).Abs(); skew > maxRequestTimestampSkew {
+		return fmt.Errorf("%w: signed at %s, %s away from the aggregator clock", ErrInvalidSignature, signedAt.UTC(), skew.Round(time.Second))
+	}
+
+	signer, err := types.RecoverRequestSigner(signingHash, signature)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSignature, err)
+	}
+	if signer != operatorAddress {
+		return fmt.Errorf("%w: signed by %s instead of the operator address", ErrInvalidSignature, signer)
+	}
+	return nil
+}
+
+// registeredOperatorAddress returns the address of a registered operator, or ErrOperatorNotRegistered
+func (agg *Aggregator) registeredOperatorAddress(operatorId eigentypes.OperatorId) (ethcommon.Address, error) {
+	cached, ok := agg.registeredOperators.Get(operatorId)
+	if !ok || time.Now().After(cached.expiresAt) {
+		address, registered, err := agg.avsReader.GetRegisteredOperatorAddress(operatorId)
+		if err != nil {
+			return ethcommon.Address{}, fmt.Errorf("could not get operator registration: %w", err)
+		}
+
+		cached = registeredOperator{address: address, registered: registered, expiresAt: time.Now().Add(registeredOperatorsCacheTtl)}
+		if !registered {
+			cached.expiresAt = time.Now().Add(unregisteredOperatorsCacheTtl)
+		}
+		agg.registeredOperators.Add(operatorId, cached)
+	}
+
+	if !cached.registered {
+		return ethcommon.Address{}, ErrOperatorNotRegistered
+	}
+	return cached.address, nil
+}
@@ -0,0 +1,114 @@
+package pkg
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+func newTestAuthAggregator(t *testing.T, registryReader *fakeAvsRegistryReader, requireSignatures bool) *Aggregator {
+	agg := newTestAggregator(t, newTestAvsReader(t, nil, registryReader), newFakeBlsAggregationService())
+	agg.AggregatorConfig.Aggregator.RequireOperatorSignatures = requireSignatures
+	agg.requestDomain = types.RequestDomain{ChainId: big.NewInt(17000), ServiceManagerAddress: gethcommon.Address{5}}
+	return agg
+}
+
+func signedTestReport(t *testing.T, domain types.RequestDomain, operatorId eigentypes.OperatorId, signedAt time.Time) *types.VerificationFailureParams {
+	privateKey, err := crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := &types.VerificationFailureParams{
+		OperatorId: gethcommon.Hash(operatorId),
+		Reason:     "invalid_proof",
+		Timestamp:  uint64(signedAt.Unix()),
+	}
+	params.Signature, err = types.SignRequest(params.SigningHash(domain), privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return params
+}
+
+func TestAuthenticateOperatorSignatures(t *testing.T) {
+	operatorId := eigentypes.OperatorId{1}
+	// Address of the key of signedTestReport
+	operatorAddress := gethcommon.HexToAddress("0x2c7536E3605D9C16a7a3D7b1898e529396a65c23")
+	registryReader := &fakeAvsRegistryReader{operators: map[eigentypes.OperatorId]gethcommon.Address{operatorId: operatorAddress}}
+	agg := newTestAuthAggregator(t, registryReader, true)
+
+	authenticate := func(params *types.VerificationFailureParams) error {
+		return agg.authenticateOperator(operatorId, params.SigningHash(agg.requestDomain), params.Timestamp, params.Signature)
+	}
+
+	if err := authenticate(signedTestReport(t, agg.requestDomain, operatorId, time.Now())); err != nil {
+		t.Fatalf("expected a valid request to be accepted, got %v", err)
+	}
+
+	otherDomain := types.RequestDomain{ChainId: big.NewInt(1), ServiceManagerAddress: agg.requestDomain.ServiceManagerAddress}
+	tests := []struct {
+		name   string
+		params *types.VerificationFailureParams
+	}{
+		{"missing signature", &types.VerificationFailureParams{OperatorId: gethcommon.Hash(operatorId), Timestamp: uint64(time.Now().Unix())}},
+		{"signed for another chain", signedTestReport(t, otherDomain, operatorId, time.Now())},
+		{"signed too long ago", signedTestReport(t, agg.requestDomain, operatorId, time.Now().Add(-2*maxRequestTimestampSkew))},
+		{"signed in the future", signedTestReport(t, agg.requestDomain, operatorId, time.Now().Add(2*maxRequestTimestampSkew))},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := authenticate(test.params); !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("expected an invalid signature, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthenticateOperatorRejectsWrongSigner(t *testing.T) {
+	operatorId := eigentypes.OperatorId{1}
+	registryReader := &fakeAvsRegistryReader{operators: map[eigentypes.OperatorId]gethcommon.Address{operatorId: {9}}}
+	agg := newTestAuthAggregator(t, registryReader, false)
+
+	params := signedTestReport(t, agg.requestDomain, operatorId, time.Now())
+	err := agg.authenticateOperator(operatorId, params.SigningHash(agg.requestDomain), params.Timestamp, params.Signature)
+	if !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected a signature of another address to be rejected, got %v", err)
+	}
+
+	// Unsigned requests are accepted while signatures are not required
+	if err = agg.authenticateOperator(operatorId, gethcommon.Hash{}, 0, nil); err != nil {
+		t.Fatalf("expected an unsigned request to be accepted, got %v", err)
+	}
+}
+
+func TestAuthenticateOperatorCachesUnregisteredOperators(t *testing.T) {
+	operatorId := eigentypes.OperatorId{1}
+	registryReader := &fakeAvsRegistryReader{operators: map[eigentypes.OperatorId]gethcommon.Address{}}
+	agg := newTestAuthAggregator(t, registryReader, false)
+
+	for i := 0; i < 3; i++ {
+		if err := agg.authenticateOperator(operatorId, gethcommon.Hash{}, 0, nil); !errors.Is(err, ErrOperatorNotRegistered) {
+			t.Fatalf("expected an unregistered operator to be rejected, got %v", err)
+		}
+	}
+	if registryReader.lookupCount() != 1 {
+		t.Fatalf("expected the unregistered operator to be looked up once, got %d lookups", registryReader.lookupCount())
+	}
+
+	// Once the cached entry expires the new registration is seen
+	cached, _ := agg.registeredOperators.Get(operatorId)
+	cached.expiresAt = time.Now().Add(-time.Second)
+	agg.registeredOperators.Add(operatorId, cached)
+	registryReader.mutex.Lock()
+	registryReader.operators[operatorId] = gethcommon.Address{9}
+	registryReader.mutex.Unlock()
+	if err := agg.authenticateOperator(operatorId, gethcommon.Hash{}, 0, nil); err != nil {
+		t.Fatalf("expected the registered operator to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package pkg
+
+import (
+	"errors"
+	"time"
+
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+const (
+	// Signatures received before the NewBatch event of their batch are kept up to these limits
+	maxPendingSignatureBatches = 256
+	pendingSignatureTtl        = 5 * time.Minute
+	// Time the JSON-RPC handler waits for the batch of a pending signature before asking the operator to retry
+	pendingSignatureWait = 10 * time.Second
+)
+
+var errPendingSignaturesFull = errors.New("too many batches with pending signatures")
+
+// pendingSignature is an operator signature received before the NewBatch event of its batch
+type pendingSignature struct {
+	signedTaskResponse *types.SignedTaskResponse
+	receivedAt         time.Time
+	// Closed once the signature is processed, after setting result
+	done   chan struct{}
+	result types.SignedTaskResponseResult
+}
+
+func (p *pendingSignature) finish(result types.SignedTaskResponseResult) {
+	p.result = result
+	close(p.done)
+}
+
+// bufferSignature keeps the signature until the task of its batch is created. An operator has
+// at most one pending signature per batch, so the one already kept is returned if there is one.
+// Must be called with taskMutex held.
+func (agg *Aggregator) bufferSignature(signedTaskResponse *types.SignedTaskResponse) (*pendingSignature, error) {
+	agg.prunePendingSignatures(time.Now())
+
+	signatures, ok := agg.pendingSignatures[signedTaskResponse.BatchMerkleRoot]
+	if !ok {
+		if len(agg.pendingSignatures) >= maxPendingSignatureBatches {
+			return nil, errPendingSignaturesFull
+		}
+		signatures = make(map[eigentypes.OperatorId]*pendingSignature)
+		agg.pendingSignatures[signedTaskResponse.BatchMerkleRoot] = signatures
+	}
+
+	if pending, ok := signatures[signedTaskResponse.OperatorId]; ok {
+		return pending, nil
+	}
+	pending := &pendingSignature{
+		signedTaskResponse: signedTaskResponse,
+		receivedAt:         time.Now(),
+		done:               make(chan struct{}),
+	}
+	signatures[signedTaskResponse.OperatorId] = pending
+	return pending, nil
+}
+
+// prunePendingSignatures drops the signatures of batches whose event never arrived.
+// Must be called with taskMutex held.
+func (agg *Aggregator) prunePendingSignatures(now time.Time) {
+	for batchMerkleRoot, signatures := range agg.pendingSignatures {
+		for operatorId, pending := range signatures {
+			if now.Sub(pending.receivedAt) > pendingSignatureTtl {
+				pending.finish(taskResponseResult(types.TaskResponseTaskUnknownYet, taskUnknownRetryAfter, ErrTaskNotFound))
+				delete(signatures, operatorId)
+			}
+		}
+		if len(signatures) == 0 {
+			delete(agg.pendingSignatures, batchMerkleRoot)
+		}
+	}
+}
+
+// takePendingSignatures removes the signatures kept for the batch.
+// Must be called with taskMutex held.
+func (agg *Aggregator) takePendingSignatures(batchMerkleRoot [32]byte) []*pendingSignature {
+	signatures := agg.pendingSignatures[batchMerkleRoot]
+	delete(agg.pendingSignatures, batchMerkleRoot)
+
+	pendingSignatures := make([]*pendingSignature, 0, len(signatures))
+	for _, pending := range signatures {
+		pendingSignatures = append(pendingSignatures, pending)
+	}
+	return pendingSignatures
+}
+
+// returnPendingSignatures keeps again the signatures taken for a task that could not be created.
+// Must be called with taskMutex held.
+func (agg *Aggregator) returnPendingSignatures(batchMerkleRoot [32]byte, pendingSignatures []*pendingSignature) {
+	if len(pendingSignatures) == 0 {
+		return
+	}
+	signatures := make(map[eigentypes.OperatorId]*pendingSignature, len(pendingSignatures))
+	for _, pending := range pendingSignatures {
+		signatures[pending.signedTaskResponse.OperatorId] = pending
+	}
+	agg.pendingSignatures[batchMerkleRoot] = signatures
+}
+
+// processPendingSignatures adds the signatures kept for a task that was just created
+func (agg *Aggregator) processPendingSignatures(taskIndex uint32, signatures *taskSignatures, pendingSignatures []*pendingSignature) {
+	if len(pendingSignatures) > 0 {
+		agg.logger.Info("Processing signatures received before the task", "batchIndex", taskIndex, "signatures", len(pendingSignatures))
+	}
+
+	for _, pending := range pendingSignatures {
+		if !agg.beginWork() {
+			pending.finish(taskResponseResult(types.TaskResponseInternal, internalErrorRetryAfter, ErrShuttingDown))
+			continue
+		}
+		go func(pending *pendingSignature) {
+			defer agg.endWork()
+			pending.finish(agg.addSignatureToTask(taskIndex, signatures, pending.signedTaskResponse))
+		}(pending)
+	}
+}
+
+// waitPendingSignature waits up to timeout for the signature to be processed
+func (agg *Aggregator) waitPendingSignature(pending *pendingSignature, timeout time.Duration) types.SignedTaskResponseResult {
+	select {
+	case <-pending.done:
+		return pending.result
+	case <-agg.shutdownChan:
+		return taskResponseResult(types.TaskResponseInternal, internalErrorRetryAfter, ErrShuttingDown)
+	case <-time.After(timeout):
+		// The signature is still kept, sending it again waits for the same pending signature
+		return taskResponseResult(types.TaskResponseTaskUnknownYet, taskUnknownRetryAfter, ErrTaskNotFound)
+	}
+}
@@ -0,0 +1,106 @@
+package pkg
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+func bufferTestSignature(agg *Aggregator, batchMerkleRoot [32]byte, operatorId eigentypes.OperatorId) (*pendingSignature, error) {
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+	return agg.bufferSignature(&types.SignedTaskResponse{BatchMerkleRoot: batchMerkleRoot, OperatorId: operatorId})
+}
+
+func TestBufferSignatureLimitsBatches(t *testing.T) {
+	agg := newTestAggregator(t, nil, newFakeBlsAggregationService())
+
+	for i := 0; i < maxPendingSignatureBatches; i++ {
+		if _, err := bufferTestSignature(agg, [32]byte{byte(i), byte(i >> 8)}, eigentypes.OperatorId{1}); err != nil {
+			t.Fatalf("could not keep the signature of batch %d: %v", i, err)
+		}
+	}
+
+	if _, err := bufferTestSignature(agg, [32]byte{0xff, 0xff}, eigentypes.OperatorId{1}); !errors.Is(err, errPendingSignaturesFull) {
+		t.Fatalf("expected the signature of a new batch to be rejected, got %v", err)
+	}
+	// Batches that already have pending signatures keep accepting them
+	if _, err := bufferTestSignature(agg, [32]byte{0, 0}, eigentypes.OperatorId{2}); err != nil {
+		t.Fatalf("expected the signature of a known batch to be kept, got %v", err)
+	}
+}
+
+func TestBufferSignatureReturnsKeptSignature(t *testing.T) {
+	agg := newTestAggregator(t, nil, newFakeBlsAggregationService())
+
+	first, err := bufferTestSignature(agg, [32]byte{1}, eigentypes.OperatorId{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := bufferTestSignature(agg, [32]byte{1}, eigentypes.OperatorId{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Fatal("expected a resent signature to wait for the one already kept")
+	}
+}
+
+func TestPrunePendingSignatures(t *testing.T) {
+	agg := newTestAggregator(t, nil, newFakeBlsAggregationService())
+
+	expired, err := bufferTestSignature(agg, [32]byte{1}, eigentypes.OperatorId{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, err := bufferTestSignature(agg, [32]byte{2}, eigentypes.OperatorId{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired.receivedAt = time.Now().Add(-2 * pendingSignatureTtl)
+
+	agg.taskMutex.Lock()
+	agg.prunePendingSignatures(time.Now())
+	agg.taskMutex.Unlock()
+
+	select {
+	case <-expired.done:
+	default:
+		t.Fatal("expected the expired signature to be finished")
+	}
+	if expired.result.Status != types.TaskResponseTaskUnknownYet {
+		t.Errorf("expected the expired signature to be asked to retry, got %s", expired.result.Status)
+	}
+	if _, ok := agg.pendingSignatures[[32]byte{1}]; ok {
+		t.Error("expected the batch of the expired signature to be removed")
+	}
+	if pending := agg.pendingSignatures[[32]byte{2}][eigentypes.OperatorId{1}]; pending != kept {
+		t.Error("expected the signature within its ttl to be kept")
+	}
+}
+
+func TestAddNewTaskProcessesPendingSignatures(t *testing.T) {
+	blsAggregationService := newFakeBlsAggregationService()
+	agg := newTestAggregator(t, nil, blsAggregationService)
+	batchMerkleRoot := [32]byte{1}
+
+	pending, err := bufferTestSignature(agg, batchMerkleRoot, eigentypes.OperatorId{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	agg.AddNewTask(batchMerkleRoot, 10)
+
+	result := agg.waitPendingSignature(pending, time.Second)
+	if result.Status != types.TaskResponseAccepted {
+		t.Fatalf("expected the pending signature to be accepted, got %+v", result)
+	}
+	if blsAggregationService.signatureCount(0) != 1 {
+		t.Fatal("expected the pending signature to be sent to the BLS aggregation service")
+	}
+	if len(agg.pendingSignatures) != 0 {
+		t.Error("expected the pending signatures of the task to be taken")
+	}
+}
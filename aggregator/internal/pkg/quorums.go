@@ -0,0 +1,121 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/yetanotherco/aligned_layer/metrics"
+)
+
+var (
+	ErrQuorumThresholdMismatch    = errors.New("quorum threshold percentage does not match the service manager")
+	ErrQuorumThresholdUnavailable = errors.New("could not read the quorum threshold percentage of the service manager")
+)
+
+// taskQuorums are the quorums a task is signed in, and the percentage of the stake of each one that must sign it
+type taskQuorums struct {
+	numbers              eigentypes.QuorumNums
+	thresholdPercentages eigentypes.QuorumThresholdPercentages
+}
+
+// quorumReader reads the quorums and the threshold the service manager checks, implemented by chainio.AvsReader
+type quorumReader interface {
+	GetQuorumNumbers(ctx context.Context) (eigentypes.QuorumNums, error)
+	GetQuorumThresholdPercentage(ctx context.Context) (eigentypes.QuorumThresholdPercentage, error)
+}
+
+// quorumTracker keeps the quorums and the threshold the service manager checks, which are
+// read again periodically, so new tasks are signed the way the contract checks them
+type quorumTracker struct {
+	avsReader quorumReader
+	// Threshold of the aggregator config, expected to match the service manager one
+	thresholdPercentage eigentypes.QuorumThresholdPercentage
+	logger              logging.Logger
+	metrics             *metrics.Metrics
+
+	// Protects quorums
+	mutex   *sync.Mutex
+	quorums taskQuorums
+}
+
+func newQuorumTracker(avsReader quorumReader, thresholdPercentage uint8, logger logging.Logger, metrics *metrics.Metrics) *quorumTracker {
+	return &quorumTracker{
+		avsReader:           avsReader,
+		thresholdPercentage: eigentypes.QuorumThresholdPercentage(thresholdPercentage),
+		logger:              logger,
+		metrics:             metrics,
+		mutex:               &sync.Mutex{},
+	}
+}
+
+// current returns the quorums new tasks are signed in
+func (t *quorumTracker) current() taskQuorums {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.quorums
+}
+
+// monitor reads the quorums every interval until ctx is done. New tasks keep the
+// previous quorums if they can't be read
+func (t *quorumTracker) monitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.refresh(ctx); err != nil {
+				t.logger.Error("Could not refresh quorums", "err", err)
+				t.metrics.IncAggregatorQuorumRefreshFailures()
+			}
+		}
+	}
+}
+
+// refresh reads the quorums and the threshold of the service manager. If the threshold differs from
+// the configured one, the service manager one is used, since responses under it are rejected, and
+// ErrQuorumThresholdMismatch is returned. If the threshold can't be read, the quorums are not
+// updated and ErrQuorumThresholdUnavailable is returned
+func (t *quorumTracker) refresh(ctx context.Context) error {
+	quorumNumbers, err := t.avsReader.GetQuorumNumbers(ctx)
+	if err != nil {
+		return fmt.Errorf("could not read quorum numbers: %w", err)
+	}
+	if len(quorumNumbers) == 0 {
+		return errors.New("the service manager checks no quorums")
+	}
+
+	var mismatchErr error
+	thresholdPercentage, err := t.avsReader.GetQuorumThresholdPercentage(ctx)
+	if err != nil {
+		// Service managers deployed before the threshold was public don't have the getter
+		return fmt.Errorf("%w: %v", ErrQuorumThresholdUnavailable, err)
+	}
+	if thresholdPercentage != t.thresholdPercentage {
+		mismatchErr = fmt.Errorf("%w: configured %d, service manager %d", ErrQuorumThresholdMismatch, t.thresholdPercentage, thresholdPercentage)
+	}
+
+	thresholdPercentages := make(eigentypes.QuorumThresholdPercentages, len(quorumNumbers))
+	for i := range thresholdPercentages {
+		thresholdPercentages[i] = thresholdPercentage
+	}
+
+	t.mutex.Lock()
+	changed := !slices.Equal(quorumNumbers, t.quorums.numbers) ||
+		!slices.Equal(thresholdPercentages, t.quorums.thresholdPercentages)
+	t.quorums = taskQuorums{numbers: quorumNumbers, thresholdPercentages: thresholdPercentages}
+	t.mutex.Unlock()
+
+	if changed {
+		t.logger.Info("Quorums updated", "quorumNumbers", quorumNumbers, "thresholdPercentage", thresholdPercentage)
+	}
+	return mismatchErr
+}
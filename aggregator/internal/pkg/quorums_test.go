@@ -0,0 +1,111 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yetanotherco/aligned_layer/metrics"
+)
+
+type fakeQuorumReader struct {
+	quorumNumbers       eigentypes.QuorumNums
+	thresholdPercentage eigentypes.QuorumThresholdPercentage
+	thresholdErr        error
+}
+
+func (r *fakeQuorumReader) GetQuorumNumbers(ctx context.Context) (eigentypes.QuorumNums, error) {
+	return r.quorumNumbers, nil
+}
+
+func (r *fakeQuorumReader) GetQuorumThresholdPercentage(ctx context.Context) (eigentypes.QuorumThresholdPercentage, error) {
+	return r.thresholdPercentage, r.thresholdErr
+}
+
+func newTestMetrics() *metrics.Metrics {
+	return metrics.NewMetrics("", prometheus.NewRegistry(), logging.NewNoopLogger())
+}
+
+// updatesLogger counts the quorum updates logged
+type updatesLogger struct {
+	logging.Logger
+	updates int
+}
+
+func (l *updatesLogger) Info(msg string, tags ...any) {
+	if msg == "Quorums updated" {
+		l.updates++
+	}
+}
+
+func TestQuorumTrackerRefreshDetectsChanges(t *testing.T) {
+	reader := &fakeQuorumReader{quorumNumbers: eigentypes.QuorumNums{0}, thresholdPercentage: 67}
+	logger := &updatesLogger{Logger: logging.NewNoopLogger()}
+	tracker := newQuorumTracker(reader, 67, logger, newTestMetrics())
+
+	if err := tracker.refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if logger.updates != 1 {
+		t.Fatalf("expected a single update for unchanged quorums, got %d", logger.updates)
+	}
+
+	// Other quorums with the same count
+	reader.quorumNumbers = eigentypes.QuorumNums{1}
+	if err := tracker.refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if logger.updates != 2 {
+		t.Fatal("expected a change of quorum numbers to be detected")
+	}
+
+	// Another threshold with the same quorums
+	reader.thresholdPercentage = 80
+	if err := tracker.refresh(context.Background()); !errors.Is(err, ErrQuorumThresholdMismatch) {
+		t.Fatalf("expected a threshold mismatch, got %v", err)
+	}
+	if logger.updates != 3 {
+		t.Fatal("expected a change of threshold to be detected")
+	}
+}
+
+func TestQuorumTrackerRefreshUsesServiceManagerThreshold(t *testing.T) {
+	reader := &fakeQuorumReader{quorumNumbers: eigentypes.QuorumNums{0, 1}, thresholdPercentage: 80}
+	tracker := newQuorumTracker(reader, 67, logging.NewNoopLogger(), newTestMetrics())
+
+	if err := tracker.refresh(context.Background()); !errors.Is(err, ErrQuorumThresholdMismatch) {
+		t.Fatalf("expected a threshold mismatch, got %v", err)
+	}
+	quorums := tracker.current()
+	if !slices.Equal(quorums.numbers, eigentypes.QuorumNums{0, 1}) ||
+		!slices.Equal(quorums.thresholdPercentages, eigentypes.QuorumThresholdPercentages{80, 80}) {
+		t.Fatalf("unexpected quorums %v with thresholds %v", quorums.numbers, quorums.thresholdPercentages)
+	}
+}
+
+func TestQuorumTrackerRefreshFailsWithoutThreshold(t *testing.T) {
+	reader := &fakeQuorumReader{quorumNumbers: eigentypes.QuorumNums{0}, thresholdPercentage: 67}
+	tracker := newQuorumTracker(reader, 67, logging.NewNoopLogger(), newTestMetrics())
+	if err := tracker.refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Service managers without the threshold getter can't be checked
+	reader.quorumNumbers = eigentypes.QuorumNums{1}
+	reader.thresholdErr = errors.New("execution reverted")
+	if err := tracker.refresh(context.Background()); !errors.Is(err, ErrQuorumThresholdUnavailable) {
+		t.Fatalf("expected the threshold to be unavailable, got %v", err)
+	}
+	quorums := tracker.current()
+	if !slices.Equal(quorums.numbers, eigentypes.QuorumNums{0}) ||
+		!slices.Equal(quorums.thresholdPercentages, eigentypes.QuorumThresholdPercentages{67}) {
+		t.Fatalf("expected the previous quorums to be kept, got %v with thresholds %v", quorums.numbers, quorums.thresholdPercentages)
+	}
+}
@@ -2,117 +2,338 @@ package pkg
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/rpc"
+	"strings"
+	"sync"
 	"time"
 
+	blsagg "github.com/Layr-Labs/eigensdk-go/services/bls_aggregation"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/yetanotherco/aligned_layer/aggregator/internal/store"
 	"github.com/yetanotherco/aligned_layer/core/types"
+	"github.com/yetanotherco/aligned_layer/core/utils"
 )
 
-const waitForEventRetries = 50
-const waitForEventSleepSeconds = 4 * time.Second
+// The deprecated net/rpc API can't ask the operator to retry, so it waits longer for unknown tasks
+const netRpcPendingSignatureWait = 200 * time.Second
 
-func (agg *Aggregator) ServeOperators() error {
-	// Registers a new RPC server
-	err := rpc.Register(agg)
-	if err != nil {
-		return err
+const (
+	// Time the operator should wait before sending again a response whose task is not known yet
+	taskUnknownRetryAfter = 4 * time.Second
+	// Time the operator should wait before sending again a response that could not be processed
+	internalErrorRetryAfter = 10 * time.Second
+	// Time an RPC call waits for the BLS aggregation service to process a signature
+	defaultBlsSignatureTimeout = 5 * time.Second
+)
+
+// Message prefix of blsagg.SignatureVerificationError, followed by the verification error
+var blsSignatureVerificationErrorPrefix = blsagg.SignatureVerificationError(errors.New("")).Error()
+
+var (
+	ErrShuttingDown          = errors.New("aggregator is shutting down")
+	ErrTaskNotFound          = errors.New("task not found")
+	ErrSignatureNotProcessed = errors.New("signature not processed by the bls aggregation service")
+)
+
+// ServeOperators serves the RPC methods until ctx is done, then stops accepting connections
+func (agg *Aggregator) ServeOperators(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(types.AggregatorJsonRpcPath, agg.serveJsonRpc)
+
+	// Registers the deprecated net/rpc server, kept for operators that don't use the JSON-RPC API yet.
+	// It can't carry request signatures, so it is disabled when they are required.
+	if !agg.AggregatorConfig.Aggregator.RequireOperatorSignatures {
+		rpcServer := rpc.NewServer()
+		err := rpcServer.Register(agg)
+		if err != nil {
+			return err
+		}
+		mux.Handle(rpc.DefaultRPCPath, rpcServer)
 	}
 
-	// Registers an HTTP handler for RPC messages
-	rpc.HandleHTTP()
+	server := &http.Server{
+		Addr:    agg.AggregatorConfig.Aggregator.ServerIpPortAddress,
+		Handler: mux,
+	}
+	tlsEnabled := agg.AggregatorConfig.Aggregator.TlsCertFile != ""
+	if tlsEnabled {
+		tlsConfig, err := agg.serverTlsConfig()
+		if err != nil {
+			return err
+		}
+		server.TLSConfig = tlsConfig
+	}
+
+	// RPC connections are hijacked from the HTTP server, so the calls in process
+	// are not tracked by Shutdown but by the aggregator pending work
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), agg.AggregatorConfig.Aggregator.ShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			agg.logger.Warn("Could not shut down RPC server", "err", err)
+		}
+	}()
 
 	// Start listening for requests on aggregator address
 	// ServeOperators accepts incoming HTTP connections on the listener, creating
 	// a new service goroutine for each. The service goroutines read requests
 	// and then call handler to reply to them
 	agg.logger.Info("Starting RPC server on address", "address",
-		agg.AggregatorConfig.Aggregator.ServerIpPortAddress)
+		agg.AggregatorConfig.Aggregator.ServerIpPortAddress, "tls", tlsEnabled)
 
-	err = http.ListenAndServe(agg.AggregatorConfig.Aggregator.ServerIpPortAddress, nil)
-	if err != nil {
+	var err error
+	if tlsEnabled {
+		err = server.ListenAndServeTLS(agg.AggregatorConfig.Aggregator.TlsCertFile, agg.AggregatorConfig.Aggregator.TlsKeyFile)
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return err
 	}
 
 	return nil
 }
 
+// serverTlsConfig requires operators to present a client certificate signed
+// by the configured CA, if there is one
+func (agg *Aggregator) serverTlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if agg.AggregatorConfig.Aggregator.TlsClientCaFile != "" {
+		clientCAs, err := utils.LoadCertPool(agg.AggregatorConfig.Aggregator.TlsClientCaFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client CA: %w", err)
+		}
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
 // Aggregator Methods
 // This is the list of methods that the Aggregator exposes to the Operator
 // The Operator can call these methods to interact with the Aggregator
 // This methods are automatically registered by the RPC server
-// This takes a response an adds it to the internal. If reaching the quorum, it sends the aggregated signatures to ethereum
+// Deprecated: the net/rpc methods are kept while operators move to the JSON-RPC API
+// This takes a response an adds it to the internal. If reaching the quorum, it sends the aggregated signatures to ethereum.
+// It waits for the task to be created, since the operator may receive the batch event before the aggregator.
 // Returns:
 //   - 0: Success
 //   - 1: Error
+//
+// Errors that may be solved by sending the response again are returned as an RPC error instead
 func (agg *Aggregator) ProcessOperatorSignedTaskResponse(signedTaskResponse *types.SignedTaskResponse, reply *uint8) error {
+	result := agg.submitSignedTaskResponse(signedTaskResponse, ethcommon.Hash{}, 0, nil, netRpcPendingSignatureWait)
+
+	switch {
+	case result.Delivered():
+		*reply = 0
+	case result.RetryAfter > 0:
+		return fmt.Errorf("%s: %s", result.Status, result.Message)
+	default:
+		*reply = 1
+	}
+	return nil
+}
+
+// submitSignedTaskResponse authenticates the operator and adds its signature to the task.
+// If the task does not exist yet, it waits up to pendingWait for it to be created.
+func (agg *Aggregator) submitSignedTaskResponse(signedTaskResponse *types.SignedTaskResponse, signingHash ethcommon.Hash, timestamp uint64, signature []byte, pendingWait time.Duration) types.SignedTaskResponseResult {
+	if !agg.beginWork() {
+		return taskResponseResult(types.TaskResponseInternal, internalErrorRetryAfter, ErrShuttingDown)
+	}
+	defer agg.endWork()
+
+	err := agg.authenticateOperator(signedTaskResponse.OperatorId, signingHash, timestamp, signature)
+	if err != nil {
+		agg.logger.Warn("Rejected signed task response", "operatorId", hex.EncodeToString(signedTaskResponse.OperatorId[:]), "err", err)
+		switch {
+		case errors.Is(err, ErrOperatorNotRegistered):
+			return taskResponseResult(types.TaskResponseOperatorNotRegistered, 0, err)
+		case errors.Is(err, ErrInvalidSignature):
+			return taskResponseResult(types.TaskResponseInvalidSignature, 0, err)
+		default:
+			return taskResponseResult(types.TaskResponseInternal, internalErrorRetryAfter, err)
+		}
+	}
+
+	return agg.processSignedTaskResponse(signedTaskResponse, pendingWait)
+}
+
+// processSignedTaskResponse adds the operator signature to the BLS aggregation of its task.
+// Signatures received before the NewBatch event of their batch are kept until the task is created.
+func (agg *Aggregator) processSignedTaskResponse(signedTaskResponse *types.SignedTaskResponse, pendingWait time.Duration) types.SignedTaskResponseResult {
 	agg.AggregatorConfig.BaseConfig.Logger.Info("New task response",
 		"merkleRoot", hex.EncodeToString(signedTaskResponse.BatchMerkleRoot[:]),
 		"operatorId", hex.EncodeToString(signedTaskResponse.OperatorId[:]))
 
-	taskIndex := uint32(0)
-	ok := false
-
-	for i := 0; i < waitForEventRetries; i++ {
-		agg.taskMutex.Lock()
-		agg.AggregatorConfig.BaseConfig.Logger.Info("- Locked Resources: Starting processing of Response")
-		taskIndex, ok = agg.batchesIdxByRoot[signedTaskResponse.BatchMerkleRoot]
-		if !ok {
-			agg.taskMutex.Unlock()
-			agg.logger.Info("- Unlocked Resources: Task not found in the internal map")
-			time.Sleep(waitForEventSleepSeconds)
-		} else {
-			break
-		}
+	var pending *pendingSignature
+	var err error
+	agg.taskMutex.Lock()
+	taskIndex, ok := agg.batchesIdxByRoot[signedTaskResponse.BatchMerkleRoot]
+	signatures := agg.taskSignatures[signedTaskResponse.BatchMerkleRoot]
+	finished := !ok && agg.isFinishedTask(signedTaskResponse.BatchMerkleRoot)
+	if !ok && !finished {
+		pending, err = agg.bufferSignature(signedTaskResponse)
 	}
+	agg.taskMutex.Unlock()
 
+	if finished {
+		return taskResponseResult(types.TaskResponseTaskExpired, 0, errors.New("task already finished"))
+	}
 	if !ok {
-		agg.logger.Warn("Task not found in the internal map, operator signature will be lost. Batch may not reach quorum")
-		*reply = 1
-		return nil
+		if err != nil {
+			agg.logger.Warn("Could not keep signature of unknown task", "err", err)
+			return taskResponseResult(types.TaskResponseTaskUnknownYet, taskUnknownRetryAfter, err)
+		}
+		agg.logger.Info("Task not found in the internal map, waiting for the batch event")
+		return agg.waitPendingSignature(pending, pendingWait)
+	}
+
+	return agg.addSignatureToTask(taskIndex, signatures, signedTaskResponse)
+}
+
+// isFinishedTask returns true if the task was responded or expired, and forgotten
+func (agg *Aggregator) isFinishedTask(batchMerkleRoot [32]byte) bool {
+	task, err := agg.taskStore.GetTask(batchMerkleRoot)
+	return err == nil && (task.Responded || task.Expired)
+}
+
+// taskSignatures serializes the signatures of a task, so signatures for different
+// tasks are processed in parallel
+type taskSignatures struct {
+	mutex sync.Mutex
+	// Operators whose signature the BLS aggregation service accepted or is still processing
+	operators map[eigentypes.OperatorId]bool
+}
+
+func newTaskSignatures() *taskSignatures {
+	return &taskSignatures{operators: make(map[eigentypes.OperatorId]bool)}
+}
+
+// addSignatureToTask sends the signature to the BLS aggregation service, unless the operator
+// already signed the task
+func (agg *Aggregator) addSignatureToTask(taskIndex uint32, signatures *taskSignatures, signedTaskResponse *types.SignedTaskResponse) types.SignedTaskResponseResult {
+	signatures.mutex.Lock()
+	defer signatures.mutex.Unlock()
+
+	// The BLS aggregation service adds every signature it receives, so an operator
+	// signing twice would have its stake counted twice. Its signature may still be
+	// processed after the call timed out, before being stored
+	operatorId := signedTaskResponse.OperatorId
+	if signatures.operators[operatorId] {
+		return taskResponseResult(types.TaskResponseDuplicate, 0, nil)
+	}
+	// The accepted signatures are missing from the store only if the task could not be persisted
+	task, err := agg.taskStore.GetTask(signedTaskResponse.BatchMerkleRoot)
+	switch {
+	case errors.Is(err, store.ErrTaskNotFound):
+		agg.logger.Warn("Task not found in the task store, duplicate signatures can't be detected")
+	case err != nil:
+		agg.logger.Error("Could not get task from the task store", "err", err)
+		return taskResponseResult(types.TaskResponseInternal, internalErrorRetryAfter, err)
+	case task.Responded:
+		return taskResponseResult(types.TaskResponseTaskExpired, 0, errors.New("task already responded"))
+	default:
+		for _, signature := range task.Signatures {
+			if signature.OperatorId == operatorId {
+				return taskResponseResult(types.TaskResponseDuplicate, 0, nil)
+			}
+		}
 	}
 
 	// Don't wait infinitely if it can't answer
-	// Create a context with a timeout of 5 seconds
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), agg.blsSignatureTimeout)
 	defer cancel() // Ensure the cancel function is called to release resources
 
-	// Create a channel to signal when the task is done
-	done := make(chan struct{})
+	// Receives the result of the bls signature process, unless it timed out
+	done := make(chan error)
+	signatures.operators[operatorId] = true
 
 	agg.logger.Info("Starting bls signature process")
 	go func() {
 		err := agg.blsAggregationService.ProcessNewSignature(
 			context.Background(), taskIndex, signedTaskResponse.BatchMerkleRoot,
-			&signedTaskResponse.BlsSignature, signedTaskResponse.OperatorId,
+			&signedTaskResponse.BlsSignature, operatorId,
 		)
 
 		if err != nil {
 			agg.logger.Warnf("BLS aggregation service error: %s", err)
 		} else {
 			agg.logger.Info("BLS process succeeded")
+
+			storeErr := agg.taskStore.AddSignature(signedTaskResponse.BatchMerkleRoot, *signedTaskResponse)
+			if storeErr != nil {
+				agg.logger.Warn("Could not store operator signature", "err", storeErr)
+			}
 		}
 
-		close(done)
+		select {
+		case done <- err:
+		case <-ctx.Done():
+			// The call already returned and released the task, the operator can sign again
+			if err != nil {
+				signatures.mutex.Lock()
+				delete(signatures.operators, operatorId)
+				signatures.mutex.Unlock()
+			}
+		}
 	}()
 
-	*reply = 1
 	// Wait for either the context to be done or the task to complete
 	select {
 	case <-ctx.Done():
 		// The context's deadline was exceeded or it was canceled
 		agg.logger.Info("Bls process timed out, operator signature will be lost. Batch may not reach quorum")
-	case <-done:
+		return taskResponseResult(types.TaskResponseInternal, internalErrorRetryAfter,
+			fmt.Errorf("%w: timed out", ErrSignatureNotProcessed))
+	case blsErr := <-done:
+		if blsErr != nil {
+			delete(signatures.operators, operatorId)
+			return blsErrorResult(blsErr, taskIndex, operatorId)
+		}
 		// The task completed successfully
 		agg.logger.Info("Bls context finished correctly")
-		*reply = 0
+		return taskResponseResult(types.TaskResponseAccepted, 0, nil)
 	}
+}
 
-	agg.AggregatorConfig.BaseConfig.Logger.Info("- Unlocked Resources: Task response processing finished")
-	agg.taskMutex.Unlock()
+// blsErrorResult classifies the errors of the BLS aggregation service. Most of them can't be
+// matched with errors.Is, as the service creates them with fmt.Errorf, so their messages are
+// compared with the ones of the same constructors. TestBlsErrorResult pins them.
+func blsErrorResult(blsErr error, taskIndex uint32, operatorId eigentypes.OperatorId) types.SignedTaskResponseResult {
+	err := fmt.Errorf("%w: %s", ErrSignatureNotProcessed, blsErr)
+	message := blsErr.Error()
+	switch {
+	case message == blsagg.TaskNotFoundErrorFn(taskIndex).Error():
+		// The task goroutine ends when the task reaches quorum or expires
+		return taskResponseResult(types.TaskResponseTaskExpired, 0, err)
+	case message == blsagg.OperatorNotPartOfTaskQuorumErrorFn(operatorId, taskIndex).Error():
+		return taskResponseResult(types.TaskResponseOperatorNotRegistered, 0, err)
+	case errors.Is(blsErr, blsagg.IncorrectSignatureError), strings.HasPrefix(message, blsSignatureVerificationErrorPrefix):
+		return taskResponseResult(types.TaskResponseInvalidSignature, 0, err)
+	default:
+		return taskResponseResult(types.TaskResponseInternal, internalErrorRetryAfter, err)
+	}
+}
 
-	return nil
+func taskResponseResult(status types.TaskResponseStatus, retryAfter time.Duration, err error) types.SignedTaskResponseResult {
+	result := types.SignedTaskResponseResult{
+		Status:     status,
+		RetryAfter: uint32(retryAfter / time.Second),
+	}
+	if err != nil {
+		result.Message = err.Error()
+	}
+	return result
 }
 
 // Dummy method to check if the server is running
@@ -121,3 +342,40 @@ func (agg *Aggregator) ServerRunning(_ *struct{}, reply *int64) error {
 	*reply = 1
 	return nil
 }
+
+// ProcessOperatorVerificationFailure receives the reason an operator refused to sign a batch.
+// Deprecated: kept for operators that don't use the JSON-RPC API yet
+// Returns:
+//   - 0: Success
+//   - 1: Error
+func (agg *Aggregator) ProcessOperatorVerificationFailure(report *types.VerificationFailureReport, reply *uint8) error {
+	if !agg.beginWork() {
+		return ErrShuttingDown
+	}
+	defer agg.endWork()
+
+	err := agg.authenticateOperator(report.OperatorId, ethcommon.Hash{}, 0, nil)
+	if err != nil {
+		agg.logger.Warn("Rejected verification failure report", "operatorId", hex.EncodeToString(report.OperatorId[:]), "err", err)
+		*reply = 1
+		return nil
+	}
+
+	agg.processVerificationFailure(report)
+
+	*reply = 0
+	return nil
+}
+
+// processVerificationFailure logs and counts the report,
+// it does not affect the BLS aggregation of the batch
+func (agg *Aggregator) processVerificationFailure(report *types.VerificationFailureReport) {
+	agg.logger.Warn("Operator reported a verification failure",
+		"merkleRoot", hex.EncodeToString(report.BatchMerkleRoot[:]),
+		"operatorId", hex.EncodeToString(report.OperatorId[:]),
+		"batchIndex", report.BatchIndex,
+		"provingSystem", report.ProvingSystem,
+		"reason", report.Reason,
+		"message", report.Message)
+	agg.metrics.IncReportedVerificationFailures(report.ProvingSystem, report.Reason)
+}
@@ -0,0 +1,98 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	blsagg "github.com/Layr-Labs/eigensdk-go/services/bls_aggregation"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+func TestBlsErrorResult(t *testing.T) {
+	operatorId := eigentypes.OperatorId{1}
+
+	// The error of the task that is not initialized is the one returned by the service
+	blsAggregationService := blsagg.NewBlsAggregatorService(nil, logging.NewNoopLogger())
+	taskNotFoundErr := blsAggregationService.ProcessNewSignature(context.Background(), 3, [32]byte{}, bls.NewZeroSignature(), operatorId)
+	if taskNotFoundErr == nil {
+		t.Fatal("expected an error for a task that is not initialized")
+	}
+
+	tests := []struct {
+		name   string
+		err    error
+		status types.TaskResponseStatus
+	}{
+		{"task not found", taskNotFoundErr, types.TaskResponseTaskExpired},
+		{"operator not in quorum", blsagg.OperatorNotPartOfTaskQuorumErrorFn(operatorId, 3), types.TaskResponseOperatorNotRegistered},
+		{"incorrect signature", blsagg.SignatureVerificationError(blsagg.IncorrectSignatureError), types.TaskResponseInvalidSignature},
+		{"signature not verified", blsagg.SignatureVerificationError(errors.New("invalid point")), types.TaskResponseInvalidSignature},
+		{"other task", blsagg.TaskNotFoundErrorFn(4), types.TaskResponseInternal},
+		{"unknown", errors.New("unknown"), types.TaskResponseInternal},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := blsErrorResult(test.err, 3, operatorId)
+			if result.Status != test.status {
+				t.Fatalf("expected status %s, got %s", test.status, result.Status)
+			}
+			if (result.RetryAfter > 0) != (test.status == types.TaskResponseInternal) {
+				t.Errorf("unexpected retry after %d", result.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestSlowSignatureIsNotCountedTwice(t *testing.T) {
+	blsAggregationService := newFakeBlsAggregationService()
+	blsAggregationService.processDelay = 200 * time.Millisecond
+	agg := newTestAggregator(t, nil, blsAggregationService)
+	agg.blsSignatureTimeout = 20 * time.Millisecond
+	batchMerkleRoot := [32]byte{1}
+	agg.AddNewTask(batchMerkleRoot, 10)
+	signedTaskResponse := &types.SignedTaskResponse{BatchMerkleRoot: batchMerkleRoot, OperatorId: eigentypes.OperatorId{1}}
+
+	if result := agg.processSignedTaskResponse(signedTaskResponse, 0); result.Status != types.TaskResponseInternal {
+		t.Fatalf("expected the call to time out, got %+v", result)
+	}
+	// The retry arrives while the first signature is still being processed
+	if result := agg.processSignedTaskResponse(signedTaskResponse, 0); result.Status != types.TaskResponseDuplicate {
+		t.Fatalf("expected the retry to be a duplicate, got %+v", result)
+	}
+
+	time.Sleep(2 * blsAggregationService.processDelay)
+	if count := blsAggregationService.signatureCount(0); count != 1 {
+		t.Fatalf("expected the signature to be processed once, got %d", count)
+	}
+}
+
+func TestSlowFailedSignatureCanBeSentAgain(t *testing.T) {
+	blsAggregationService := newFakeBlsAggregationService()
+	blsAggregationService.processDelay = 100 * time.Millisecond
+	blsAggregationService.processErr = errors.New("unknown")
+	agg := newTestAggregator(t, nil, blsAggregationService)
+	agg.blsSignatureTimeout = 20 * time.Millisecond
+	batchMerkleRoot := [32]byte{1}
+	agg.AddNewTask(batchMerkleRoot, 10)
+	signedTaskResponse := &types.SignedTaskResponse{BatchMerkleRoot: batchMerkleRoot, OperatorId: eigentypes.OperatorId{1}}
+
+	if result := agg.processSignedTaskResponse(signedTaskResponse, 0); result.Status != types.TaskResponseInternal {
+		t.Fatalf("expected the call to time out, got %+v", result)
+	}
+
+	// Once the signature fails, the operator can send it again
+	time.Sleep(2 * blsAggregationService.processDelay)
+	blsAggregationService.setProcessErr(nil)
+	agg.blsSignatureTimeout = time.Second
+	if result := agg.processSignedTaskResponse(signedTaskResponse, 0); result.Status != types.TaskResponseAccepted {
+		t.Fatalf("expected the signature sent again to be accepted, got %+v", result)
+	}
+	if count := blsAggregationService.signatureCount(0); count != 1 {
+		t.Fatalf("expected the signature to be processed once, got %d", count)
+	}
+}
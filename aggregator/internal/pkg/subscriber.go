@@ -1,11 +1,14 @@
 package pkg
 
 import (
+	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
 )
 
 const (
@@ -13,28 +16,52 @@ const (
 	RetryInterval = 1 * time.Second
 )
 
-func (agg *Aggregator) SubscribeToNewTasks() error {
+// SubscribeToNewTasks adds a task for each new batch until ctx is done
+func (agg *Aggregator) SubscribeToNewTasks(ctx context.Context) error {
 	for retries := 0; retries < MaxRetries; retries++ {
 		err := agg.tryCreateTaskSubscriber()
 		if err == nil {
-			_ = agg.subscribeToNewTasks() // This will block until an error occurs
+			// The subscription is created before backfilling, so no event is missed
+			// between the end of the backfill and the start of the live watch
+			err = agg.backfillMissedTasks(ctx)
+			if err != nil {
+				agg.AggregatorConfig.BaseConfig.Logger.Warn("Failed to backfill missed tasks", "err", err)
+				agg.taskSubscriber.Unsubscribe()
+			} else {
+				_ = agg.subscribeToNewTasks(ctx) // This will block until an error occurs or ctx is done
+			}
+		}
+
+		if ctx.Err() != nil {
+			return nil
 		}
 
 		message := fmt.Sprintf("Failed to subscribe to new tasks. Retrying in %v", RetryInterval)
 		agg.AggregatorConfig.BaseConfig.Logger.Info(message)
-		time.Sleep(RetryInterval)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(RetryInterval):
+		}
 	}
 
 	return errors.New("failed to subscribe to new tasks after max retries")
 }
 
-func (agg *Aggregator) subscribeToNewTasks() error {
+func (agg *Aggregator) subscribeToNewTasks(ctx context.Context) error {
 	for {
 		select {
+		case <-ctx.Done():
+			agg.taskSubscriber.Unsubscribe()
+			return nil
 		case err := <-agg.taskSubscriber.Err():
 			return err
 		case newBatch := <-agg.NewBatchChan:
-			agg.AddNewTask(newBatch.BatchMerkleRoot, newBatch.TaskCreatedBlock)
+			// The events of the blocks that were backfilled may be sent again
+			if agg.isKnownBatch(newBatch.BatchMerkleRoot) {
+				continue
+			}
+			agg.processNewBatch(newBatch)
 		}
 	}
 }
@@ -51,3 +78,84 @@ func (agg *Aggregator) tryCreateTaskSubscriber() error {
 	}
 	return err
 }
+
+// backfillMissedTasks adds the tasks of the NewBatch events emitted since the last
+// processed block, looking back at most MaxBackfillBlocks blocks, which were missed
+// while the subscription was down or the aggregator was stopped
+func (agg *Aggregator) backfillMissedTasks(ctx context.Context) error {
+	currentBlock, err := agg.avsReader.GetBlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+
+	lastProcessedBlock, ok, err := agg.taskStore.GetLastProcessedBlock()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// Nothing was processed before, start from the current block
+		return agg.taskStore.SetLastProcessedBlock(currentBlock)
+	}
+
+	// The last processed block is queried again, since the aggregator could have
+	// stopped before processing all of its events. Known batches are skipped.
+	fromBlock := lastProcessedBlock
+	maxBackfillBlocks := agg.AggregatorConfig.Aggregator.MaxBackfillBlocks
+	if currentBlock > maxBackfillBlocks && fromBlock < currentBlock-maxBackfillBlocks {
+		fromBlock = currentBlock - maxBackfillBlocks
+		agg.logger.Warn("Last processed block is older than the max backfill window, older batches will be skipped",
+			"lastProcessedBlock", lastProcessedBlock, "maxBackfillBlocks", maxBackfillBlocks)
+	}
+
+	agg.logger.Info("Backfilling missed tasks", "fromBlock", fromBlock, "toBlock", currentBlock)
+	newBatches, err := agg.avsReader.FilterNewBatches(ctx, fromBlock, currentBlock)
+	if err != nil {
+		return err
+	}
+
+	for _, newBatch := range newBatches {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if agg.isKnownBatch(newBatch.BatchMerkleRoot) {
+			continue
+		}
+
+		responded, err := agg.avsReader.IsBatchResponded(newBatch.BatchMerkleRoot)
+		if err != nil {
+			return err
+		}
+		if responded {
+			agg.logger.Info("Skipping already responded batch",
+				"merkleRoot", hex.EncodeToString(newBatch.BatchMerkleRoot[:]))
+			continue
+		}
+
+		agg.processNewBatch(newBatch)
+	}
+
+	return agg.taskStore.SetLastProcessedBlock(currentBlock)
+}
+
+func (agg *Aggregator) processNewBatch(newBatch *servicemanager.ContractAlignedLayerServiceManagerNewBatch) {
+	agg.AddNewTask(newBatch.BatchMerkleRoot, newBatch.TaskCreatedBlock)
+
+	err := agg.taskStore.SetLastProcessedBlock(newBatch.Raw.BlockNumber)
+	if err != nil {
+		agg.logger.Warn("Could not store last processed block", "block", newBatch.Raw.BlockNumber, "err", err)
+	}
+}
+
+// isKnownBatch returns true if the batch was already added as a task, either
+// in this run or before a restart
+func (agg *Aggregator) isKnownBatch(batchMerkleRoot [32]byte) bool {
+	agg.taskMutex.Lock()
+	_, ok := agg.batchesIdxByRoot[batchMerkleRoot]
+	agg.taskMutex.Unlock()
+	if ok {
+		return true
+	}
+
+	_, err := agg.taskStore.GetTask(batchMerkleRoot)
+	return err == nil
+}
@@ -0,0 +1,51 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBackfillMissedTasksBoundsRange(t *testing.T) {
+	client := &fakeServiceManagerClient{blockNumber: 5000}
+	agg := newTestAggregator(t, newTestAvsReader(t, client, nil), newFakeBlsAggregationService())
+	agg.AggregatorConfig.Aggregator.MaxBackfillBlocks = 1000
+	if err := agg.taskStore.SetLastProcessedBlock(10); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := agg.backfillMissedTasks(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(client.filterQueries) == 0 || client.filterQueries[0].FromBlock.Uint64() != 4000 {
+		t.Fatalf("expected the backfill to start %d blocks back, got queries %v", 1000, client.filterQueries)
+	}
+	lastQuery := client.filterQueries[len(client.filterQueries)-1]
+	if lastQuery.ToBlock.Uint64() != 5000 {
+		t.Errorf("expected the backfill to end at the current block, got %d", lastQuery.ToBlock.Uint64())
+	}
+	lastProcessedBlock, ok, err := agg.taskStore.GetLastProcessedBlock()
+	if err != nil || !ok || lastProcessedBlock != 5000 {
+		t.Errorf("expected the last processed block to be the current one, got %d", lastProcessedBlock)
+	}
+}
+
+func TestBackfillMissedTasksStopsWithContext(t *testing.T) {
+	client := &fakeServiceManagerClient{blockNumber: 5000}
+	agg := newTestAggregator(t, newTestAvsReader(t, client, nil), newFakeBlsAggregationService())
+	agg.AggregatorConfig.Aggregator.MaxBackfillBlocks = 1000
+	if err := agg.taskStore.SetLastProcessedBlock(4500); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := agg.backfillMissedTasks(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the backfill to stop with its context, got %v", err)
+	}
+	lastProcessedBlock, _, err := agg.taskStore.GetLastProcessedBlock()
+	if err != nil || lastProcessedBlock != 4500 {
+		t.Errorf("expected the last processed block to stay, got %d", lastProcessedBlock)
+	}
+}
@@ -0,0 +1,187 @@
+package pkg
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/Layr-Labs/eigensdk-go/signer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/metrics"
+)
+
+// responseWallet is a wallet that responds to tasks, with its own transaction manager
+// so its nonces don't depend on the other wallets
+type responseWallet struct {
+	txManager *chainio.TxManager
+	address   common.Address
+	// Responses being sent with the wallet
+	pending int
+	// Underfunded wallets are not assigned responses
+	funded  bool
+	balance *big.Int
+}
+
+// walletPool assigns the aggregated responses to the aggregator wallets, so responses
+// to different tasks are sent in parallel instead of waiting for each other's receipts
+type walletPool struct {
+	wallets    []*responseWallet
+	assignment string
+	// Index the assignment starts checking from, the one after the last wallet used
+	nextWallet int
+	// Protects the state of the wallets
+	mutex sync.Mutex
+
+	client     eth.Client
+	minBalance *big.Int
+	logger     logging.Logger
+	metrics    *metrics.Metrics
+}
+
+// newWalletPool creates a wallet for each signer, ignoring repeated addresses
+func newWalletPool(client eth.Client, signers []signer.Signer, txManagerConfig chainio.TxManagerConfig, assignment string, minBalance *big.Int, logger logging.Logger, metrics *metrics.Metrics) *walletPool {
+	wallets := make([]*responseWallet, 0, len(signers))
+	addresses := make(map[common.Address]struct{})
+	for _, walletSigner := range signers {
+		txManager := chainio.NewTxManager(client, walletSigner, txManagerConfig, logger)
+		address := txManager.Address()
+		if _, ok := addresses[address]; ok {
+			logger.Warn("Response wallet is repeated, using it once", "address", address.Hex())
+			continue
+		}
+		addresses[address] = struct{}{}
+
+		// Wallets are funded until their balance is checked
+		wallets = append(wallets, &responseWallet{
+			txManager: txManager,
+			address:   address,
+			funded:    true,
+			balance:   new(big.Int),
+		})
+	}
+
+	return &walletPool{
+		wallets:    wallets,
+		assignment: assignment,
+		client:     client,
+		minBalance: minBalance,
+		logger:     logger,
+		metrics:    metrics,
+	}
+}
+
+// acquire assigns a funded wallet to a response, it must be released once the response is sent.
+// If every wallet is underfunded the one with the highest balance is used, so responses are still tried.
+func (p *walletPool) acquire() *responseWallet {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// Wallets are checked starting after the last one used, so ties are assigned in turns
+	var chosen *responseWallet
+	start := p.nextWallet
+	for i := range p.wallets {
+		index := (start + i) % len(p.wallets)
+		wallet := p.wallets[index]
+		if !wallet.funded {
+			continue
+		}
+		if chosen == nil || wallet.pending < chosen.pending {
+			chosen = wallet
+			p.nextWallet = index + 1
+		}
+		if p.assignment == config.WalletAssignmentRoundRobin {
+			break
+		}
+	}
+
+	if chosen == nil {
+		chosen = p.wallets[0]
+		for _, wallet := range p.wallets[1:] {
+			if wallet.balance.Cmp(chosen.balance) > 0 {
+				chosen = wallet
+			}
+		}
+		p.logger.Warn("Every response wallet is underfunded, using the one with the highest balance", "address", chosen.address.Hex())
+	}
+
+	chosen.pending++
+	return chosen
+}
+
+// release finishes a response sent with the wallet. If it failed for lack of funds,
+// the wallet is not used until the balance monitor finds it funded again.
+func (p *walletPool) release(wallet *responseWallet, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	wallet.pending--
+	if err != nil && strings.Contains(err.Error(), "insufficient funds") && wallet.funded {
+		p.logger.Warn("Response wallet has insufficient funds, removed from rotation", "address", wallet.address.Hex())
+		wallet.funded = false
+		p.metrics.SetAggregatorFundedWallets(p.fundedWallets())
+	}
+}
+
+// monitorBalances checks the balance of the wallets every interval until ctx is done
+func (p *walletPool) monitorBalances(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.checkBalances(ctx)
+		}
+	}
+}
+
+// checkBalances removes from rotation the wallets under the min balance, and adds back the ones funded again
+func (p *walletPool) checkBalances(ctx context.Context) {
+	for _, wallet := range p.wallets {
+		balance, err := p.client.BalanceAt(ctx, wallet.address, nil)
+		if err != nil {
+			p.logger.Warn("Could not check response wallet balance", "address", wallet.address.Hex(), "err", err)
+			continue
+		}
+		balanceEth, _ := new(big.Float).Quo(new(big.Float).SetInt(balance), big.NewFloat(params.Ether)).Float64()
+		p.metrics.SetAggregatorWalletBalance(wallet.address.Hex(), balanceEth)
+
+		funded := balance.Sign() > 0 && balance.Cmp(p.minBalance) >= 0
+
+		p.mutex.Lock()
+		if funded != wallet.funded {
+			if funded {
+				p.logger.Info("Response wallet funded, added back to rotation", "address", wallet.address.Hex(), "balance", balance)
+			} else {
+				p.logger.Warn("Response wallet underfunded, removed from rotation", "address", wallet.address.Hex(), "balance", balance)
+			}
+		}
+		wallet.funded = funded
+		wallet.balance = balance
+		p.mutex.Unlock()
+	}
+
+	p.mutex.Lock()
+	p.metrics.SetAggregatorFundedWallets(p.fundedWallets())
+	p.mutex.Unlock()
+}
+
+// fundedWallets must be called with the mutex held
+func (p *walletPool) fundedWallets() int {
+	count := 0
+	for _, wallet := range p.wallets {
+		if wallet.funded {
+			count++
+		}
+	}
+	return count
+}
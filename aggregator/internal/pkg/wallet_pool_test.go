@@ -0,0 +1,150 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/Layr-Labs/eigensdk-go/signer"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/metrics"
+)
+
+// fakeSigner only has the address of the wallet
+type fakeSigner struct {
+	signer.Signer
+	address gethcommon.Address
+}
+
+func (s *fakeSigner) GetTxOpts() *bind.TransactOpts {
+	return &bind.TransactOpts{From: s.address}
+}
+
+// fakeBalanceClient answers the balances of the wallets
+type fakeBalanceClient struct {
+	eth.Client
+	mutex    sync.Mutex
+	balances map[gethcommon.Address]*big.Int
+}
+
+func (c *fakeBalanceClient) BalanceAt(ctx context.Context, account gethcommon.Address, blockNumber *big.Int) (*big.Int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	balance, ok := c.balances[account]
+	if !ok {
+		return new(big.Int), nil
+	}
+	return balance, nil
+}
+
+func (c *fakeBalanceClient) setBalance(account gethcommon.Address, balance *big.Int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.balances[account] = balance
+}
+
+var testWalletAddresses = []gethcommon.Address{{1}, {2}, {3}}
+
+func newTestWalletPool(client eth.Client, assignment string) *walletPool {
+	signers := make([]signer.Signer, 0, len(testWalletAddresses))
+	for _, address := range testWalletAddresses {
+		signers = append(signers, &fakeSigner{address: address})
+	}
+	return newWalletPool(client, signers, chainio.TxManagerConfig{}, assignment, big.NewInt(100), logging.NewNoopLogger(),
+		metrics.NewMetrics("", prometheus.NewRegistry(), logging.NewNoopLogger()))
+}
+
+func TestWalletPoolRoundRobin(t *testing.T) {
+	pool := newTestWalletPool(nil, config.WalletAssignmentRoundRobin)
+
+	// Wallets are used in turns, even if the previous responses were not sent yet
+	for i := 0; i < 2*len(testWalletAddresses); i++ {
+		wallet := pool.acquire()
+		if expected := testWalletAddresses[i%len(testWalletAddresses)]; wallet.address != expected {
+			t.Fatalf("response %d: expected wallet %s, got %s", i, expected.Hex(), wallet.address.Hex())
+		}
+	}
+}
+
+func TestWalletPoolLeastPending(t *testing.T) {
+	pool := newTestWalletPool(nil, config.WalletAssignmentLeastPending)
+
+	first := pool.acquire()
+	second := pool.acquire()
+	third := pool.acquire()
+	if first == second || second == third || first == third {
+		t.Fatal("expected the responses to be assigned to different wallets")
+	}
+
+	// The wallet that finished its response is the one with the least pending responses
+	pool.release(second, nil)
+	if wallet := pool.acquire(); wallet != second {
+		t.Fatalf("expected wallet %s, got %s", second.address.Hex(), wallet.address.Hex())
+	}
+}
+
+func TestWalletPoolRemovesUnfundedWallet(t *testing.T) {
+	client := &fakeBalanceClient{balances: make(map[gethcommon.Address]*big.Int)}
+	for _, address := range testWalletAddresses {
+		client.setBalance(address, big.NewInt(1000))
+	}
+	pool := newTestWalletPool(client, config.WalletAssignmentRoundRobin)
+
+	unfunded := pool.acquire()
+	pool.release(unfunded, errors.New("insufficient funds for gas * price + value"))
+	for i := 0; i < 2*len(testWalletAddresses); i++ {
+		wallet := pool.acquire()
+		if wallet == unfunded {
+			t.Fatal("expected the wallet without funds to be removed from rotation")
+		}
+		pool.release(wallet, nil)
+	}
+
+	// Other errors keep the wallet in rotation
+	other := pool.acquire()
+	pool.release(other, errors.New("nonce too low"))
+	if !other.funded {
+		t.Fatal("expected a wallet failing for other reasons to stay in rotation")
+	}
+
+	// It is added back once the balance monitor finds it funded
+	pool.checkBalances(context.Background())
+	used := false
+	for i := 0; i < len(testWalletAddresses); i++ {
+		wallet := pool.acquire()
+		used = used || wallet == unfunded
+		pool.release(wallet, nil)
+	}
+	if !used {
+		t.Fatal("expected the funded wallet to be added back to rotation")
+	}
+
+	// And removed again if its balance drops under the min balance
+	client.setBalance(unfunded.address, big.NewInt(10))
+	pool.checkBalances(context.Background())
+	if unfunded.funded {
+		t.Fatal("expected the wallet under the min balance to be removed from rotation")
+	}
+}
+
+func TestWalletPoolUsesHighestBalanceWhenAllUnfunded(t *testing.T) {
+	client := &fakeBalanceClient{balances: map[gethcommon.Address]*big.Int{
+		testWalletAddresses[0]: big.NewInt(10),
+		testWalletAddresses[1]: big.NewInt(50),
+		testWalletAddresses[2]: big.NewInt(20),
+	}}
+	pool := newTestWalletPool(client, config.WalletAssignmentLeastPending)
+
+	pool.checkBalances(context.Background())
+	if wallet := pool.acquire(); wallet.address != testWalletAddresses[1] {
+		t.Fatalf("expected the wallet with the highest balance, got %s", wallet.address.Hex())
+	}
+}
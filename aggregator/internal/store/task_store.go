@@ -0,0 +1,264 @@
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"sync"
+	"time"
+
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+// Key prefixes used in the underlying key-value store
+var (
+	taskPrefix            = []byte("task-")
+	nextBatchIndexKey     = []byte("next-batch-index")
+	lastProcessedBlockKey = []byte("last-processed-block")
+)
+
+const (
+	levelDbCacheMb     = 16
+	levelDbFileHandles = 16
+)
+
+var ErrTaskNotFound = errors.New("task not found")
+
+// Task is the persisted state of a NewBatch task handled by the aggregator
+type Task struct {
+	BatchMerkleRoot  [32]byte
+	TaskCreatedBlock uint32
+	// Index used to communicate with the local BLS aggregation service
+	BatchIndex uint32
+	// Quorums the task is signed in, and the percentage of the stake of each one that must sign it.
+	// Empty for the tasks stored before they were persisted
+	QuorumNumbers              eigentypes.QuorumNums
+	QuorumThresholdPercentages eigentypes.QuorumThresholdPercentages
+	// Operator signatures accepted by the BLS aggregation service for this task
+	Signatures []types.SignedTaskResponse
+	// When the task was initialized in the BLS aggregation service, which expires it after the task response window.
+	// Zero for the tasks stored before it was persisted
+	InitializedAt time.Time
+	// Hash of the last respondToTask transaction sent for this task, if any
+	ResponseTxHash *common.Hash
+	Responded      bool
+	// The BLS aggregation service stopped collecting signatures for this task without reaching the threshold
+	Expired bool
+}
+
+// TaskStore persists the aggregator tasks so that batches whose signatures
+// are still being collected survive a restart or a redeploy.
+// Any ethdb.KeyValueStore can be used as backend.
+type TaskStore struct {
+	db ethdb.KeyValueStore
+	// Protects read-modify-write updates of a task
+	mutex *sync.Mutex
+}
+
+func NewTaskStore(db ethdb.KeyValueStore) *TaskStore {
+	return &TaskStore{
+		db:    db,
+		mutex: &sync.Mutex{},
+	}
+}
+
+// NewLevelDbTaskStore creates a TaskStore backed by an embedded on-disk
+// LevelDB database in the given directory
+func NewLevelDbTaskStore(path string) (*TaskStore, error) {
+	db, err := leveldb.New(path, levelDbCacheMb, levelDbFileHandles, "aggregator/tasks/", false)
+	if err != nil {
+		return nil, err
+	}
+	return NewTaskStore(db), nil
+}
+
+// NewInMemoryTaskStore creates a TaskStore that is lost when the process exits.
+// Meant to be used in tests
+func NewInMemoryTaskStore() *TaskStore {
+	return NewTaskStore(memorydb.New())
+}
+
+func (s *TaskStore) Close() error {
+	return s.db.Close()
+}
+
+// SaveTask stores the given task, overwriting any previous task with the same merkle root
+func (s *TaskStore) SaveTask(task *Task) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.putTask(task)
+}
+
+func (s *TaskStore) GetTask(batchMerkleRoot [32]byte) (*Task, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.getTask(batchMerkleRoot)
+}
+
+// AddSignature appends an operator signature to the task, ignoring signatures
+// from operators that already signed it
+func (s *TaskStore) AddSignature(batchMerkleRoot [32]byte, signedTaskResponse types.SignedTaskResponse) error {
+	return s.updateTask(batchMerkleRoot, func(task *Task) {
+		for _, signature := range task.Signatures {
+			if signature.OperatorId == signedTaskResponse.OperatorId {
+				return
+			}
+		}
+		task.Signatures = append(task.Signatures, signedTaskResponse)
+	})
+}
+
+func (s *TaskStore) SetResponseTxHash(batchMerkleRoot [32]byte, txHash common.Hash) error {
+	return s.updateTask(batchMerkleRoot, func(task *Task) {
+		task.ResponseTxHash = &txHash
+	})
+}
+
+func (s *TaskStore) MarkResponded(batchMerkleRoot [32]byte) error {
+	return s.updateTask(batchMerkleRoot, func(task *Task) {
+		task.Responded = true
+	})
+}
+
+func (s *TaskStore) MarkExpired(batchMerkleRoot [32]byte) error {
+	return s.updateTask(batchMerkleRoot, func(task *Task) {
+		task.Expired = true
+	})
+}
+
+// UnfinishedTasks returns all the tasks that were neither responded nor expired
+func (s *TaskStore) UnfinishedTasks() ([]*Task, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	it := s.db.NewIterator(taskPrefix, nil)
+	defer it.Release()
+
+	tasks := make([]*Task, 0)
+	for it.Next() {
+		task, err := decodeTask(it.Value())
+		if err != nil {
+			return nil, err
+		}
+		if !task.Responded && !task.Expired {
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks, it.Error()
+}
+
+// GetNextBatchIndex returns the next index to use with the BLS aggregation service.
+// It returns 0 if it was never stored.
+func (s *TaskStore) GetNextBatchIndex() (uint32, error) {
+	has, err := s.db.Has(nextBatchIndexKey)
+	if err != nil || !has {
+		return 0, err
+	}
+
+	value, err := s.db.Get(nextBatchIndexKey)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(value), nil
+}
+
+func (s *TaskStore) SetNextBatchIndex(nextBatchIndex uint32) error {
+	value := make([]byte, 4)
+	binary.BigEndian.PutUint32(value, nextBatchIndex)
+	return s.db.Put(nextBatchIndexKey, value)
+}
+
+// GetLastProcessedBlock returns the last block whose NewBatch events were processed.
+// The second return value is false if no block was processed yet.
+func (s *TaskStore) GetLastProcessedBlock() (uint64, bool, error) {
+	has, err := s.db.Has(lastProcessedBlockKey)
+	if err != nil || !has {
+		return 0, false, err
+	}
+
+	value, err := s.db.Get(lastProcessedBlockKey)
+	if err != nil {
+		return 0, false, err
+	}
+	return binary.BigEndian.Uint64(value), true, nil
+}
+
+// SetLastProcessedBlock stores the given block as the last processed one.
+// Blocks older than the one already stored are ignored.
+func (s *TaskStore) SetLastProcessedBlock(block uint64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	lastProcessedBlock, ok, err := s.GetLastProcessedBlock()
+	if err != nil {
+		return err
+	}
+	if ok && lastProcessedBlock >= block {
+		return nil
+	}
+
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, block)
+	return s.db.Put(lastProcessedBlockKey, value)
+}
+
+func (s *TaskStore) updateTask(batchMerkleRoot [32]byte, update func(task *Task)) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	task, err := s.getTask(batchMerkleRoot)
+	if err != nil {
+		return err
+	}
+	update(task)
+
+	return s.putTask(task)
+}
+
+func (s *TaskStore) getTask(batchMerkleRoot [32]byte) (*Task, error) {
+	key := taskKey(batchMerkleRoot)
+	has, err := s.db.Has(key)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrTaskNotFound
+	}
+
+	value, err := s.db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return decodeTask(value)
+}
+
+func (s *TaskStore) putTask(task *Task) error {
+	var buffer bytes.Buffer
+	err := gob.NewEncoder(&buffer).Encode(task)
+	if err != nil {
+		return err
+	}
+	return s.db.Put(taskKey(task.BatchMerkleRoot), buffer.Bytes())
+}
+
+func decodeTask(value []byte) (*Task, error) {
+	var task Task
+	err := gob.NewDecoder(bytes.NewReader(value)).Decode(&task)
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func taskKey(batchMerkleRoot [32]byte) []byte {
+	return append(append([]byte{}, taskPrefix...), batchMerkleRoot[:]...)
+}
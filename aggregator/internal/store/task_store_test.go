@@ -0,0 +1,106 @@
+package store_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yetanotherco/aligned_layer/aggregator/internal/store"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+func TestTaskStoreKeepsUnfinishedTasks(t *testing.T) {
+	taskStore := store.NewInMemoryTaskStore()
+	defer taskStore.Close()
+
+	finishedRoot := [32]byte{1}
+	unfinishedRoot := [32]byte{2}
+
+	for i, root := range [][32]byte{finishedRoot, unfinishedRoot} {
+		err := taskStore.SaveTask(&store.Task{
+			BatchMerkleRoot:            root,
+			TaskCreatedBlock:           10,
+			BatchIndex:                 uint32(i),
+			QuorumNumbers:              eigentypes.QuorumNums{0, 1},
+			QuorumThresholdPercentages: eigentypes.QuorumThresholdPercentages{67, 67},
+		})
+		if err != nil {
+			t.Fatalf("could not save task: %v", err)
+		}
+	}
+
+	keyPair, err := bls.GenRandomBlsKeys()
+	if err != nil {
+		t.Fatalf("could not generate bls keys: %v", err)
+	}
+	signedTaskResponse := types.SignedTaskResponse{
+		BatchMerkleRoot: unfinishedRoot,
+		BlsSignature:    *keyPair.SignMessage(unfinishedRoot),
+		OperatorId:      eigentypes.OperatorIdFromKeyPair(keyPair),
+	}
+
+	// The second signature from the same operator must be ignored
+	for i := 0; i < 2; i++ {
+		if err := taskStore.AddSignature(unfinishedRoot, signedTaskResponse); err != nil {
+			t.Fatalf("could not add signature: %v", err)
+		}
+	}
+
+	if err := taskStore.SetResponseTxHash(finishedRoot, common.Hash{3}); err != nil {
+		t.Fatalf("could not set response tx hash: %v", err)
+	}
+	if err := taskStore.MarkResponded(finishedRoot); err != nil {
+		t.Fatalf("could not mark task as responded: %v", err)
+	}
+
+	tasks, err := taskStore.UnfinishedTasks()
+	if err != nil {
+		t.Fatalf("could not get unfinished tasks: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].BatchMerkleRoot != unfinishedRoot {
+		t.Fatalf("expected only the unfinished task, got %+v", tasks)
+	}
+	if len(tasks[0].QuorumNumbers) != 2 || tasks[0].QuorumNumbers[1] != 1 || tasks[0].QuorumThresholdPercentages[1] != 67 {
+		t.Fatalf("expected the quorums of the task, got %v %v", tasks[0].QuorumNumbers, tasks[0].QuorumThresholdPercentages)
+	}
+	if len(tasks[0].Signatures) != 1 {
+		t.Fatalf("expected 1 signature, got %d", len(tasks[0].Signatures))
+	}
+	verified, err := tasks[0].Signatures[0].BlsSignature.Verify(keyPair.GetPubKeyG2(), unfinishedRoot)
+	if err != nil || !verified {
+		t.Errorf("stored signature does not verify")
+	}
+
+	finishedTask, err := taskStore.GetTask(finishedRoot)
+	if err != nil {
+		t.Fatalf("could not get task: %v", err)
+	}
+	if !finishedTask.Responded || *finishedTask.ResponseTxHash != (common.Hash{3}) {
+		t.Errorf("unexpected finished task state: %+v", finishedTask)
+	}
+
+	if _, err := taskStore.GetTask([32]byte{4}); !errors.Is(err, store.ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func TestTaskStoreNextBatchIndex(t *testing.T) {
+	taskStore := store.NewInMemoryTaskStore()
+	defer taskStore.Close()
+
+	nextBatchIndex, err := taskStore.GetNextBatchIndex()
+	if err != nil || nextBatchIndex != 0 {
+		t.Fatalf("expected next batch index 0, got %d (err: %v)", nextBatchIndex, err)
+	}
+
+	if err := taskStore.SetNextBatchIndex(42); err != nil {
+		t.Fatalf("could not set next batch index: %v", err)
+	}
+
+	nextBatchIndex, err = taskStore.GetNextBatchIndex()
+	if err != nil || nextBatchIndex != 42 {
+		t.Fatalf("expected next batch index 42, got %d (err: %v)", nextBatchIndex, err)
+	}
+}
@@ -0,0 +1,126 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// BatchFormat is the serialization format of a batch. JSON batches are arrays
+// of verification data. Binary batches start with BinaryBatchMagic and the
+// format version, followed by a CBOR sequence (RFC 8742) of verification data.
+// JSON batches start with '[', so both formats can be told apart by their prefix.
+type BatchFormat uint8
+
+const (
+	BatchFormatJson BatchFormat = iota
+	BatchFormatBinary
+)
+
+// BinaryBatchVersion is the version of the binary batch format written by EncodeBatch
+const BinaryBatchVersion byte = 1
+
+var BinaryBatchMagic = []byte("ALB")
+
+var (
+	ErrUnsupportedBatchFormat  = errors.New("unsupported batch format")
+	ErrUnsupportedBatchVersion = errors.New("unsupported binary batch version")
+)
+
+// CBOR nil byte strings are encoded as null, so missing fields are kept
+// missing, which matters for the merkle tree leaves
+var (
+	cborEncMode cbor.EncMode
+	cborDecMode cbor.DecMode
+)
+
+func init() {
+	var err error
+	cborEncMode, err = cbor.CoreDetEncOptions().EncMode()
+	if err != nil {
+		panic(err)
+	}
+	cborDecMode, err = cbor.DecOptions{}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+}
+
+func ParseBatchFormat(batchFormat string) (BatchFormat, error) {
+	switch batchFormat {
+	case "json":
+		return BatchFormatJson, nil
+	case "binary":
+		return BatchFormatBinary, nil
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedBatchFormat, batchFormat)
+	}
+}
+
+// DetectBatchFormat returns the format of a batch from its first bytes
+func DetectBatchFormat(prefix []byte) BatchFormat {
+	if bytes.HasPrefix(prefix, BinaryBatchMagic) {
+		return BatchFormatBinary
+	}
+	return BatchFormatJson
+}
+
+func EncodeBatch(writer io.Writer, batch []VerificationData, format BatchFormat) error {
+	switch format {
+	case BatchFormatJson:
+		return json.NewEncoder(writer).Encode(batch)
+	case BatchFormatBinary:
+		if _, err := writer.Write(append(append([]byte{}, BinaryBatchMagic...), BinaryBatchVersion)); err != nil {
+			return err
+		}
+		encoder := cborEncMode.NewEncoder(writer)
+		for _, verificationData := range batch {
+			if err := encoder.Encode(verificationData); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %d", ErrUnsupportedBatchFormat, format)
+	}
+}
+
+func MarshalBatch(batch []VerificationData, format BatchFormat) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := EncodeBatch(&buffer, batch, format); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// BinaryBatchDecoder decodes a binary batch one entry at a time
+type BinaryBatchDecoder struct {
+	decoder *cbor.Decoder
+}
+
+// NewBinaryBatchDecoder reads and checks the header of a binary batch
+func NewBinaryBatchDecoder(reader io.Reader) (*BinaryBatchDecoder, error) {
+	header := make([]byte, len(BinaryBatchMagic)+1)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:len(BinaryBatchMagic)], BinaryBatchMagic) {
+		return nil, fmt.Errorf("%w: missing binary batch magic", ErrUnsupportedBatchFormat)
+	}
+	if version := header[len(BinaryBatchMagic)]; version != BinaryBatchVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedBatchVersion, version)
+	}
+
+	return &BinaryBatchDecoder{decoder: cborDecMode.NewDecoder(reader)}, nil
+}
+
+// Next returns the next entry of the batch, or io.EOF after the last one
+func (d *BinaryBatchDecoder) Next() (VerificationData, error) {
+	var verificationData VerificationData
+	err := d.decoder.Decode(&verificationData)
+	return verificationData, err
+}
@@ -0,0 +1,87 @@
+package common_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/yetanotherco/aligned_layer/common"
+	"github.com/yetanotherco/aligned_layer/merkle"
+)
+
+func TestBinaryBatchRoundTripKeepsMerkleRoot(t *testing.T) {
+	batch := []common.VerificationData{
+		{
+			ProvingSystemId:       common.SP1,
+			Proof:                 []byte{1, 2, 3},
+			VmProgramCode:         []byte{4, 5},
+			ProofGeneratorAddress: ethcommon.HexToAddress("0x66f9664f97F2b50F62D13eA064982f936dE76657"),
+		},
+		{
+			ProvingSystemId: common.GnarkPlonkBn254,
+			Proof:           []byte{6},
+			// Empty and missing fields have different commitments
+			PubInput:        []byte{},
+			VerificationKey: []byte{7, 8, 9},
+		},
+	}
+
+	encoded, err := common.MarshalBatch(batch, common.BatchFormatBinary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if common.DetectBatchFormat(encoded) != common.BatchFormatBinary {
+		t.Fatal("binary batch not detected")
+	}
+
+	decoder, err := common.NewBinaryBatchDecoder(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := make([]common.VerificationData, 0)
+	for {
+		verificationData, err := decoder.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded = append(decoded, verificationData)
+	}
+
+	if len(decoded) != len(batch) {
+		t.Fatalf("expected %d entries, got %d", len(batch), len(decoded))
+	}
+	if decoded[0].PubInput != nil || decoded[1].PubInput == nil {
+		t.Errorf("missing and empty fields were not kept")
+	}
+
+	expectedRoot, err := merkle.BatchMerkleRoot(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := merkle.BatchMerkleRoot(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != expectedRoot {
+		t.Errorf("expected merkle root %x, got %x", expectedRoot, root)
+	}
+}
+
+func TestBinaryBatchRejectsUnknownVersion(t *testing.T) {
+	encoded := append(append([]byte{}, common.BinaryBatchMagic...), common.BinaryBatchVersion+1)
+	_, err := common.NewBinaryBatchDecoder(bytes.NewReader(encoded))
+	if !errors.Is(err, common.ErrUnsupportedBatchVersion) {
+		t.Errorf("expected unsupported version, got %v", err)
+	}
+}
+
+func TestJsonBatchDetected(t *testing.T) {
+	if common.DetectBatchFormat([]byte(` [{"proof": []}]`)) != common.BatchFormatJson {
+		t.Error("JSON batch not detected")
+	}
+}
@@ -7,6 +7,8 @@ import (
 
 type ProvingSystemId uint16
 
+// Ids of the proving systems supported out of the box. Their verifiers are
+// registered by the packages implementing them, see RegisterVerifier.
 const (
 	GnarkPlonkBls12_381 ProvingSystemId = iota
 	GnarkPlonkBn254
@@ -14,48 +16,44 @@ const (
 	SP1
 	Halo2KZG
 	Halo2IPA
+	RiscZero
 )
 
+// Names of the proving systems in the JSON batch format. They don't depend on the
+// registered verifiers, so batches are encoded the same by every binary.
+var provingSystemNames = map[ProvingSystemId]string{
+	GnarkPlonkBls12_381: "GnarkPlonkBls12_381",
+	GnarkPlonkBn254:     "GnarkPlonkBn254",
+	Groth16Bn254:        "Groth16Bn254",
+	SP1:                 "SP1",
+	Halo2KZG:            "Halo2KZG",
+	Halo2IPA:            "Halo2IPA",
+	RiscZero:            "RiscZero",
+}
+
 func (t *ProvingSystemId) String() string {
-	return [...]string{"GnarkPlonkBls12_381", "GnarkPlonkBn254", "Groth16Bn254", "SP1", "Halo2IPA"}[*t]
+	str, err := ProvingSystemIdToString(*t)
+	if err != nil {
+		return fmt.Sprintf("ProvingSystemId(%d)", *t)
+	}
+	return str
 }
 
 func ProvingSystemIdFromString(provingSystem string) (ProvingSystemId, error) {
-	switch provingSystem {
-	case "GnarkPlonkBls12_381":
-		return GnarkPlonkBls12_381, nil
-	case "GnarkPlonkBn254":
-		return GnarkPlonkBn254, nil
-	case "Groth16Bn254":
-		return Groth16Bn254, nil
-	case "SP1":
-		return SP1, nil
-	case "Halo2KZG":
-		return Halo2KZG, nil
-	case "Halo2IPA":
-		return Halo2IPA, nil
+	for id, name := range provingSystemNames {
+		if name == provingSystem {
+			return id, nil
+		}
 	}
-
 	return 0, fmt.Errorf("unknown proving system: %s", provingSystem)
 }
 
 func ProvingSystemIdToString(provingSystem ProvingSystemId) (string, error) {
-	switch provingSystem {
-	case GnarkPlonkBls12_381:
-		return "GnarkPlonkBls12_381", nil
-	case GnarkPlonkBn254:
-		return "GnarkPlonkBn254", nil
-	case Groth16Bn254:
-		return "Groth16Bn254", nil
-	case SP1:
-		return "SP1", nil
-	case Halo2KZG:
-		return "Halo2KZG", nil
-	case Halo2IPA:
-		return "Halo2IPA", nil
+	name, ok := provingSystemNames[provingSystem]
+	if !ok {
+		return "", fmt.Errorf("unknown proving system: %d", provingSystem)
 	}
-
-	return "", fmt.Errorf("unknown proving system: %d", provingSystem)
+	return name, nil
 }
 
 func (t *ProvingSystemId) UnmarshalJSON(b []byte) error {
@@ -0,0 +1,30 @@
+package common_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/yetanotherco/aligned_layer/common"
+)
+
+// No verifier is registered in this package, the names don't depend on them
+func TestProvingSystemIdJsonRoundTrip(t *testing.T) {
+	for id := common.GnarkPlonkBls12_381; id <= common.RiscZero; id++ {
+		encoded, err := json.Marshal(id)
+		if err != nil {
+			t.Fatalf("could not marshal proving system %d: %v", id, err)
+		}
+		var decoded common.ProvingSystemId
+		if err = json.Unmarshal(encoded, &decoded); err != nil || decoded != id {
+			t.Fatalf("proving system %d decoded from %s as %d, err %v", id, encoded, decoded, err)
+		}
+	}
+
+	if _, err := json.Marshal(common.ProvingSystemId(1000)); err == nil {
+		t.Error("expected an unknown proving system id to be rejected")
+	}
+	var decoded common.ProvingSystemId
+	if err := json.Unmarshal([]byte(`"Unknown"`), &decoded); err == nil {
+		t.Error("expected an unknown proving system name to be rejected")
+	}
+}
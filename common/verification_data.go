@@ -0,0 +1,16 @@
+package common
+
+import (
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// VerificationData is an entry of a batch. The cbor keys are used by the binary batch format.
+type VerificationData struct {
+	ProvingSystemId ProvingSystemId `json:"proving_system" cbor:"1,keyasint"`
+	Proof           []byte          `json:"proof" cbor:"2,keyasint"`
+	PubInput        []byte          `json:"pub_input" cbor:"3,keyasint"`
+	VerificationKey []byte          `json:"verification_key" cbor:"4,keyasint"`
+	VmProgramCode   []byte          `json:"vm_program_code" cbor:"5,keyasint"`
+	// Address of the proof sender, it is part of the batch merkle tree leaf
+	ProofGeneratorAddress ethcommon.Address `json:"proof_generator_addr" cbor:"6,keyasint"`
+}
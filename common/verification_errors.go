@@ -0,0 +1,63 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of verification failures. Verifiers wrap them so the operator can
+// tell why a proof was rejected.
+var (
+	ErrDeserialization = errors.New("could not deserialize verification data")
+	ErrSizeLimit       = errors.New("verification data exceeds size limit")
+	ErrInvalidProof    = errors.New("invalid proof")
+	ErrVerifierPanic   = errors.New("verifier panicked")
+	ErrTimeout         = errors.New("proof verification timed out")
+)
+
+// Reasons used in logs, metric labels and failure reports sent to the aggregator
+const (
+	ReasonDeserialization          = "deserialization"
+	ReasonSizeLimit                = "size_limit"
+	ReasonInvalidProof             = "invalid_proof"
+	ReasonUnsupportedProvingSystem = "unsupported_proving_system"
+	ReasonVerifierPanic            = "verifier_panic"
+	ReasonTimeout                  = "timeout"
+	ReasonUnknown                  = "unknown"
+)
+
+// VerificationError is the error returned when an entry of a batch fails to verify
+type VerificationError struct {
+	// Index of the failing entry in the batch
+	BatchIndex      int
+	ProvingSystemId ProvingSystemId
+	Err             error
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("verification of batch entry %d (%s) failed: %v", e.BatchIndex, e.ProvingSystemId.String(), e.Err)
+}
+
+func (e *VerificationError) Unwrap() error {
+	return e.Err
+}
+
+// Reason returns the kind of failure, one of the Reason constants
+func (e *VerificationError) Reason() string {
+	switch {
+	case errors.Is(e.Err, ErrUnsupportedProvingSystem):
+		return ReasonUnsupportedProvingSystem
+	case errors.Is(e.Err, ErrSizeLimit):
+		return ReasonSizeLimit
+	case errors.Is(e.Err, ErrDeserialization):
+		return ReasonDeserialization
+	case errors.Is(e.Err, ErrVerifierPanic):
+		return ReasonVerifierPanic
+	case errors.Is(e.Err, ErrTimeout):
+		return ReasonTimeout
+	case errors.Is(e.Err, ErrInvalidProof):
+		return ReasonInvalidProof
+	default:
+		return ReasonUnknown
+	}
+}
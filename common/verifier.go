@@ -0,0 +1,149 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var ErrUnsupportedProvingSystem = errors.New("unsupported proving system")
+
+// VerificationDataLimits are the max sizes in bytes accepted by a verifier for
+// each field of the verification data. Zero means there is no limit.
+type VerificationDataLimits struct {
+	MaxProofSize           int
+	MaxPubInputSize        int
+	MaxVerificationKeySize int
+	MaxVmProgramCodeSize   int
+}
+
+// Verifier verifies the proofs of a proving system. Verifiers are registered
+// with RegisterVerifier, usually from the init function of their own package.
+type Verifier interface {
+	ProvingSystemId() ProvingSystemId
+	// Name used in the JSON batch format, e.g. "GnarkPlonkBn254", which must be
+	// the one of the proving system id in ProvingSystemIdToString
+	Name() string
+	// Alias used in the CLI, e.g. "plonk_bn254"
+	Alias() string
+	Limits() VerificationDataLimits
+	// Verify returns false if the proof is invalid, and an error if the
+	// verification data could not be processed
+	Verify(ctx context.Context, verificationData VerificationData) (bool, error)
+}
+
+var (
+	verifiersMutex   sync.RWMutex
+	verifiersById    = make(map[ProvingSystemId]Verifier)
+	verifiersByName  = make(map[string]Verifier)
+	verifiersByAlias = make(map[string]Verifier)
+)
+
+// RegisterVerifier makes a verifier available for its proving system. It panics if the name
+// is not the one of the proving system id, or if the id or alias is already registered.
+func RegisterVerifier(verifier Verifier) {
+	verifiersMutex.Lock()
+	defer verifiersMutex.Unlock()
+
+	if name, err := ProvingSystemIdToString(verifier.ProvingSystemId()); err != nil || name != verifier.Name() {
+		panic(fmt.Sprintf("verifier name %s is not the name of proving system id %d", verifier.Name(), verifier.ProvingSystemId()))
+	}
+
+	if _, ok := verifiersById[verifier.ProvingSystemId()]; ok {
+		panic(fmt.Sprintf("verifier already registered for proving system id %d", verifier.ProvingSystemId()))
+	}
+	if _, ok := verifiersByName[verifier.Name()]; ok {
+		panic(fmt.Sprintf("verifier already registered for proving system %s", verifier.Name()))
+	}
+	if _, ok := verifiersByAlias[verifier.Alias()]; ok {
+		panic(fmt.Sprintf("verifier already registered for proving system alias %s", verifier.Alias()))
+	}
+
+	verifiersById[verifier.ProvingSystemId()] = verifier
+	verifiersByName[verifier.Name()] = verifier
+	verifiersByAlias[verifier.Alias()] = verifier
+}
+
+func GetVerifier(provingSystemId ProvingSystemId) (Verifier, error) {
+	verifiersMutex.RLock()
+	defer verifiersMutex.RUnlock()
+
+	verifier, ok := verifiersById[provingSystemId]
+	if !ok {
+		return nil, fmt.Errorf("%w: id %d", ErrUnsupportedProvingSystem, provingSystemId)
+	}
+	return verifier, nil
+}
+
+func GetVerifierByName(name string) (Verifier, error) {
+	verifiersMutex.RLock()
+	defer verifiersMutex.RUnlock()
+
+	verifier, ok := verifiersByName[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedProvingSystem, name)
+	}
+	return verifier, nil
+}
+
+func GetVerifierByAlias(alias string) (Verifier, error) {
+	verifiersMutex.RLock()
+	defer verifiersMutex.RUnlock()
+
+	verifier, ok := verifiersByAlias[alias]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s, available proving systems are: [%s]",
+			ErrUnsupportedProvingSystem, alias, strings.Join(verifierAliases(), ", "))
+	}
+	return verifier, nil
+}
+
+// Verifiers returns all the registered verifiers sorted by proving system id
+func Verifiers() []Verifier {
+	verifiersMutex.RLock()
+	defer verifiersMutex.RUnlock()
+
+	verifiers := make([]Verifier, 0, len(verifiersById))
+	for _, verifier := range verifiersById {
+		verifiers = append(verifiers, verifier)
+	}
+	sort.Slice(verifiers, func(i, j int) bool {
+		return verifiers[i].ProvingSystemId() < verifiers[j].ProvingSystemId()
+	})
+	return verifiers
+}
+
+// CheckLimits returns an error if any field of the verification data exceeds the verifier limits
+func CheckLimits(verifier Verifier, verificationData VerificationData) error {
+	limits := verifier.Limits()
+	checks := []struct {
+		field   string
+		size    int
+		maxSize int
+	}{
+		{"proof", len(verificationData.Proof), limits.MaxProofSize},
+		{"public input", len(verificationData.PubInput), limits.MaxPubInputSize},
+		{"verification key", len(verificationData.VerificationKey), limits.MaxVerificationKeySize},
+		{"vm program code", len(verificationData.VmProgramCode), limits.MaxVmProgramCodeSize},
+	}
+
+	for _, check := range checks {
+		if check.maxSize != 0 && check.size > check.maxSize {
+			return fmt.Errorf("%w: %s %s size %d exceeds max size %d", ErrSizeLimit, verifier.Name(), check.field, check.size, check.maxSize)
+		}
+	}
+	return nil
+}
+
+// verifierAliases must be called holding verifiersMutex
+func verifierAliases() []string {
+	aliases := make([]string, 0, len(verifiersByAlias))
+	for alias := range verifiersByAlias {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
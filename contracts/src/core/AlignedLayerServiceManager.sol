@@ -21,7 +21,7 @@ contract AlignedLayerServiceManager is
     AlignedLayerServiceManagerStorage
 {
     uint256 internal constant THRESHOLD_DENOMINATOR = 100;
-    uint8 internal constant QUORUM_THRESHOLD_PERCENTAGE = 67;
+    uint8 public constant QUORUM_THRESHOLD_PERCENTAGE = 67;
 
     // EVENTS
     event NewBatch(
@@ -1,40 +1,49 @@
 package chainio
 
 import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	gethcommon "github.com/ethereum/go-ethereum/common"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
 	contractERC20Mock "github.com/yetanotherco/aligned_layer/contracts/bindings/ERC20Mock"
 	"github.com/yetanotherco/aligned_layer/core/config"
 
-	"github.com/Layr-Labs/eigensdk-go/chainio/clients"
 	sdkavsregistry "github.com/Layr-Labs/eigensdk-go/chainio/clients/avsregistry"
 	"github.com/Layr-Labs/eigensdk-go/logging"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
 )
 
+// ServiceManagerQuorumNumbers are the quorums the service manager checks the signatures of a
+// response in. The checkSignatures of the middleware fork takes no quorum numbers, and
+// respondToTask only checks the stake of quorum 0, which the contract doesn't expose, so it
+// can't be read from chain. Signing in the other quorums of the registry coordinator would
+// make tasks wait for a threshold the contract doesn't check. Keep in sync with respondToTask
+var ServiceManagerQuorumNumbers = eigentypes.QuorumNums{0}
+
+// Max amount of blocks queried in a single eth_getLogs call, since
+// most RPC providers reject requests over big ranges
+const maxFilterBlockRange = 10_000
+
 type AvsReader struct {
 	sdkavsregistry.AvsRegistryReader
 	AvsContractBindings *AvsServiceBindings
 	logger              logging.Logger
 }
 
-func NewAvsReaderFromConfig(baseConfig *config.BaseConfig, ecdsaConfig *config.EcdsaConfig) (*AvsReader, error) {
-
-	buildAllConfig := clients.BuildAllConfig{
-		EthHttpUrl:                 baseConfig.EthRpcUrl,
-		EthWsUrl:                   baseConfig.EthWsUrl,
-		RegistryCoordinatorAddr:    baseConfig.AlignedLayerDeploymentConfig.AlignedLayerRegistryCoordinatorAddr.String(),
-		OperatorStateRetrieverAddr: baseConfig.AlignedLayerDeploymentConfig.AlignedLayerOperatorStateRetrieverAddr.String(),
-		AvsName:                    "AlignedLayer",
-		PromMetricsIpPortAddress:   baseConfig.EigenMetricsIpPortAddress,
-	}
-
-	clients, err := clients.BuildAll(buildAllConfig, ecdsaConfig.PrivateKey, baseConfig.Logger)
+func NewAvsReaderFromConfig(baseConfig *config.BaseConfig) (*AvsReader, error) {
+	avsRegistryReader, err := sdkavsregistry.BuildAvsRegistryChainReader(
+		baseConfig.AlignedLayerDeploymentConfig.AlignedLayerRegistryCoordinatorAddr,
+		baseConfig.AlignedLayerDeploymentConfig.AlignedLayerOperatorStateRetrieverAddr,
+		baseConfig.EthRpcClient, baseConfig.Logger)
 	if err != nil {
 		return nil, err
 	}
 
-	avsRegistryReader := clients.AvsRegistryChainReader
-
 	avsServiceBindings, err := NewAvsServiceBindings(baseConfig.AlignedLayerDeploymentConfig.AlignedLayerServiceManagerAddr, baseConfig.AlignedLayerDeploymentConfig.AlignedLayerOperatorStateRetrieverAddr, baseConfig.EthRpcClient, baseConfig.Logger)
 	if err != nil {
 		return nil, err
@@ -59,3 +68,99 @@ func (r *AvsReader) GetErc20Mock(tokenAddr gethcommon.Address) (*contractERC20Mo
 func (r *AvsReader) IsOperatorRegistered(address gethcommon.Address) (bool, error) {
 	return r.AvsRegistryReader.IsOperatorRegistered(&bind.CallOpts{}, address)
 }
+
+// GetRegisteredOperatorAddress returns the address of the operator with the given id,
+// and false if no operator with that id is currently registered in the AVS
+func (r *AvsReader) GetRegisteredOperatorAddress(operatorId eigentypes.OperatorId) (gethcommon.Address, bool, error) {
+	address, err := r.AvsRegistryReader.GetOperatorFromId(&bind.CallOpts{}, operatorId)
+	if err != nil {
+		return gethcommon.Address{}, false, err
+	}
+	if address == (gethcommon.Address{}) {
+		return address, false, nil
+	}
+
+	registered, err := r.AvsRegistryReader.IsOperatorRegistered(&bind.CallOpts{}, address)
+	if err != nil {
+		return gethcommon.Address{}, false, err
+	}
+	return address, registered, nil
+}
+
+func (r *AvsReader) GetBlockNumber(ctx context.Context) (uint64, error) {
+	return r.AvsContractBindings.ethClient.BlockNumber(ctx)
+}
+
+// GetBlockTimestamp returns the timestamp of the given block
+func (r *AvsReader) GetBlockTimestamp(ctx context.Context, blockNumber uint64) (time.Time, error) {
+	header, err := r.AvsContractBindings.ethClient.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(int64(header.Time), 0), nil
+}
+
+// FilterNewBatches returns the NewBatch events emitted between fromBlock and toBlock, both inclusive
+func (r *AvsReader) FilterNewBatches(ctx context.Context, fromBlock uint64, toBlock uint64) ([]*servicemanager.ContractAlignedLayerServiceManagerNewBatch, error) {
+	newBatches := make([]*servicemanager.ContractAlignedLayerServiceManagerNewBatch, 0)
+
+	for start := fromBlock; start <= toBlock; start += maxFilterBlockRange {
+		end := min(start+maxFilterBlockRange-1, toBlock)
+
+		it, err := r.AvsContractBindings.ServiceManager.FilterNewBatch(&bind.FilterOpts{
+			Start:   start,
+			End:     &end,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for it.Next() {
+			newBatches = append(newBatches, it.Event)
+		}
+		err = it.Error()
+		_ = it.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newBatches, nil
+}
+
+// IsBatchResponded checks the batchesState of the service manager to know if
+// the batch was already responded
+func (r *AvsReader) IsBatchResponded(batchMerkleRoot [32]byte) (bool, error) {
+	batchState, err := r.AvsContractBindings.ServiceManager.BatchesState(&bind.CallOpts{}, batchMerkleRoot)
+	if err != nil {
+		return false, err
+	}
+	return batchState.Responded, nil
+}
+
+// GetQuorumNumbers returns the quorums the service manager checks the signatures of a response in,
+// failing if any of them is not created in the registry coordinator
+func (r *AvsReader) GetQuorumNumbers(ctx context.Context) (eigentypes.QuorumNums, error) {
+	quorumCount, err := r.AvsRegistryReader.GetQuorumCount(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, err
+	}
+	for _, quorumNumber := range ServiceManagerQuorumNumbers {
+		if uint8(quorumNumber) >= quorumCount {
+			return nil, fmt.Errorf("quorum %d is not created in the registry coordinator, which has %d quorums", quorumNumber, quorumCount)
+		}
+	}
+	return ServiceManagerQuorumNumbers, nil
+}
+
+// GetQuorumThresholdPercentage returns the percentage of the stake of each quorum that
+// must sign a batch for the service manager to accept its response
+func (r *AvsReader) GetQuorumThresholdPercentage(ctx context.Context) (eigentypes.QuorumThresholdPercentage, error) {
+	var out []interface{}
+	err := r.AvsContractBindings.quorumThresholdCaller.Call(&bind.CallOpts{Context: ctx}, &out, "QUORUM_THRESHOLD_PERCENTAGE")
+	if err != nil {
+		return 0, err
+	}
+	return eigentypes.QuorumThresholdPercentage(*abi.ConvertType(out[0], new(uint8)).(*uint8)), nil
+}
@@ -6,6 +6,8 @@ import (
 	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
 	"github.com/yetanotherco/aligned_layer/core/config"
 
+	sdkavsregistry "github.com/Layr-Labs/eigensdk-go/chainio/clients/avsregistry"
+	regcoord "github.com/Layr-Labs/eigensdk-go/contracts/bindings/RegistryCoordinator"
 	sdklogging "github.com/Layr-Labs/eigensdk-go/logging"
 )
 
@@ -42,6 +44,20 @@ func NewAvsSubscriberFromConfig(baseConfig *config.BaseConfig) (*AvsSubscriber,
 	}, nil
 }
 
+// NewAvsRegistrySubscriberFromConfig subscribes to the events of the BLS public key registry
+func NewAvsRegistrySubscriberFromConfig(baseConfig *config.BaseConfig) (*sdkavsregistry.AvsRegistryChainSubscriber, error) {
+	registryCoordinator, err := regcoord.NewContractRegistryCoordinator(
+		baseConfig.AlignedLayerDeploymentConfig.AlignedLayerRegistryCoordinatorAddr, baseConfig.EthRpcClient)
+	if err != nil {
+		return nil, err
+	}
+	blsApkRegistryAddr, err := registryCoordinator.BlsApkRegistry(&bind.CallOpts{})
+	if err != nil {
+		return nil, err
+	}
+	return sdkavsregistry.BuildAvsRegistryChainSubscriber(blsApkRegistryAddr, baseConfig.EthWsClient, baseConfig.Logger)
+}
+
 func (s *AvsSubscriber) SubscribeToNewTasks(newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatch) event.Subscription {
 	sub, err := s.AvsContractBindings.ServiceManager.WatchNewBatch(
 		&bind.WatchOpts{}, newTaskCreatedChan, nil,
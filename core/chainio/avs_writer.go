@@ -2,67 +2,166 @@ package chainio
 
 import (
 	"context"
-	"github.com/Layr-Labs/eigensdk-go/chainio/clients"
-	"github.com/Layr-Labs/eigensdk-go/chainio/clients/avsregistry"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
 	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	sdkutils "github.com/Layr-Labs/eigensdk-go/chainio/utils"
+	avsdirectory "github.com/Layr-Labs/eigensdk-go/contracts/bindings/AVSDirectory"
+	regcoord "github.com/Layr-Labs/eigensdk-go/contracts/bindings/RegistryCoordinator"
 	"github.com/Layr-Labs/eigensdk-go/logging"
-	"github.com/Layr-Labs/eigensdk-go/signer"
-	"github.com/ethereum/go-ethereum/common"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
 	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/signer"
 	"github.com/yetanotherco/aligned_layer/core/utils"
 )
 
+// Revert reason of respondToTask when the batch was already responded
+const batchAlreadyRespondedRevert = "Batch already responded"
+
+// ErrBatchAlreadyResponded is returned when the batch was responded by another transaction
+var ErrBatchAlreadyResponded = errors.New("batch already responded")
+
 type AvsWriter struct {
-	avsregistry.AvsRegistryWriter
 	AvsContractBindings *AvsServiceBindings
 	logger              logging.Logger
 	Signer              signer.Signer
 	Client              eth.Client
+	txManager           *TxManager
+	registryCoordinator *regcoord.ContractRegistryCoordinator
+	avsDirectory        *avsdirectory.ContractAVSDirectory
+	serviceManagerAddr  gethcommon.Address
 }
 
 func NewAvsWriterFromConfig(baseConfig *config.BaseConfig, ecdsaConfig *config.EcdsaConfig) (*AvsWriter, error) {
-
-	buildAllConfig := clients.BuildAllConfig{
-		EthHttpUrl:                 baseConfig.EthRpcUrl,
-		EthWsUrl:                   baseConfig.EthWsUrl,
-		RegistryCoordinatorAddr:    baseConfig.AlignedLayerDeploymentConfig.AlignedLayerRegistryCoordinatorAddr.String(),
-		OperatorStateRetrieverAddr: baseConfig.AlignedLayerDeploymentConfig.AlignedLayerOperatorStateRetrieverAddr.String(),
-		AvsName:                    "AlignedLayer",
-		PromMetricsIpPortAddress:   baseConfig.EigenMetricsIpPortAddress,
-	}
-
-	clients, err := clients.BuildAll(buildAllConfig, ecdsaConfig.PrivateKey, baseConfig.Logger)
+	avsServiceBindings, err := NewAvsServiceBindings(baseConfig.AlignedLayerDeploymentConfig.AlignedLayerServiceManagerAddr, baseConfig.AlignedLayerDeploymentConfig.AlignedLayerOperatorStateRetrieverAddr, baseConfig.EthRpcClient, baseConfig.Logger)
 
 	if err != nil {
-		baseConfig.Logger.Error("Cannot build signer config", "err", err)
+		baseConfig.Logger.Error("Cannot create avs service bindings", "err", err)
 		return nil, err
 	}
 
-	avsServiceBindings, err := NewAvsServiceBindings(baseConfig.AlignedLayerDeploymentConfig.AlignedLayerServiceManagerAddr, baseConfig.AlignedLayerDeploymentConfig.AlignedLayerOperatorStateRetrieverAddr, baseConfig.EthRpcClient, baseConfig.Logger)
-
+	registryCoordinator, err := regcoord.NewContractRegistryCoordinator(baseConfig.AlignedLayerDeploymentConfig.AlignedLayerRegistryCoordinatorAddr, baseConfig.EthRpcClient)
 	if err != nil {
-		baseConfig.Logger.Error("Cannot create avs service bindings", "err", err)
+		baseConfig.Logger.Error("Cannot create registry coordinator bindings", "err", err)
 		return nil, err
 	}
 
-	privateKeySigner, err := signer.NewPrivateKeySigner(ecdsaConfig.PrivateKey, baseConfig.ChainId)
+	avsDirectory, err := avsdirectory.NewContractAVSDirectory(baseConfig.EigenLayerDeploymentConfig.AVSDirectoryAddr, baseConfig.EthRpcClient)
 	if err != nil {
-		baseConfig.Logger.Error("Cannot create signer", "err", err)
+		baseConfig.Logger.Error("Cannot create avs directory bindings", "err", err)
 		return nil, err
 	}
 
-	avsRegistryWriter := clients.AvsRegistryChainWriter
-
 	return &AvsWriter{
-		AvsRegistryWriter:   avsRegistryWriter,
 		AvsContractBindings: avsServiceBindings,
 		logger:              baseConfig.Logger,
-		Signer:              privateKeySigner,
+		Signer:              ecdsaConfig.Signer,
 		Client:              baseConfig.EthRpcClient,
+		txManager:           NewTxManager(baseConfig.EthRpcClient, ecdsaConfig.Signer, DefaultTxManagerConfig(), baseConfig.Logger),
+		registryCoordinator: registryCoordinator,
+		avsDirectory:        avsDirectory,
+		serviceManagerAddr:  baseConfig.AlignedLayerDeploymentConfig.AlignedLayerServiceManagerAddr,
 	}, nil
 }
 
+// RegisterOperator registers the operator of the signer in the quorums with the key of the BLS signer.
+// It does the same as the eigensdk registry writer, but signs the operator to AVS registration
+// with the signer instead of requiring the private key, so the key can be held by a remote signer.
+func (w *AvsWriter) RegisterOperator(
+	ctx context.Context,
+	operatorToAvsRegistrationSigSalt [32]byte,
+	operatorToAvsRegistrationSigExpiry *big.Int,
+	blsSigner signer.BlsSigner,
+	quorumNumbers eigentypes.QuorumNums,
+	socket string,
+) (*types.Receipt, error) {
+	operatorAddr := w.Signer.Address()
+	w.logger.Info("Registering operator with the AVS registry coordinator", "operator", operatorAddr, "quorumNumbers", quorumNumbers)
+
+	// Params to register the BLS public key with the BLS apk registry
+	g1HashedMsgToSign, err := w.registryCoordinator.PubkeyRegistrationMessageHash(&bind.CallOpts{Context: ctx}, operatorAddr)
+	if err != nil {
+		return nil, err
+	}
+	pubkeyRegSignature, err := blsSigner.SignPubkeyRegistration(ctx, sdkutils.ConvertBn254GethToGnark(g1HashedMsgToSign))
+	if err != nil {
+		return nil, fmt.Errorf("could not sign pubkey registration: %w", err)
+	}
+	pubkeyRegParams := regcoord.IBLSApkRegistryPubkeyRegistrationParams{
+		PubkeyRegistrationSignature: sdkutils.ConvertToBN254G1Point(pubkeyRegSignature.G1Point),
+		PubkeyG1:                    sdkutils.ConvertToBN254G1Point(blsSigner.PubKeyG1()),
+		PubkeyG2:                    sdkutils.ConvertToBN254G2Point(blsSigner.PubKeyG2()),
+	}
+
+	// Params to register the operator in the operator to AVS mapping of the AVS directory
+	digestData, err := w.operatorAvsRegistrationDigestData(ctx, operatorAddr, operatorToAvsRegistrationSigSalt, operatorToAvsRegistrationSigExpiry)
+	if err != nil {
+		return nil, err
+	}
+	operatorSignature, err := w.Signer.SignData(ctx, digestData)
+	if err != nil {
+		return nil, fmt.Errorf("could not sign operator registration: %w", err)
+	}
+	// The AVS directory expects V as 27 or 28
+	operatorSignature[crypto.RecoveryIDOffset] += 27
+	operatorSignatureWithSaltAndExpiry := regcoord.ISignatureUtilsSignatureWithSaltAndExpiry{
+		Signature: operatorSignature,
+		Salt:      operatorToAvsRegistrationSigSalt,
+		Expiry:    operatorToAvsRegistrationSigExpiry,
+	}
+
+	receipt, err := w.txManager.Send(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return w.registryCoordinator.RegisterOperator(opts, quorumNumbers.UnderlyingType(), socket, pubkeyRegParams, operatorSignatureWithSaltAndExpiry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	w.logger.Info("Registered operator with the AVS registry coordinator", "txHash", receipt.TxHash.String(), "operator", operatorAddr, "quorumNumbers", quorumNumbers)
+	return receipt, nil
+}
+
+// operatorAvsRegistrationDigestData returns the EIP-712 encoded registration message, whose keccak256
+// hash is the digest the operator signs. Remote signers sign the hash of the data they are given,
+// so the data is built here and checked against the digest calculated by the AVS directory.
+func (w *AvsWriter) operatorAvsRegistrationDigestData(ctx context.Context, operatorAddr gethcommon.Address, salt [32]byte, expiry *big.Int) ([]byte, error) {
+	callOpts := &bind.CallOpts{Context: ctx}
+	typeHash, err := w.avsDirectory.OPERATORAVSREGISTRATIONTYPEHASH(callOpts)
+	if err != nil {
+		return nil, err
+	}
+	domainSeparator, err := w.avsDirectory.DomainSeparator(callOpts)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := w.avsDirectory.CalculateOperatorAVSRegistrationDigestHash(callOpts, operatorAddr, w.serviceManagerAddr, salt, expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	structHash := crypto.Keccak256(
+		typeHash[:],
+		gethcommon.LeftPadBytes(operatorAddr.Bytes(), 32),
+		gethcommon.LeftPadBytes(w.serviceManagerAddr.Bytes(), 32),
+		salt[:],
+		gethcommon.LeftPadBytes(expiry.Bytes(), 32),
+	)
+	digestData := append([]byte("\x19\x01"), domainSeparator[:]...)
+	digestData = append(digestData, structHash...)
+
+	if crypto.Keccak256Hash(digestData) != digest {
+		return nil, errors.New("operator registration digest does not match the one of the AVS directory")
+	}
+	return digestData, nil
+}
+
 func (w *AvsWriter) SendTask(context context.Context, batchMerkleRoot [32]byte, batchDataPointer string) error {
 
 	txOpts := w.Signer.GetTxOpts()
@@ -85,25 +184,31 @@ func (w *AvsWriter) SendTask(context context.Context, batchMerkleRoot [32]byte,
 	return nil
 }
 
-func (w *AvsWriter) SendAggregatedResponse(batchMerkleRoot [32]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) (*common.Hash, error) {
-	txOpts := *w.Signer.GetTxOpts()
-	txOpts.NoSend = true // simulate the transaction
-	tx, err := w.AvsContractBindings.ServiceManager.RespondToTask(&txOpts, batchMerkleRoot, nonSignerStakesAndSignature)
-	if err != nil {
-		return nil, err
-	}
-
-	// Send the transaction
-	txOpts.NoSend = false
-	txOpts.GasLimit = tx.Gas() * 110 / 100 // Add 10% to the gas limit
-	tx, err = w.AvsContractBindings.ServiceManager.RespondToTask(&txOpts, batchMerkleRoot, nonSignerStakesAndSignature)
-	if err != nil {
-		return nil, err
-	}
+// NewAggregatedResponseTx returns the transaction responding to the task, to be sent with SendAggregatedResponse
+func (w *AvsWriter) NewAggregatedResponseTx(batchMerkleRoot [32]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) *Tx {
+	return NewTx(func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return w.AvsContractBindings.ServiceManager.RespondToTask(opts, batchMerkleRoot, nonSignerStakesAndSignature)
+	})
+}
 
-	txHash := tx.Hash()
+// SendAggregatedResponse sends the response to the task with the given transaction manager and
+// waits until it is confirmed. Returns ErrBatchAlreadyResponded if another transaction responded
+// to the task first. If it fails while the response is pending, sending it again keeps its nonce.
+func (w *AvsWriter) SendAggregatedResponse(ctx context.Context, txManager *TxManager, tx *Tx, batchMerkleRoot [32]byte) (*types.Receipt, error) {
+	receipt, err := txManager.SendTx(ctx, tx)
 
-	return &txHash, nil
+	// The simulation reverts if the batch was responded before sending the transaction,
+	// and the transaction reverts if it was responded while it was pending
+	if err != nil && strings.Contains(err.Error(), batchAlreadyRespondedRevert) {
+		return nil, ErrBatchAlreadyResponded
+	}
+	if errors.Is(err, ErrTxReverted) {
+		batchState, stateErr := w.AvsContractBindings.ServiceManager.BatchesState(&bind.CallOpts{Context: ctx}, batchMerkleRoot)
+		if stateErr == nil && batchState.Responded {
+			return receipt, ErrBatchAlreadyResponded
+		}
+	}
+	return receipt, err
 }
 
 // func (w *AvsWriter) RaiseChallenge(
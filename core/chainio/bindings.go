@@ -1,18 +1,29 @@
 package chainio
 
 import (
+	"strings"
+
 	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
 	"github.com/Layr-Labs/eigensdk-go/logging"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	gethcommon "github.com/ethereum/go-ethereum/common"
 
 	csservicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
 )
 
+// ABI of the QUORUM_THRESHOLD_PERCENTAGE getter of the service manager. It is not part of the
+// generated binding until it is regenerated with `make bindings`; remove it then. Service managers
+// deployed before the constant was public don't have it, and the aggregator doesn't start with them
+const quorumThresholdPercentageAbi = `[{"type":"function","name":"QUORUM_THRESHOLD_PERCENTAGE","inputs":[],"outputs":[{"name":"","type":"uint8","internalType":"uint8"}],"stateMutability":"view"}]`
+
 type AvsServiceBindings struct {
 	ServiceManager *csservicemanager.ContractAlignedLayerServiceManager
-	ethClient      eth.Client
-	logger         logging.Logger
+	// Calls the QUORUM_THRESHOLD_PERCENTAGE getter of the service manager
+	quorumThresholdCaller *bind.BoundContract
+	ethClient             eth.Client
+	logger                logging.Logger
 }
 
 func NewAvsServiceBindings(serviceManagerAddr, blsOperatorStateRetrieverAddr gethcommon.Address, ethclient eth.Client, logger logging.Logger) (*AvsServiceBindings, error) {
@@ -22,9 +33,15 @@ func NewAvsServiceBindings(serviceManagerAddr, blsOperatorStateRetrieverAddr get
 		return nil, err
 	}
 
+	quorumThresholdAbi, err := abi.JSON(strings.NewReader(quorumThresholdPercentageAbi))
+	if err != nil {
+		return nil, err
+	}
+
 	return &AvsServiceBindings{
-		ServiceManager: contractServiceManager,
-		ethClient:      ethclient,
-		logger:         logger,
+		ServiceManager:        contractServiceManager,
+		quorumThresholdCaller: bind.NewBoundContract(serviceManagerAddr, quorumThresholdAbi, ethclient, ethclient, ethclient),
+		ethClient:             ethclient,
+		logger:                logger,
 	}, nil
 }
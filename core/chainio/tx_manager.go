@@ -0,0 +1,566 @@
+package chainio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/Layr-Labs/eigensdk-go/signer"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	DefaultTxConfirmationBlocks        = 1
+	DefaultTxReplacementTimeout        = 30 * time.Second
+	DefaultTxFeeBumpPercentage         = 20
+	DefaultTxGasLimitPaddingPercentage = 10
+	DefaultTxReceiptPollInterval       = 2 * time.Second
+	DefaultTxCancelTimeout             = 5 * time.Minute
+
+	// Nodes reject replacements that don't increase the fees by at least 10%
+	MinTxFeeBumpPercentage = 10
+)
+
+var (
+	// ErrTxReverted is returned with the receipt of a transaction that was included but reverted
+	ErrTxReverted = errors.New("transaction reverted")
+	// ErrTxNonceUsed is returned if the nonce of the transaction was used by a transaction not sent by the TxManager
+	ErrTxNonceUsed = errors.New("transaction nonce used by another transaction")
+)
+
+type TxManagerConfig struct {
+	// Blocks that must be mined on top of the one including the transaction, counting it,
+	// before it is considered final. 1 returns as soon as the transaction is included.
+	ConfirmationBlocks uint64
+	// Time to wait for a transaction to be included before replacing it with higher fees
+	ReplacementTimeout time.Duration
+	// Percentage the fees are increased by on each replacement, at least MinTxFeeBumpPercentage
+	FeeBumpPercentage uint64
+	// Max fee per gas the transactions are sent with, nil for no limit
+	MaxFeePerGas *big.Int
+	// Percentage added to the estimated gas limit
+	GasLimitPaddingPercentage uint64
+	ReceiptPollInterval       time.Duration
+	// Time Send waits for the cancel of a transaction it gave up on to be confirmed
+	CancelTimeout time.Duration
+}
+
+func DefaultTxManagerConfig() TxManagerConfig {
+	return TxManagerConfig{
+		ConfirmationBlocks:        DefaultTxConfirmationBlocks,
+		ReplacementTimeout:        DefaultTxReplacementTimeout,
+		FeeBumpPercentage:         DefaultTxFeeBumpPercentage,
+		GasLimitPaddingPercentage: DefaultTxGasLimitPaddingPercentage,
+		ReceiptPollInterval:       DefaultTxReceiptPollInterval,
+		CancelTimeout:             DefaultTxCancelTimeout,
+	}
+}
+
+// TxBuilder builds and signs a transaction with the given options, without sending it.
+// It is called again with the same nonce and higher fees to replace the transaction.
+type TxBuilder func(opts *bind.TransactOpts) (*gethtypes.Transaction, error)
+
+// Tx is a transaction sent by a TxManager at a single nonce. When SendTx fails before the
+// transaction is confirmed, calling it again with the same Tx keeps replacing the transaction at
+// its nonce instead of sending it at a new one behind it. A Tx given up on must be abandoned.
+type Tx struct {
+	build TxBuilder
+	// Set while the Tx holds a nonce, from the first time it is sent until it is confirmed or abandoned
+	opts         *bind.TransactOpts
+	fees         txFees
+	sentTxHashes []gethcommon.Hash
+	lastSentAt   time.Time
+	// Set when the node rejects a replacement because the nonce was already included
+	nonceUsedAt *time.Time
+	// Set when the transaction is being replaced by a cancel, whose fees are not limited
+	cancelling bool
+}
+
+func NewTx(build TxBuilder) *Tx {
+	return &Tx{build: build}
+}
+
+// Pending returns whether the transaction was sent and holds its nonce, so it must be sent
+// again or abandoned
+func (tx *Tx) Pending() bool {
+	return tx.opts != nil
+}
+
+func (tx *Tx) nonce() uint64 {
+	return tx.opts.Nonce.Uint64()
+}
+
+// release clears the state of the sent transaction, after its nonce was used or released
+func (tx *Tx) release() {
+	tx.opts = nil
+	tx.fees = txFees{}
+	tx.sentTxHashes = nil
+	tx.nonceUsedAt = nil
+	tx.cancelling = false
+}
+
+// TxManager sends the transactions of a wallet. Nonces are allocated locally, so several
+// transactions can be sent concurrently, and transactions that are not included in time
+// are replaced at the same nonce with higher fees until they are confirmed.
+type TxManager struct {
+	client eth.Client
+	signer signer.Signer
+	config TxManagerConfig
+	logger logging.Logger
+
+	// Protects nextNonce, allocatedNonces and releasedNonces
+	nonceMutex sync.Mutex
+	// Next nonce to allocate, nil until it is read from the node
+	nextNonce *uint64
+	// Nonces of the transactions not confirmed or abandoned yet, which must not be allocated again
+	allocatedNonces map[uint64]struct{}
+	// Nonces released without sending anything, under nextNonce. Allocated before the new ones
+	// so no gap is left behind the transactions sent with the following nonces
+	releasedNonces []uint64
+}
+
+func NewTxManager(client eth.Client, signer signer.Signer, config TxManagerConfig, logger logging.Logger) *TxManager {
+	return &TxManager{
+		client:          client,
+		signer:          signer,
+		config:          config,
+		logger:          logger,
+		allocatedNonces: make(map[uint64]struct{}),
+	}
+}
+
+// Address returns the address of the wallet that sends the transactions
+func (m *TxManager) Address() gethcommon.Address {
+	return m.signer.GetTxOpts().From
+}
+
+// txFees are the EIP-1559 fees of a transaction
+type txFees struct {
+	gasTipCap *big.Int
+	gasFeeCap *big.Int
+}
+
+// Send sends a transaction built by build until it is confirmed, as SendTx does. If it fails
+// while the transaction is pending, the transaction is abandoned so its nonce doesn't block
+// the following ones.
+func (m *TxManager) Send(ctx context.Context, build TxBuilder) (*gethtypes.Receipt, error) {
+	tx := NewTx(build)
+	receipt, err := m.SendTx(ctx, tx)
+	if err != nil && tx.Pending() {
+		abandonCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), m.config.CancelTimeout)
+		defer cancel()
+		if abandonErr := m.Abandon(abandonCtx, tx); abandonErr != nil {
+			m.logger.Error("Could not abandon transaction", "nonce", tx.nonce(), "err", abandonErr)
+		}
+	}
+	return receipt, err
+}
+
+// SendTx simulates the transaction, sends it and waits until it is confirmed, replacing it
+// when it is not included in time. Reverts found by the simulation are returned without
+// using a nonce. A reverted transaction is returned with its receipt and ErrTxReverted.
+// If the transaction is pending when ctx is done, calling SendTx again keeps replacing it.
+func (m *TxManager) SendTx(ctx context.Context, tx *Tx) (*gethtypes.Receipt, error) {
+	if !tx.Pending() {
+		if err := m.sendFirst(ctx, tx); err != nil {
+			return nil, err
+		}
+	}
+	tx.opts.Context = ctx
+
+	receipt, err := m.waitForConfirmation(ctx, tx)
+	switch {
+	case err == nil, errors.Is(err, ErrTxReverted):
+		m.finishNonce(tx.nonce())
+		tx.release()
+	case errors.Is(err, ErrTxNonceUsed):
+		m.finishNonce(tx.nonce())
+		tx.release()
+		m.resyncNonce(ctx)
+	}
+	return receipt, err
+}
+
+// sendFirst sends the transaction with a new nonce
+func (m *TxManager) sendFirst(ctx context.Context, tx *Tx) error {
+	fees, err := m.estimateFees(ctx)
+	if err != nil {
+		return fmt.Errorf("could not estimate fees: %w", err)
+	}
+
+	// The gas limit is estimated by the binding, the nonce is not used by the simulation
+	opts := m.txOpts(ctx, fees)
+	opts.Nonce = new(big.Int)
+	simulatedTx, err := tx.build(opts)
+	if err != nil {
+		return err
+	}
+	gasLimit := simulatedTx.Gas() * (100 + m.config.GasLimitPaddingPercentage) / 100
+
+	nonce, err := m.allocateNonce(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get nonce: %w", err)
+	}
+	opts = m.txOpts(ctx, fees)
+	opts.Nonce = new(big.Int).SetUint64(nonce)
+	opts.GasLimit = gasLimit
+
+	sentTx, err := m.buildAndSend(ctx, tx.build, opts)
+	if err != nil {
+		if isNonceTooLow(err) {
+			// Used by a transaction not sent by the TxManager
+			m.finishNonce(nonce)
+			m.resyncNonce(ctx)
+		} else {
+			// Nothing was sent with the nonce, the next transaction must use it
+			m.releaseNonce(nonce)
+		}
+		return err
+	}
+	m.logger.Info("Transaction sent", "txHash", sentTx.Hash().Hex(), "nonce", nonce,
+		"gasTipCap", fees.gasTipCap, "gasFeeCap", fees.gasFeeCap)
+
+	tx.opts = opts
+	tx.fees = fees
+	tx.sentTxHashes = []gethcommon.Hash{sentTx.Hash()}
+	tx.lastSentAt = time.Now()
+	return nil
+}
+
+// Abandon gives up on a pending transaction. Unless its nonce was already used, it is replaced
+// by a transfer of 0 to the wallet itself, so the transactions sent with the following nonces
+// are not stuck behind it. The cancel is sent even above MaxFeePerGas, since it only uses
+// 21000 gas. If ctx is done before the nonce is used, Abandon can be called again.
+func (m *TxManager) Abandon(ctx context.Context, tx *Tx) error {
+	if !tx.Pending() {
+		return nil
+	}
+	nonce := tx.nonce()
+
+	confirmedNonce, err := m.client.NonceAt(ctx, m.Address(), nil)
+	if err != nil {
+		return fmt.Errorf("could not get confirmed nonce: %w", err)
+	}
+	if confirmedNonce > nonce {
+		// Included already, either one of the sent transactions or another one
+		m.finishNonce(nonce)
+		tx.release()
+		return nil
+	}
+
+	if !tx.cancelling {
+		chainId, err := m.client.ChainID(ctx)
+		if err != nil {
+			return fmt.Errorf("could not get chain id: %w", err)
+		}
+		fees, err := m.bumpFees(ctx, tx.fees, false)
+		if err != nil {
+			return fmt.Errorf("could not bump fees: %w", err)
+		}
+
+		opts := m.txOpts(ctx, fees)
+		opts.Nonce = new(big.Int).SetUint64(nonce)
+		opts.GasLimit = params.TxGas
+		cancelTx, err := m.buildAndSend(ctx, cancelTxBuilder(chainId), opts)
+		switch {
+		case err != nil && isNonceTooLow(err):
+			m.logger.Info("Transaction nonce already included, waiting for its receipt", "nonce", nonce)
+			now := time.Now()
+			tx.nonceUsedAt = &now
+		case err != nil:
+			return fmt.Errorf("could not send cancel transaction: %w", err)
+		default:
+			tx.sentTxHashes = append(tx.sentTxHashes, cancelTx.Hash())
+			tx.lastSentAt = time.Now()
+			m.logger.Info("Cancelling transaction", "txHash", cancelTx.Hash().Hex(), "nonce", nonce,
+				"gasTipCap", fees.gasTipCap, "gasFeeCap", fees.gasFeeCap)
+		}
+		tx.build = cancelTxBuilder(chainId)
+		tx.opts = opts
+		tx.fees = fees
+		tx.cancelling = true
+	}
+	tx.opts.Context = ctx
+
+	// Any of the transactions sent with the nonce being included frees it
+	_, err = m.waitForConfirmation(ctx, tx)
+	if err != nil && !errors.Is(err, ErrTxReverted) && !errors.Is(err, ErrTxNonceUsed) {
+		return fmt.Errorf("transaction with nonce %d still pending: %w", nonce, err)
+	}
+	m.finishNonce(nonce)
+	tx.release()
+	return nil
+}
+
+// cancelTxBuilder builds transfers of 0 to the sender, which replace a transaction at its nonce
+func cancelTxBuilder(chainId *big.Int) TxBuilder {
+	return func(opts *bind.TransactOpts) (*gethtypes.Transaction, error) {
+		return opts.Signer(opts.From, gethtypes.NewTx(&gethtypes.DynamicFeeTx{
+			ChainID:   chainId,
+			Nonce:     opts.Nonce.Uint64(),
+			GasTipCap: opts.GasTipCap,
+			GasFeeCap: opts.GasFeeCap,
+			Gas:       opts.GasLimit,
+			To:        &opts.From,
+			Value:     new(big.Int),
+		}))
+	}
+}
+
+// waitForConfirmation polls the receipts of every transaction sent with the nonce,
+// since any of them can be the one included
+func (m *TxManager) waitForConfirmation(ctx context.Context, tx *Tx) (*gethtypes.Receipt, error) {
+	ticker := time.NewTicker(m.config.ReceiptPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("transaction %s not confirmed: %w", tx.sentTxHashes[len(tx.sentTxHashes)-1].Hex(), ctx.Err())
+		case <-ticker.C:
+		}
+
+		receipt, confirmed := m.findReceipt(ctx, tx.sentTxHashes)
+		if confirmed {
+			if receipt.Status != gethtypes.ReceiptStatusSuccessful {
+				return receipt, fmt.Errorf("%w: %s", ErrTxReverted, receipt.TxHash.Hex())
+			}
+			m.logger.Info("Transaction confirmed", "txHash", receipt.TxHash.Hex(), "block", receipt.BlockNumber)
+			return receipt, nil
+		}
+		// Included but not deep enough yet, it must not be replaced
+		if receipt != nil {
+			continue
+		}
+
+		if tx.nonceUsedAt != nil {
+			if time.Since(*tx.nonceUsedAt) > m.config.ReplacementTimeout {
+				return nil, fmt.Errorf("%w: %d", ErrTxNonceUsed, tx.nonce())
+			}
+			continue
+		}
+		if time.Since(tx.lastSentAt) < m.config.ReplacementTimeout {
+			continue
+		}
+
+		newFees, err := m.bumpFees(ctx, tx.fees, !tx.cancelling)
+		if err != nil {
+			m.logger.Warn("Could not replace transaction", "txHash", tx.sentTxHashes[len(tx.sentTxHashes)-1].Hex(), "err", err)
+			tx.lastSentAt = time.Now()
+			continue
+		}
+		tx.opts.GasTipCap, tx.opts.GasFeeCap = newFees.gasTipCap, newFees.gasFeeCap
+
+		tx.lastSentAt = time.Now()
+		sentTx, err := m.buildAndSend(ctx, tx.build, tx.opts)
+		switch {
+		case err != nil && isNonceTooLow(err):
+			m.logger.Info("Transaction nonce already included, waiting for its receipt", "nonce", tx.opts.Nonce)
+			now := time.Now()
+			tx.nonceUsedAt = &now
+		case err != nil:
+			m.logger.Warn("Could not send replacement transaction", "nonce", tx.opts.Nonce, "err", err)
+		default:
+			tx.fees = newFees
+			tx.sentTxHashes = append(tx.sentTxHashes, sentTx.Hash())
+			m.logger.Info("Transaction not included in time, replaced it with higher fees",
+				"txHash", sentTx.Hash().Hex(), "nonce", tx.opts.Nonce, "gasTipCap", tx.fees.gasTipCap, "gasFeeCap", tx.fees.gasFeeCap)
+		}
+	}
+}
+
+func isNonceTooLow(err error) bool {
+	return strings.Contains(err.Error(), "nonce too low")
+}
+
+// findReceipt returns the receipt of the first included transaction, and whether it has
+// enough confirmations. Receipts are fetched again on every call, so reorgs are noticed.
+func (m *TxManager) findReceipt(ctx context.Context, txHashes []gethcommon.Hash) (*gethtypes.Receipt, bool) {
+	for _, txHash := range txHashes {
+		receipt, err := m.client.TransactionReceipt(ctx, txHash)
+		if err != nil {
+			continue
+		}
+
+		blockNumber, err := m.client.BlockNumber(ctx)
+		if err != nil {
+			return receipt, false
+		}
+		includedAt := receipt.BlockNumber.Uint64()
+		return receipt, blockNumber >= includedAt && blockNumber-includedAt+1 >= m.config.ConfirmationBlocks
+	}
+	return nil, false
+}
+
+func (m *TxManager) buildAndSend(ctx context.Context, build TxBuilder, opts *bind.TransactOpts) (*gethtypes.Transaction, error) {
+	tx, err := build(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.client.SendTransaction(ctx, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// txOpts returns options to build a transaction without sending it
+func (m *TxManager) txOpts(ctx context.Context, fees txFees) *bind.TransactOpts {
+	opts := *m.signer.GetTxOpts()
+	opts.Context = ctx
+	opts.NoSend = true
+	opts.GasTipCap = fees.gasTipCap
+	opts.GasFeeCap = fees.gasFeeCap
+	return &opts
+}
+
+func (m *TxManager) allocateNonce(ctx context.Context) (uint64, error) {
+	m.nonceMutex.Lock()
+	defer m.nonceMutex.Unlock()
+
+	if m.nextNonce == nil {
+		nonce, err := m.client.PendingNonceAt(ctx, m.Address())
+		if err != nil {
+			return 0, err
+		}
+		m.setNextNonce(nonce)
+	}
+
+	var nonce uint64
+	if len(m.releasedNonces) > 0 {
+		nonce = m.releasedNonces[0]
+		m.releasedNonces = m.releasedNonces[1:]
+	} else {
+		nonce = *m.nextNonce
+		*m.nextNonce++
+	}
+	m.allocatedNonces[nonce] = struct{}{}
+	return nonce, nil
+}
+
+// releaseNonce returns a nonce nothing was sent with, so it is allocated again
+func (m *TxManager) releaseNonce(nonce uint64) {
+	m.nonceMutex.Lock()
+	defer m.nonceMutex.Unlock()
+
+	delete(m.allocatedNonces, nonce)
+	if m.nextNonce == nil || nonce >= *m.nextNonce {
+		return
+	}
+	m.releasedNonces = append(m.releasedNonces, nonce)
+	slices.Sort(m.releasedNonces)
+	// Released nonces right under the next one are allocated as new ones
+	for len(m.releasedNonces) > 0 && m.releasedNonces[len(m.releasedNonces)-1] == *m.nextNonce-1 {
+		m.releasedNonces = m.releasedNonces[:len(m.releasedNonces)-1]
+		*m.nextNonce--
+	}
+}
+
+// finishNonce forgets a nonce that was used by an included transaction
+func (m *TxManager) finishNonce(nonce uint64) {
+	m.nonceMutex.Lock()
+	defer m.nonceMutex.Unlock()
+
+	delete(m.allocatedNonces, nonce)
+}
+
+// resyncNonce reads the nonce from the node again, after a nonce was used by a transaction not
+// sent by the TxManager. Nonces of pending transactions are never allocated again.
+func (m *TxManager) resyncNonce(ctx context.Context) {
+	pendingNonce, err := m.client.PendingNonceAt(ctx, m.Address())
+	if err != nil {
+		m.logger.Warn("Could not read pending nonce", "err", err)
+		return
+	}
+	confirmedNonce, err := m.client.NonceAt(ctx, m.Address(), nil)
+	if err != nil {
+		m.logger.Warn("Could not read confirmed nonce", "err", err)
+		return
+	}
+
+	m.nonceMutex.Lock()
+	defer m.nonceMutex.Unlock()
+
+	m.setNextNonce(pendingNonce)
+	releasedNonces := m.releasedNonces[:0]
+	for _, nonce := range m.releasedNonces {
+		if nonce >= confirmedNonce && nonce < *m.nextNonce {
+			releasedNonces = append(releasedNonces, nonce)
+		}
+	}
+	m.releasedNonces = releasedNonces
+}
+
+// setNextNonce sets the next nonce to the one of the node, or after the allocated ones if they
+// are not known by the node yet. Must be called with the nonce mutex held
+func (m *TxManager) setNextNonce(nodeNonce uint64) {
+	nextNonce := nodeNonce
+	for nonce := range m.allocatedNonces {
+		nextNonce = max(nextNonce, nonce+1)
+	}
+	m.nextNonce = &nextNonce
+}
+
+// estimateFees uses the suggested tip and allows the base fee to double before
+// the transaction stops being includable
+func (m *TxManager) estimateFees(ctx context.Context) (txFees, error) {
+	gasTipCap, err := m.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return txFees{}, err
+	}
+	header, err := m.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return txFees{}, err
+	}
+	if header.BaseFee == nil {
+		return txFees{}, errors.New("chain does not support EIP-1559 transactions")
+	}
+
+	gasFeeCap := new(big.Int).Add(new(big.Int).Mul(header.BaseFee, big.NewInt(2)), gasTipCap)
+	if m.config.MaxFeePerGas != nil && gasFeeCap.Cmp(m.config.MaxFeePerGas) > 0 {
+		gasFeeCap = new(big.Int).Set(m.config.MaxFeePerGas)
+	}
+	if gasTipCap.Cmp(gasFeeCap) > 0 {
+		gasTipCap = new(big.Int).Set(gasFeeCap)
+	}
+	return txFees{gasTipCap: gasTipCap, gasFeeCap: gasFeeCap}, nil
+}
+
+// bumpFees increases the fees by the configured percentage, or to the current
+// estimation if it is higher. If capped, fails if the max fee per gas doesn't allow it.
+func (m *TxManager) bumpFees(ctx context.Context, fees txFees, capped bool) (txFees, error) {
+	estimated, err := m.estimateFees(ctx)
+	if err != nil {
+		return txFees{}, err
+	}
+
+	bump := big.NewInt(int64(100 + m.config.FeeBumpPercentage))
+	bumped := txFees{
+		gasTipCap: bigMax(new(big.Int).Div(new(big.Int).Mul(fees.gasTipCap, bump), big.NewInt(100)), estimated.gasTipCap),
+		gasFeeCap: bigMax(new(big.Int).Div(new(big.Int).Mul(fees.gasFeeCap, bump), big.NewInt(100)), estimated.gasFeeCap),
+	}
+
+	if capped && m.config.MaxFeePerGas != nil && bumped.gasFeeCap.Cmp(m.config.MaxFeePerGas) > 0 {
+		return txFees{}, fmt.Errorf("max fee per gas %s reached", m.config.MaxFeePerGas)
+	}
+	if bumped.gasTipCap.Cmp(bumped.gasFeeCap) > 0 {
+		bumped.gasTipCap = new(big.Int).Set(bumped.gasFeeCap)
+	}
+	return bumped, nil
+}
+
+func bigMax(a *big.Int, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
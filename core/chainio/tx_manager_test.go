@@ -0,0 +1,258 @@
+package chainio_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/Layr-Labs/eigensdk-go/signer"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+)
+
+// fakeClient includes a sent transaction once includeAfter transactions were sent
+type fakeClient struct {
+	eth.Client
+
+	mutex        sync.Mutex
+	pendingNonce uint64
+	includeAfter int
+	sent         []*gethtypes.Transaction
+	sendErr      error
+	receipts     map[gethcommon.Hash]*gethtypes.Receipt
+}
+
+func newFakeClient(includeAfter int) *fakeClient {
+	return &fakeClient{includeAfter: includeAfter, receipts: make(map[gethcommon.Hash]*gethtypes.Receipt)}
+}
+
+func (c *fakeClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1_000), nil
+}
+
+func (c *fakeClient) HeaderByNumber(ctx context.Context, number *big.Int) (*gethtypes.Header, error) {
+	return &gethtypes.Header{BaseFee: big.NewInt(10_000)}, nil
+}
+
+func (c *fakeClient) PendingNonceAt(ctx context.Context, account gethcommon.Address) (uint64, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.pendingNonce, nil
+}
+
+func (c *fakeClient) NonceAt(ctx context.Context, account gethcommon.Address, blockNumber *big.Int) (uint64, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.pendingNonce, nil
+}
+
+func (c *fakeClient) ChainID(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (c *fakeClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return 100, nil
+}
+
+func (c *fakeClient) SendTransaction(ctx context.Context, tx *gethtypes.Transaction) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.sendErr != nil {
+		return c.sendErr
+	}
+	c.sent = append(c.sent, tx)
+	if len(c.sent) == c.includeAfter {
+		c.receipts[tx.Hash()] = &gethtypes.Receipt{
+			Status:      gethtypes.ReceiptStatusSuccessful,
+			TxHash:      tx.Hash(),
+			BlockNumber: big.NewInt(100),
+		}
+	}
+	return nil
+}
+
+func (c *fakeClient) TransactionReceipt(ctx context.Context, txHash gethcommon.Hash) (*gethtypes.Receipt, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	receipt, ok := c.receipts[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+func newTestTxManager(t *testing.T, client eth.Client) *chainio.TxManager {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txSigner, err := signer.NewPrivateKeySigner(privateKey, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := chainio.DefaultTxManagerConfig()
+	config.ReplacementTimeout = 10 * time.Millisecond
+	config.ReceiptPollInterval = time.Millisecond
+	return chainio.NewTxManager(client, txSigner, config, logging.NewNoopLogger())
+}
+
+func buildTestTx(opts *bind.TransactOpts) (*gethtypes.Transaction, error) {
+	gas := opts.GasLimit
+	if gas == 0 {
+		gas = 21_000
+	}
+	tx := gethtypes.NewTx(&gethtypes.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     opts.Nonce.Uint64(),
+		GasTipCap: opts.GasTipCap,
+		GasFeeCap: opts.GasFeeCap,
+		Gas:       gas,
+	})
+	return opts.Signer(opts.From, tx)
+}
+
+func TestTxManagerReplacesStuckTransaction(t *testing.T) {
+	client := newFakeClient(3)
+	client.pendingNonce = 7
+	txManager := newTestTxManager(t, client)
+
+	receipt, err := txManager.Send(context.Background(), buildTestTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(client.sent) != 3 || receipt.TxHash != client.sent[2].Hash() {
+		t.Fatalf("expected the second replacement to be confirmed, sent %d transactions", len(client.sent))
+	}
+	for i, tx := range client.sent {
+		if tx.Nonce() != 7 {
+			t.Errorf("transaction %d sent with nonce %d", i, tx.Nonce())
+		}
+		if tx.Gas() != 21_000*110/100 {
+			t.Errorf("transaction %d sent with gas limit %d", i, tx.Gas())
+		}
+		if i > 0 && tx.GasFeeCap().Cmp(new(big.Int).Div(new(big.Int).Mul(client.sent[i-1].GasFeeCap(), big.NewInt(110)), big.NewInt(100))) < 0 {
+			t.Errorf("replacement %d does not bump the fee cap enough", i)
+		}
+	}
+}
+
+func TestTxManagerAllocatesNonces(t *testing.T) {
+	client := newFakeClient(1)
+	txManager := newTestTxManager(t, client)
+
+	if _, err := txManager.Send(context.Background(), buildTestTx); err != nil {
+		t.Fatal(err)
+	}
+	client.includeAfter = 2
+	if _, err := txManager.Send(context.Background(), buildTestTx); err != nil {
+		t.Fatal(err)
+	}
+	if client.sent[0].Nonce() != 0 || client.sent[1].Nonce() != 1 {
+		t.Errorf("unexpected nonces %d and %d", client.sent[0].Nonce(), client.sent[1].Nonce())
+	}
+
+	// A nonce that could not be used is allocated again
+	client.sendErr = errors.New("insufficient funds")
+	if _, err := txManager.Send(context.Background(), buildTestTx); err == nil {
+		t.Fatal("expected send error")
+	}
+	client.sendErr = nil
+	client.pendingNonce = 2
+	client.includeAfter = 3
+	if _, err := txManager.Send(context.Background(), buildTestTx); err != nil {
+		t.Fatal(err)
+	}
+	if client.sent[2].Nonce() != 2 {
+		t.Errorf("expected nonce 2 to be reused, got %d", client.sent[2].Nonce())
+	}
+}
+
+func TestTxManagerSimulationErrorDoesNotUseNonce(t *testing.T) {
+	client := newFakeClient(1)
+	txManager := newTestTxManager(t, client)
+
+	_, err := txManager.Send(context.Background(), func(opts *bind.TransactOpts) (*gethtypes.Transaction, error) {
+		return nil, errors.New("execution reverted: Batch already responded")
+	})
+	if err == nil || len(client.sent) != 0 {
+		t.Fatal("expected simulation error without sending")
+	}
+}
+
+func TestTxManagerKeepsNonceWhenSendTimesOut(t *testing.T) {
+	client := newFakeClient(2)
+	client.pendingNonce = 7
+	txManager := newTestTxManager(t, client)
+
+	// The first attempt times out before the transaction is replaced
+	tx := chainio.NewTx(buildTestTx)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if _, err := txManager.SendTx(ctx, tx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the first attempt to time out, got %v", err)
+	}
+	if !tx.Pending() || len(client.sent) != 1 {
+		t.Fatalf("expected the transaction to be pending, sent %d transactions", len(client.sent))
+	}
+
+	// The retry replaces it at the same nonce
+	receipt, err := txManager.SendTx(context.Background(), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.sent) != 2 || receipt.TxHash != client.sent[1].Hash() {
+		t.Fatalf("expected the replacement to be confirmed, sent %d transactions", len(client.sent))
+	}
+	if client.sent[1].Nonce() != 7 || client.sent[1].GasFeeCap().Cmp(client.sent[0].GasFeeCap()) <= 0 {
+		t.Errorf("replacement sent with nonce %d and fee cap %s", client.sent[1].Nonce(), client.sent[1].GasFeeCap())
+	}
+	if tx.Pending() {
+		t.Error("confirmed transaction still holds its nonce")
+	}
+
+	// The following transaction is not sent behind a gap
+	client.includeAfter = 3
+	if _, err = txManager.Send(context.Background(), buildTestTx); err != nil {
+		t.Fatal(err)
+	}
+	if client.sent[2].Nonce() != 8 {
+		t.Errorf("expected nonce 8, got %d", client.sent[2].Nonce())
+	}
+}
+
+func TestTxManagerAbandonCancelsAtSameNonce(t *testing.T) {
+	client := newFakeClient(2)
+	client.pendingNonce = 7
+	txManager := newTestTxManager(t, client)
+
+	tx := chainio.NewTx(buildTestTx)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if _, err := txManager.SendTx(ctx, tx); err == nil {
+		t.Fatal("expected the first attempt to time out")
+	}
+
+	if err := txManager.Abandon(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+	if tx.Pending() || len(client.sent) != 2 {
+		t.Fatalf("expected the cancel to be confirmed, sent %d transactions", len(client.sent))
+	}
+	cancelTx := client.sent[1]
+	if cancelTx.Nonce() != 7 || *cancelTx.To() != txManager.Address() || cancelTx.Value().Sign() != 0 || cancelTx.Gas() != 21_000 {
+		t.Errorf("unexpected cancel transaction with nonce %d to %s of %s", cancelTx.Nonce(), cancelTx.To().Hex(), cancelTx.Value())
+	}
+	if cancelTx.GasFeeCap().Cmp(client.sent[0].GasFeeCap()) <= 0 {
+		t.Error("cancel does not bump the fee cap")
+	}
+}
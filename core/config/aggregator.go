@@ -6,18 +6,60 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"log"
 	"os"
+	"time"
+)
+
+const (
+	DefaultAggregatorTaskStorePath   = "./aggregator_task_store"
+	DefaultAggregatorShutdownTimeout = 30 * time.Second
+	DefaultAggregatorTxSendTimeout   = 5 * time.Minute
+
+	DefaultAggregatorMaxBackfillBlocks = 1000
+
+	DefaultAggregatorWalletBalanceCheckInterval = time.Minute
+
+	// QUORUM_THRESHOLD_PERCENTAGE of the service manager
+	DefaultAggregatorQuorumThresholdPercentage = 67
+	DefaultAggregatorQuorumRefreshInterval     = 5 * time.Minute
+)
+
+// Ways of choosing the wallet that responds to a task
+const (
+	// Wallets are used in turns
+	WalletAssignmentRoundRobin = "round_robin"
+	// The wallet with the fewest responses being sent is used
+	WalletAssignmentLeastPending = "least_pending"
 )
 
 type AggregatorConfig struct {
 	BaseConfig  *BaseConfig
 	EcdsaConfig *EcdsaConfig
 	BlsConfig   *BlsConfig
-	Aggregator  struct {
+	// Wallets that respond to tasks besides the EcdsaConfig one
+	ResponseWallets []*EcdsaConfig
+	Aggregator      struct {
 		ServerIpPortAddress           string
 		BlsPublicKeyCompendiumAddress common.Address
 		AvsServiceManagerAddress      common.Address
 		EnableMetrics                 bool
 		MetricsIpPortAddress          string
+		TaskStorePath                 string
+		ShutdownTimeout               time.Duration
+		TlsCertFile                   string
+		TlsKeyFile                    string
+		TlsClientCaFile               string
+		RequireOperatorSignatures     bool
+		TxConfirmationBlocks          uint64
+		TxReplacementTimeout          time.Duration
+		TxFeeBumpPercentage           uint64
+		TxMaxFeePerGasGwei            uint64
+		TxSendTimeout                 time.Duration
+		WalletAssignment              string
+		WalletMinBalanceGwei          uint64
+		WalletBalanceCheckInterval    time.Duration
+		QuorumThresholdPercentage     uint8
+		QuorumRefreshInterval         time.Duration
+		MaxBackfillBlocks             uint64
 	}
 }
 
@@ -28,6 +70,29 @@ type AggregatorConfigFromYaml struct {
 		AvsServiceManagerAddress      common.Address `yaml:"avs_service_manager_address"`
 		EnableMetrics                 bool           `yaml:"enable_metrics"`
 		MetricsIpPortAddress          string         `yaml:"metrics_ip_port_address"`
+		TaskStorePath                 string         `yaml:"task_store_path"`
+		ShutdownTimeout               time.Duration  `yaml:"shutdown_timeout"`
+		TlsCertFile                   string         `yaml:"tls_cert_file"`
+		TlsKeyFile                    string         `yaml:"tls_key_file"`
+		TlsClientCaFile               string         `yaml:"tls_client_ca_file"`
+		RequireOperatorSignatures     bool           `yaml:"require_operator_signatures"`
+		TxConfirmationBlocks          uint64         `yaml:"tx_confirmation_blocks"`
+		TxReplacementTimeout          time.Duration  `yaml:"tx_replacement_timeout"`
+		TxFeeBumpPercentage           uint64         `yaml:"tx_fee_bump_percentage"`
+		TxMaxFeePerGasGwei            uint64         `yaml:"tx_max_fee_per_gas_gwei"`
+		TxSendTimeout                 time.Duration  `yaml:"tx_send_timeout"`
+		WalletAssignment              string         `yaml:"wallet_assignment"`
+		WalletMinBalanceGwei          uint64         `yaml:"wallet_min_balance_gwei"`
+		WalletBalanceCheckInterval    time.Duration  `yaml:"wallet_balance_check_interval"`
+		QuorumThresholdPercentage     uint8          `yaml:"quorum_threshold_percentage"`
+		QuorumRefreshInterval         time.Duration  `yaml:"quorum_refresh_interval"`
+		MaxBackfillBlocks             uint64         `yaml:"max_backfill_blocks"`
+	} `yaml:"aggregator"`
+}
+
+type AggregatorWalletsConfigFromYaml struct {
+	Aggregator struct {
+		ResponseWallets []EcdsaSignerConfigFromYaml `yaml:"response_wallets"`
 	} `yaml:"aggregator"`
 }
 
@@ -58,16 +123,97 @@ func NewAggregatorConfig(configFilePath string) *AggregatorConfig {
 		log.Fatal("Error reading aggregator config: ", err)
 	}
 
+	if aggregatorConfigFromYaml.Aggregator.TaskStorePath == "" {
+		aggregatorConfigFromYaml.Aggregator.TaskStorePath = DefaultAggregatorTaskStorePath
+	}
+
+	if aggregatorConfigFromYaml.Aggregator.ShutdownTimeout == 0 {
+		aggregatorConfigFromYaml.Aggregator.ShutdownTimeout = DefaultAggregatorShutdownTimeout
+	}
+
+	if aggregatorConfigFromYaml.Aggregator.TxSendTimeout == 0 {
+		aggregatorConfigFromYaml.Aggregator.TxSendTimeout = DefaultAggregatorTxSendTimeout
+	}
+
+	switch aggregatorConfigFromYaml.Aggregator.WalletAssignment {
+	case "":
+		aggregatorConfigFromYaml.Aggregator.WalletAssignment = WalletAssignmentLeastPending
+	case WalletAssignmentRoundRobin, WalletAssignmentLeastPending:
+	default:
+		log.Fatal("Unknown wallet_assignment: ", aggregatorConfigFromYaml.Aggregator.WalletAssignment)
+	}
+
+	if aggregatorConfigFromYaml.Aggregator.WalletBalanceCheckInterval == 0 {
+		aggregatorConfigFromYaml.Aggregator.WalletBalanceCheckInterval = DefaultAggregatorWalletBalanceCheckInterval
+	}
+
+	switch {
+	case aggregatorConfigFromYaml.Aggregator.QuorumThresholdPercentage == 0:
+		aggregatorConfigFromYaml.Aggregator.QuorumThresholdPercentage = DefaultAggregatorQuorumThresholdPercentage
+	case aggregatorConfigFromYaml.Aggregator.QuorumThresholdPercentage > 100:
+		log.Fatal("quorum_threshold_percentage must be at most 100")
+	}
+
+	if aggregatorConfigFromYaml.Aggregator.QuorumRefreshInterval == 0 {
+		aggregatorConfigFromYaml.Aggregator.QuorumRefreshInterval = DefaultAggregatorQuorumRefreshInterval
+	}
+
+	if aggregatorConfigFromYaml.Aggregator.MaxBackfillBlocks == 0 {
+		aggregatorConfigFromYaml.Aggregator.MaxBackfillBlocks = DefaultAggregatorMaxBackfillBlocks
+	}
+
+	var walletsConfigFromYaml AggregatorWalletsConfigFromYaml
+	err = sdkutils.ReadYamlConfig(configFilePath, &walletsConfigFromYaml)
+	if err != nil {
+		log.Fatal("Error reading aggregator response wallets config: ", err)
+	}
+
+	responseWallets := make([]*EcdsaConfig, 0, len(walletsConfigFromYaml.Aggregator.ResponseWallets))
+	for i, wallet := range walletsConfigFromYaml.Aggregator.ResponseWallets {
+		walletConfig, err := NewEcdsaConfigFromSignerConfig(wallet, baseConfig.ChainId)
+		if err != nil {
+			log.Fatal("Error reading response wallet ", i, ": ", err)
+		}
+		responseWallets = append(responseWallets, walletConfig)
+	}
+
+	if (aggregatorConfigFromYaml.Aggregator.TlsCertFile == "") != (aggregatorConfigFromYaml.Aggregator.TlsKeyFile == "") {
+		log.Fatal("Both tls_cert_file and tls_key_file must be set to enable TLS")
+	}
+	if aggregatorConfigFromYaml.Aggregator.TlsClientCaFile != "" && aggregatorConfigFromYaml.Aggregator.TlsCertFile == "" {
+		log.Fatal("tls_client_ca_file requires TLS to be enabled")
+	}
+
 	return &AggregatorConfig{
 		BaseConfig:  baseConfig,
 		EcdsaConfig: ecdsaConfig,
 		BlsConfig:   blsConfig,
+
+		ResponseWallets: responseWallets,
+
 		Aggregator: struct {
 			ServerIpPortAddress           string
 			BlsPublicKeyCompendiumAddress common.Address
 			AvsServiceManagerAddress      common.Address
 			EnableMetrics                 bool
 			MetricsIpPortAddress          string
+			TaskStorePath                 string
+			ShutdownTimeout               time.Duration
+			TlsCertFile                   string
+			TlsKeyFile                    string
+			TlsClientCaFile               string
+			RequireOperatorSignatures     bool
+			TxConfirmationBlocks          uint64
+			TxReplacementTimeout          time.Duration
+			TxFeeBumpPercentage           uint64
+			TxMaxFeePerGasGwei            uint64
+			TxSendTimeout                 time.Duration
+			WalletAssignment              string
+			WalletMinBalanceGwei          uint64
+			WalletBalanceCheckInterval    time.Duration
+			QuorumThresholdPercentage     uint8
+			QuorumRefreshInterval         time.Duration
+			MaxBackfillBlocks             uint64
 		}(aggregatorConfigFromYaml.Aggregator),
 	}
 }
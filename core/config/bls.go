@@ -1,21 +1,37 @@
 package config
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
 	sdkutils "github.com/Layr-Labs/eigensdk-go/utils"
+	"github.com/yetanotherco/aligned_layer/core/signer"
 	"log"
 	"os"
 )
 
+// Where the BLS key is kept
+const (
+	// The key is read from a local keystore file
+	BlsSignerTypeKeystore = "keystore"
+	// The key is held by the signer daemon reached over its unix socket
+	BlsSignerTypeDaemon = "daemon"
+	// The key is held by the signer daemon reached over HTTPS, or plain HTTP on localhost
+	BlsSignerTypeRemote = "remote"
+)
+
 type BlsConfig struct {
-	KeyPair *bls.KeyPair
+	Signer signer.BlsSigner
 }
 
 type BlsConfigFromYaml struct {
 	Bls struct {
+		SignerType              string `yaml:"signer_type"`
 		PrivateKeyStorePath     string `yaml:"private_key_store_path"`
 		PrivateKeyStorePassword string `yaml:"private_key_store_password"`
+		RemoteSignerUrl         string `yaml:"remote_signer_url"`
+		SignerSocketPath        string `yaml:"signer_socket_path"`
 	} `yaml:"bls"`
 }
 
@@ -30,16 +46,50 @@ func NewBlsConfig(blsConfigFilePath string) *BlsConfig {
 		log.Fatal("Error reading bls config: ", err)
 	}
 
-	if blsConfigFromYaml.Bls.PrivateKeyStorePath == "" {
-		log.Fatal("Bls private key store path is empty")
-	}
+	var blsSigner signer.BlsSigner
+	switch blsConfigFromYaml.Bls.SignerType {
+	case "", BlsSignerTypeKeystore:
+		if blsConfigFromYaml.Bls.PrivateKeyStorePath == "" {
+			log.Fatal("Bls private key store path is empty")
+		}
 
-	blsKeyPair, err := bls.ReadPrivateKeyFromFile(blsConfigFromYaml.Bls.PrivateKeyStorePath, blsConfigFromYaml.Bls.PrivateKeyStorePassword)
+		blsKeyPair, err := bls.ReadPrivateKeyFromFile(blsConfigFromYaml.Bls.PrivateKeyStorePath, blsConfigFromYaml.Bls.PrivateKeyStorePassword)
+		if err != nil {
+			log.Fatal("Error reading bls private key from file: ", err)
+		}
+		blsSigner = signer.NewLocalBlsSigner(blsKeyPair)
+	case BlsSignerTypeDaemon:
+		if blsConfigFromYaml.Bls.SignerSocketPath == "" {
+			log.Fatal("Bls signer socket path is empty")
+		}
+		blsSigner, err = newRemoteBlsSigner(signer.UnixSocketUrlPrefix + blsConfigFromYaml.Bls.SignerSocketPath)
+	case BlsSignerTypeRemote:
+		if blsConfigFromYaml.Bls.RemoteSignerUrl == "" {
+			log.Fatal("Bls remote signer url is empty")
+		}
+		// The signatures are sent in the clear otherwise
+		if err = signer.CheckSecureSignerUrl(blsConfigFromYaml.Bls.RemoteSignerUrl); err != nil {
+			log.Fatal("Invalid bls remote signer url: ", err)
+		}
+		blsSigner, err = newRemoteBlsSigner(blsConfigFromYaml.Bls.RemoteSignerUrl)
+	default:
+		log.Fatal("Unknown bls signer type: ", blsConfigFromYaml.Bls.SignerType)
+	}
 	if err != nil {
-		log.Fatal("Error reading bls private key from file: ", err)
+		log.Fatal("Error connecting to the bls remote signer: ", err)
 	}
 
 	return &BlsConfig{
-		KeyPair: blsKeyPair,
+		Signer: blsSigner,
+	}
+}
+
+func newRemoteBlsSigner(url string) (signer.BlsSigner, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), signer.DefaultRemoteSignerTimeout)
+	defer cancel()
+	remoteSigner, err := signer.NewRemoteBlsSigner(ctx, url, signer.DefaultRemoteSignerTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("error creating remote bls signer: %w", err)
 	}
+	return remoteSigner, nil
 }
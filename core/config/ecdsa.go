@@ -1,26 +1,43 @@
 package config
 
 import (
-	"crypto/ecdsa"
+	"context"
 	"errors"
+	"fmt"
 	ecdsa2 "github.com/Layr-Labs/eigensdk-go/crypto/ecdsa"
-	"github.com/Layr-Labs/eigensdk-go/signer"
 	sdkutils "github.com/Layr-Labs/eigensdk-go/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yetanotherco/aligned_layer/core/signer"
 	"log"
 	"math/big"
 	"os"
 )
 
+// Where the ECDSA key is kept
+const (
+	// The key is read from a local keystore file
+	EcdsaSignerTypeKeystore = "keystore"
+	// The key is held by a Web3Signer compatible signer reached over HTTPS, or plain HTTP on localhost
+	EcdsaSignerTypeWeb3Signer = "web3signer"
+	// The key is held by the local signing daemon reached over its unix socket
+	EcdsaSignerTypeDaemon = "daemon"
+)
+
 type EcdsaConfig struct {
-	PrivateKey *ecdsa.PrivateKey
-	Signer     signer.Signer
+	Signer signer.Signer
 }
 
 type EcdsaConfigFromYaml struct {
-	Ecdsa struct {
-		PrivateKeyStorePath     string `yaml:"private_key_store_path"`
-		PrivateKeyStorePassword string `yaml:"private_key_store_password"`
-	} `yaml:"ecdsa"`
+	Ecdsa EcdsaSignerConfigFromYaml `yaml:"ecdsa"`
+}
+
+type EcdsaSignerConfigFromYaml struct {
+	SignerType              string         `yaml:"signer_type"`
+	PrivateKeyStorePath     string         `yaml:"private_key_store_path"`
+	PrivateKeyStorePassword string         `yaml:"private_key_store_password"`
+	RemoteSignerUrl         string         `yaml:"remote_signer_url"`
+	SignerSocketPath        string         `yaml:"signer_socket_path"`
+	Address                 common.Address `yaml:"address"`
 }
 
 func NewEcdsaConfig(ecdsaConfigFilePath string, chainId *big.Int) *EcdsaConfig {
@@ -34,22 +51,66 @@ func NewEcdsaConfig(ecdsaConfigFilePath string, chainId *big.Int) *EcdsaConfig {
 		log.Fatal("Error reading ecdsa config: ", err)
 	}
 
-	if ecdsaConfigFromYaml.Ecdsa.PrivateKeyStorePath == "" {
-		log.Fatal("Ecdsa private key store path is empty")
+	ecdsaConfig, err := NewEcdsaConfigFromSignerConfig(ecdsaConfigFromYaml.Ecdsa, chainId)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	ecdsaKeyPair, err := ecdsa2.ReadKey(ecdsaConfigFromYaml.Ecdsa.PrivateKeyStorePath, ecdsaConfigFromYaml.Ecdsa.PrivateKeyStorePassword)
-	if err != nil {
-		log.Fatal("Error reading ecdsa private key from file: ", err)
+	return ecdsaConfig
+}
+
+// NewEcdsaConfigFromSignerConfig creates the signer of the configured type
+func NewEcdsaConfigFromSignerConfig(signerConfig EcdsaSignerConfigFromYaml, chainId *big.Int) (*EcdsaConfig, error) {
+	switch signerConfig.SignerType {
+	case "", EcdsaSignerTypeKeystore:
+		if signerConfig.PrivateKeyStorePath == "" {
+			return nil, errors.New("ecdsa private key store path is empty")
+		}
+		return NewEcdsaConfigFromKeyStore(signerConfig.PrivateKeyStorePath, signerConfig.PrivateKeyStorePassword, chainId)
+	case EcdsaSignerTypeWeb3Signer:
+		if signerConfig.RemoteSignerUrl == "" {
+			return nil, errors.New("ecdsa remote signer url is empty")
+		}
+		return newRemoteEcdsaConfig(signerConfig.RemoteSignerUrl, signerConfig.Address, chainId)
+	case EcdsaSignerTypeDaemon:
+		if signerConfig.SignerSocketPath == "" {
+			return nil, errors.New("ecdsa signer socket path is empty")
+		}
+		return newRemoteEcdsaConfig(signer.UnixSocketUrlPrefix+signerConfig.SignerSocketPath, signerConfig.Address, chainId)
+	default:
+		return nil, fmt.Errorf("unknown ecdsa signer type: %s", signerConfig.SignerType)
 	}
+}
 
-	privateKeySigner, err := signer.NewPrivateKeySigner(ecdsaKeyPair, chainId)
+// NewEcdsaConfigFromKeyStore reads the private key from the given keystore file
+func NewEcdsaConfigFromKeyStore(privateKeyStorePath string, privateKeyStorePassword string, chainId *big.Int) (*EcdsaConfig, error) {
+	ecdsaKeyPair, err := ecdsa2.ReadKey(privateKeyStorePath, privateKeyStorePassword)
 	if err != nil {
-		log.Fatal("Error creating private key signer: ", err)
+		return nil, fmt.Errorf("error reading ecdsa private key from file: %w", err)
 	}
 
 	return &EcdsaConfig{
-		PrivateKey: ecdsaKeyPair,
-		Signer:     privateKeySigner,
+		Signer: signer.NewLocalSigner(ecdsaKeyPair, chainId),
+	}, nil
+}
+
+func newRemoteEcdsaConfig(url string, address common.Address, chainId *big.Int) (*EcdsaConfig, error) {
+	if address == (common.Address{}) {
+		return nil, errors.New("ecdsa address is required for remote signers")
+	}
+	// The signed requests and transactions are sent in the clear otherwise
+	if err := signer.CheckSecureSignerUrl(url); err != nil {
+		return nil, fmt.Errorf("invalid ecdsa remote signer url: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), signer.DefaultRemoteSignerTimeout)
+	defer cancel()
+	remoteSigner, err := signer.NewRemoteSigner(ctx, url, address, chainId, signer.DefaultRemoteSignerTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to the remote signer: %w", err)
 	}
+
+	return &EcdsaConfig{
+		Signer: remoteSigner,
+	}, nil
 }
@@ -0,0 +1,37 @@
+package config_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/signer"
+)
+
+func TestWeb3SignerRequiresSecureUrl(t *testing.T) {
+	tests := []struct {
+		url      string
+		insecure bool
+	}{
+		{"http://signer.example.com:9000", true},
+		{"ftp://localhost:9000", true},
+		// Secure urls are accepted, and fail connecting to the missing signer
+		{"https://127.0.0.1:1", false},
+		{"http://localhost:1", false},
+	}
+	for _, test := range tests {
+		_, err := config.NewEcdsaConfigFromSignerConfig(config.EcdsaSignerConfigFromYaml{
+			SignerType:      config.EcdsaSignerTypeWeb3Signer,
+			RemoteSignerUrl: test.url,
+			Address:         common.HexToAddress("0x1"),
+		}, big.NewInt(17000))
+		if err == nil {
+			t.Fatalf("%s: expected an error", test.url)
+		}
+		if errors.Is(err, signer.ErrInsecureSignerUrl) != test.insecure {
+			t.Errorf("%s: expected insecure %v, got %v", test.url, test.insecure, err)
+		}
+	}
+}
@@ -6,6 +6,37 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"log"
 	"os"
+	"runtime"
+	"time"
+)
+
+const (
+	DefaultOperatorLastProcessedBlockFilePath = "./operator_last_processed_block"
+	DefaultOperatorMaxBackfillBlocks          = 1000
+	DefaultOperatorProofVerificationTimeout   = 2 * time.Minute
+	DefaultOperatorVerificationCacheSize      = 4096
+	DefaultOperatorVerificationCacheTtl       = 7 * 24 * time.Hour
+	DefaultOperatorIpfsGatewayUrl             = "https://ipfs.io"
+	DefaultOperatorS3Region                   = "us-east-2"
+	DefaultOperatorBatchFetchTimeout          = 2 * time.Minute
+	DefaultOperatorBatchFetchConnectTimeout   = 10 * time.Second
+	DefaultOperatorBatchFetchReadTimeout      = 30 * time.Second
+	DefaultOperatorBatchFetchMaxRetries       = 3
+	DefaultOperatorBatchFetchRetryBackoff     = time.Second
+	DefaultOperatorBatchFetchMaxRetryBackoff  = 30 * time.Second
+	DefaultOperatorParallelBatches            = 2
+	DefaultOperatorBatchQueueSize             = 32
+	DefaultOperatorShutdownTimeout            = 30 * time.Second
+	DefaultOperatorAggregatorRpcApi           = AggregatorRpcApiJsonRpc
+	// Time the aggregator waits for the operator signatures of a batch
+	DefaultOperatorTaskResponseWindow = 100 * time.Second
+)
+
+// APIs the operator can use to talk to the aggregator
+const (
+	AggregatorRpcApiJsonRpc = "jsonrpc"
+	// Deprecated: Go net/rpc API, kept while aggregators are updated
+	AggregatorRpcApiNetRpc = "netrpc"
 )
 
 type OperatorConfig struct {
@@ -16,6 +47,11 @@ type OperatorConfig struct {
 
 	Operator struct {
 		AggregatorServerIpPortAddress string
+		AggregatorRpcApi              string
+		AggregatorTls                 bool
+		AggregatorTlsCaFile           string
+		AggregatorTlsCertFile         string
+		AggregatorTlsKeyFile          string
 		Address                       common.Address
 		EarningsReceiverAddress       common.Address
 		DelegationApproverAddress     common.Address
@@ -25,12 +61,41 @@ type OperatorConfig struct {
 		EnableMetrics                 bool
 		MetricsIpPortAddress          string
 		MaxBatchSize                  int64
+		LastProcessedBlockFilePath    string
+		MaxBackfillBlocks             uint64
+		VerificationWorkers           int
+		VerificationConcurrencyLimits map[string]int
+		ProofVerificationTimeout      time.Duration
+		DisableVerificationCache      bool
+		VerificationCacheSize         int
+		VerificationCachePath         string
+		VerificationCacheTtl          time.Duration
+		BatchMirrors                  []string
+		BatchFilesDir                 string
+		IpfsGatewayUrl                string
+		S3Region                      string
+		S3Endpoint                    string
+		BatchFetchTimeout             time.Duration
+		BatchFetchConnectTimeout      time.Duration
+		BatchFetchReadTimeout         time.Duration
+		BatchFetchMaxRetries          int
+		BatchFetchRetryBackoff        time.Duration
+		BatchFetchMaxRetryBackoff     time.Duration
+		ParallelBatches               int
+		BatchQueueSize                int
+		TaskResponseWindow            time.Duration
+		ShutdownTimeout               time.Duration
 	}
 }
 
 type OperatorConfigFromYaml struct {
 	Operator struct {
 		AggregatorServerIpPortAddress string         `yaml:"aggregator_rpc_server_ip_port_address"`
+		AggregatorRpcApi              string         `yaml:"aggregator_rpc_api"`
+		AggregatorTls                 bool           `yaml:"aggregator_tls"`
+		AggregatorTlsCaFile           string         `yaml:"aggregator_tls_ca_file"`
+		AggregatorTlsCertFile         string         `yaml:"aggregator_tls_cert_file"`
+		AggregatorTlsKeyFile          string         `yaml:"aggregator_tls_key_file"`
 		Address                       common.Address `yaml:"address"`
 		EarningsReceiverAddress       common.Address `yaml:"earnings_receiver_address"`
 		DelegationApproverAddress     common.Address `yaml:"delegation_approver_address"`
@@ -40,6 +105,32 @@ type OperatorConfigFromYaml struct {
 		EnableMetrics                 bool           `yaml:"enable_metrics"`
 		MetricsIpPortAddress          string         `yaml:"metrics_ip_port_address"`
 		MaxBatchSize                  int64          `yaml:"max_batch_size"`
+		LastProcessedBlockFilePath    string         `yaml:"last_processed_block_file_path"`
+		MaxBackfillBlocks             uint64         `yaml:"max_backfill_blocks"`
+		VerificationWorkers           int            `yaml:"verification_workers"`
+		// Max number of proofs of each proving system verified at the same time,
+		// keyed by proving system name
+		VerificationConcurrencyLimits map[string]int `yaml:"verification_concurrency_limits"`
+		ProofVerificationTimeout      time.Duration  `yaml:"proof_verification_timeout"`
+		DisableVerificationCache      bool           `yaml:"disable_verification_cache"`
+		VerificationCacheSize         int            `yaml:"verification_cache_size"`
+		VerificationCachePath         string         `yaml:"verification_cache_path"`
+		VerificationCacheTtl          time.Duration  `yaml:"verification_cache_ttl"`
+		BatchMirrors                  []string       `yaml:"batch_mirrors"`
+		BatchFilesDir                 string         `yaml:"batch_files_dir"`
+		IpfsGatewayUrl                string         `yaml:"ipfs_gateway_url"`
+		S3Region                      string         `yaml:"s3_region"`
+		S3Endpoint                    string         `yaml:"s3_endpoint"`
+		BatchFetchTimeout             time.Duration  `yaml:"batch_fetch_timeout"`
+		BatchFetchConnectTimeout      time.Duration  `yaml:"batch_fetch_connect_timeout"`
+		BatchFetchReadTimeout         time.Duration  `yaml:"batch_fetch_read_timeout"`
+		BatchFetchMaxRetries          int            `yaml:"batch_fetch_max_retries"`
+		BatchFetchRetryBackoff        time.Duration  `yaml:"batch_fetch_retry_backoff"`
+		BatchFetchMaxRetryBackoff     time.Duration  `yaml:"batch_fetch_max_retry_backoff"`
+		ParallelBatches               int            `yaml:"parallel_batches"`
+		BatchQueueSize                int            `yaml:"batch_queue_size"`
+		TaskResponseWindow            time.Duration  `yaml:"task_response_window"`
+		ShutdownTimeout               time.Duration  `yaml:"shutdown_timeout"`
 	} `yaml:"operator"`
 	EcdsaConfigFromYaml EcdsaConfigFromYaml `yaml:"ecdsa"`
 	BlsConfigFromYaml   BlsConfigFromYaml   `yaml:"bls"`
@@ -72,6 +163,93 @@ func NewOperatorConfig(configFilePath string) *OperatorConfig {
 		log.Fatal("Error reading operator config: ", err)
 	}
 
+	if operatorConfigFromYaml.Operator.LastProcessedBlockFilePath == "" {
+		operatorConfigFromYaml.Operator.LastProcessedBlockFilePath = DefaultOperatorLastProcessedBlockFilePath
+	}
+
+	if operatorConfigFromYaml.Operator.MaxBackfillBlocks == 0 {
+		operatorConfigFromYaml.Operator.MaxBackfillBlocks = DefaultOperatorMaxBackfillBlocks
+	}
+
+	if operatorConfigFromYaml.Operator.VerificationWorkers <= 0 {
+		operatorConfigFromYaml.Operator.VerificationWorkers = runtime.NumCPU()
+	}
+
+	if operatorConfigFromYaml.Operator.ProofVerificationTimeout == 0 {
+		operatorConfigFromYaml.Operator.ProofVerificationTimeout = DefaultOperatorProofVerificationTimeout
+	}
+
+	if operatorConfigFromYaml.Operator.VerificationCacheSize <= 0 {
+		operatorConfigFromYaml.Operator.VerificationCacheSize = DefaultOperatorVerificationCacheSize
+	}
+
+	if operatorConfigFromYaml.Operator.VerificationCacheTtl <= 0 {
+		operatorConfigFromYaml.Operator.VerificationCacheTtl = DefaultOperatorVerificationCacheTtl
+	}
+
+	if operatorConfigFromYaml.Operator.IpfsGatewayUrl == "" {
+		operatorConfigFromYaml.Operator.IpfsGatewayUrl = DefaultOperatorIpfsGatewayUrl
+	}
+
+	if operatorConfigFromYaml.Operator.S3Region == "" {
+		operatorConfigFromYaml.Operator.S3Region = DefaultOperatorS3Region
+	}
+
+	if operatorConfigFromYaml.Operator.BatchFetchTimeout == 0 {
+		operatorConfigFromYaml.Operator.BatchFetchTimeout = DefaultOperatorBatchFetchTimeout
+	}
+
+	if operatorConfigFromYaml.Operator.BatchFetchConnectTimeout == 0 {
+		operatorConfigFromYaml.Operator.BatchFetchConnectTimeout = DefaultOperatorBatchFetchConnectTimeout
+	}
+
+	if operatorConfigFromYaml.Operator.BatchFetchReadTimeout == 0 {
+		operatorConfigFromYaml.Operator.BatchFetchReadTimeout = DefaultOperatorBatchFetchReadTimeout
+	}
+
+	// A negative amount of retries disables them
+	if operatorConfigFromYaml.Operator.BatchFetchMaxRetries == 0 {
+		operatorConfigFromYaml.Operator.BatchFetchMaxRetries = DefaultOperatorBatchFetchMaxRetries
+	}
+
+	if operatorConfigFromYaml.Operator.BatchFetchRetryBackoff == 0 {
+		operatorConfigFromYaml.Operator.BatchFetchRetryBackoff = DefaultOperatorBatchFetchRetryBackoff
+	}
+
+	if operatorConfigFromYaml.Operator.BatchFetchMaxRetryBackoff == 0 {
+		operatorConfigFromYaml.Operator.BatchFetchMaxRetryBackoff = DefaultOperatorBatchFetchMaxRetryBackoff
+	}
+
+	if operatorConfigFromYaml.Operator.ParallelBatches == 0 {
+		operatorConfigFromYaml.Operator.ParallelBatches = DefaultOperatorParallelBatches
+	}
+
+	if operatorConfigFromYaml.Operator.BatchQueueSize == 0 {
+		operatorConfigFromYaml.Operator.BatchQueueSize = DefaultOperatorBatchQueueSize
+	}
+
+	if operatorConfigFromYaml.Operator.TaskResponseWindow == 0 {
+		operatorConfigFromYaml.Operator.TaskResponseWindow = DefaultOperatorTaskResponseWindow
+	}
+
+	if operatorConfigFromYaml.Operator.AggregatorRpcApi == "" {
+		operatorConfigFromYaml.Operator.AggregatorRpcApi = DefaultOperatorAggregatorRpcApi
+	}
+	if operatorConfigFromYaml.Operator.AggregatorRpcApi != AggregatorRpcApiJsonRpc &&
+		operatorConfigFromYaml.Operator.AggregatorRpcApi != AggregatorRpcApiNetRpc {
+		log.Fatal("Unknown aggregator rpc api: ", operatorConfigFromYaml.Operator.AggregatorRpcApi)
+	}
+	if operatorConfigFromYaml.Operator.AggregatorTls && operatorConfigFromYaml.Operator.AggregatorRpcApi != AggregatorRpcApiJsonRpc {
+		log.Fatal("aggregator_tls is only supported by the jsonrpc aggregator api")
+	}
+	if (operatorConfigFromYaml.Operator.AggregatorTlsCertFile == "") != (operatorConfigFromYaml.Operator.AggregatorTlsKeyFile == "") {
+		log.Fatal("Both aggregator_tls_cert_file and aggregator_tls_key_file must be set to use a client certificate")
+	}
+
+	if operatorConfigFromYaml.Operator.ShutdownTimeout == 0 {
+		operatorConfigFromYaml.Operator.ShutdownTimeout = DefaultOperatorShutdownTimeout
+	}
+
 	return &OperatorConfig{
 		BaseConfig:                   baseConfig,
 		EcdsaConfig:                  ecdsaConfig,
@@ -79,6 +257,11 @@ func NewOperatorConfig(configFilePath string) *OperatorConfig {
 		AlignedLayerDeploymentConfig: baseConfig.AlignedLayerDeploymentConfig,
 		Operator: struct {
 			AggregatorServerIpPortAddress string
+			AggregatorRpcApi              string
+			AggregatorTls                 bool
+			AggregatorTlsCaFile           string
+			AggregatorTlsCertFile         string
+			AggregatorTlsKeyFile          string
 			Address                       common.Address
 			EarningsReceiverAddress       common.Address
 			DelegationApproverAddress     common.Address
@@ -88,6 +271,30 @@ func NewOperatorConfig(configFilePath string) *OperatorConfig {
 			EnableMetrics                 bool
 			MetricsIpPortAddress          string
 			MaxBatchSize                  int64
+			LastProcessedBlockFilePath    string
+			MaxBackfillBlocks             uint64
+			VerificationWorkers           int
+			VerificationConcurrencyLimits map[string]int
+			ProofVerificationTimeout      time.Duration
+			DisableVerificationCache      bool
+			VerificationCacheSize         int
+			VerificationCachePath         string
+			VerificationCacheTtl          time.Duration
+			BatchMirrors                  []string
+			BatchFilesDir                 string
+			IpfsGatewayUrl                string
+			S3Region                      string
+			S3Endpoint                    string
+			BatchFetchTimeout             time.Duration
+			BatchFetchConnectTimeout      time.Duration
+			BatchFetchReadTimeout         time.Duration
+			BatchFetchMaxRetries          int
+			BatchFetchRetryBackoff        time.Duration
+			BatchFetchMaxRetryBackoff     time.Duration
+			ParallelBatches               int
+			BatchQueueSize                int
+			TaskResponseWindow            time.Duration
+			ShutdownTimeout               time.Duration
 		}(operatorConfigFromYaml.Operator),
 	}
 }
@@ -0,0 +1,186 @@
+package signer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	bn254utils "github.com/Layr-Labs/eigensdk-go/crypto/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Types of the messages a BLS signer can be asked to sign
+const (
+	// The merkle root of a batch, signed in the task responses
+	BlsMessageBatchMerkleRoot = "batch_merkle_root"
+	// The hashed to curve message registering the public key in the BLS apk registry
+	BlsMessagePubkeyRegistration = "pubkey_registration"
+)
+
+// Endpoints of the BLS signing API
+const (
+	blsSignerPublicKeyPath = "/bls/v1/public_key"
+	blsSignerSignPath      = "/bls/v1/sign"
+)
+
+var ErrBlsMessageNotAllowed = errors.New("bls message type not allowed by the signer")
+
+// BlsSigner signs with a BLS key, which a remote signer can keep out of the process
+type BlsSigner interface {
+	PubKeyG1() *bls.G1Point
+	PubKeyG2() *bls.G2Point
+	// SignBatchMerkleRoot signs the merkle root of a batch, as the response to its task
+	SignBatchMerkleRoot(ctx context.Context, batchMerkleRoot [32]byte) (*bls.Signature, error)
+	// SignPubkeyRegistration signs the message registering the public key in the BLS apk registry
+	SignPubkeyRegistration(ctx context.Context, g1HashedMsg *bn254.G1Affine) (*bls.Signature, error)
+}
+
+// LocalBlsSigner signs with a key pair held in memory, read from a local keystore
+type LocalBlsSigner struct {
+	keyPair *bls.KeyPair
+}
+
+func NewLocalBlsSigner(keyPair *bls.KeyPair) *LocalBlsSigner {
+	return &LocalBlsSigner{keyPair: keyPair}
+}
+
+func (s *LocalBlsSigner) PubKeyG1() *bls.G1Point {
+	return s.keyPair.GetPubKeyG1()
+}
+
+func (s *LocalBlsSigner) PubKeyG2() *bls.G2Point {
+	return s.keyPair.GetPubKeyG2()
+}
+
+func (s *LocalBlsSigner) SignBatchMerkleRoot(ctx context.Context, batchMerkleRoot [32]byte) (*bls.Signature, error) {
+	return s.keyPair.SignMessage(batchMerkleRoot), nil
+}
+
+func (s *LocalBlsSigner) SignPubkeyRegistration(ctx context.Context, g1HashedMsg *bn254.G1Affine) (*bls.Signature, error) {
+	return s.keyPair.SignHashedToCurveMessage(g1HashedMsg), nil
+}
+
+// blsPublicKeyResponse has the compressed public keys of the signer
+type blsPublicKeyResponse struct {
+	PubKeyG1 hexutil.Bytes `json:"pubkey_g1"`
+	PubKeyG2 hexutil.Bytes `json:"pubkey_g2"`
+}
+
+// blsSignRequest asks to sign a message of the given type. Batch merkle roots are 32 bytes,
+// and the pubkey registration messages are compressed G1 points.
+type blsSignRequest struct {
+	Type    string        `json:"type"`
+	Message hexutil.Bytes `json:"message"`
+}
+
+// blsSignResponse has the signature as a compressed G1 point
+type blsSignResponse struct {
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// RemoteBlsSigner signs with a key held by the signer daemon, reached over its unix socket or HTTP
+type RemoteBlsSigner struct {
+	baseUrl    string
+	httpClient *http.Client
+	pubKeyG1   *bls.G1Point
+	pubKeyG2   *bls.G2Point
+}
+
+// NewRemoteBlsSigner connects to the signer at the url, which is either an http(s) url or
+// UnixSocketUrlPrefix followed by the path of a unix socket, and gets its public keys
+func NewRemoteBlsSigner(ctx context.Context, url string, timeout time.Duration) (*RemoteBlsSigner, error) {
+	baseUrl, httpClient := newSignerHttpClient(url, timeout)
+
+	var response blsPublicKeyResponse
+	if err := signerGet(ctx, httpClient, baseUrl+blsSignerPublicKeyPath, &response); err != nil {
+		return nil, fmt.Errorf("could not get the public key of the remote bls signer: %w", err)
+	}
+	pubKeyG1 := new(bn254.G1Affine)
+	if _, err := pubKeyG1.SetBytes(response.PubKeyG1); err != nil {
+		return nil, fmt.Errorf("invalid G1 public key returned by the remote bls signer: %w", err)
+	}
+	pubKeyG2 := new(bn254.G2Affine)
+	if _, err := pubKeyG2.SetBytes(response.PubKeyG2); err != nil {
+		return nil, fmt.Errorf("invalid G2 public key returned by the remote bls signer: %w", err)
+	}
+	// Both keys must be of the same private key, or the registration and the signatures fail
+	if ok, err := bn254utils.CheckG1AndG2DiscreteLogEquality(pubKeyG1, pubKeyG2); err != nil || !ok {
+		return nil, errors.New("the public keys of the remote bls signer do not match")
+	}
+
+	return &RemoteBlsSigner{
+		baseUrl:    baseUrl,
+		httpClient: httpClient,
+		pubKeyG1:   &bls.G1Point{G1Affine: pubKeyG1},
+		pubKeyG2:   &bls.G2Point{G2Affine: pubKeyG2},
+	}, nil
+}
+
+func (s *RemoteBlsSigner) PubKeyG1() *bls.G1Point {
+	return s.pubKeyG1
+}
+
+func (s *RemoteBlsSigner) PubKeyG2() *bls.G2Point {
+	return s.pubKeyG2
+}
+
+func (s *RemoteBlsSigner) SignBatchMerkleRoot(ctx context.Context, batchMerkleRoot [32]byte) (*bls.Signature, error) {
+	signature, err := s.sign(ctx, BlsMessageBatchMerkleRoot, batchMerkleRoot[:])
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := signature.Verify(s.pubKeyG2, batchMerkleRoot); err != nil || !ok {
+		return nil, errors.New("remote bls signer returned an invalid signature")
+	}
+	return signature, nil
+}
+
+func (s *RemoteBlsSigner) SignPubkeyRegistration(ctx context.Context, g1HashedMsg *bn254.G1Affine) (*bls.Signature, error) {
+	message := g1HashedMsg.Bytes()
+	signature, err := s.sign(ctx, BlsMessagePubkeyRegistration, message[:])
+	if err != nil {
+		return nil, err
+	}
+	if !verifyHashedToCurveSignature(signature.G1Affine, s.pubKeyG2.G2Affine, g1HashedMsg) {
+		return nil, errors.New("remote bls signer returned an invalid signature")
+	}
+	return signature, nil
+}
+
+func (s *RemoteBlsSigner) sign(ctx context.Context, messageType string, message []byte) (*bls.Signature, error) {
+	body, err := json.Marshal(blsSignRequest{Type: messageType, Message: message})
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := signerPost(ctx, s.httpClient, s.baseUrl+blsSignerSignPath, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var response blsSignResponse
+	if err = json.Unmarshal(responseBody, &response); err != nil {
+		return nil, err
+	}
+	signature := new(bn254.G1Affine)
+	if _, err = signature.SetBytes(response.Signature); err != nil {
+		return nil, fmt.Errorf("invalid signature returned by the remote bls signer: %w", err)
+	}
+	return &bls.Signature{G1Point: &bls.G1Point{G1Affine: signature}}, nil
+}
+
+// verifyHashedToCurveSignature checks e(msg, pubKey) == e(sig, G2), as bls.Signature.Verify
+// does for messages that are not hashed to the curve yet
+func verifyHashedToCurveSignature(signature *bn254.G1Affine, pubKeyG2 *bn254.G2Affine, g1HashedMsg *bn254.G1Affine) bool {
+	var negSignature bn254.G1Affine
+	negSignature.Neg(signature)
+	ok, err := bn254.PairingCheck(
+		[]bn254.G1Affine{*g1HashedMsg, negSignature},
+		[]bn254.G2Affine{*pubKeyG2, *bn254utils.GetG2Generator()},
+	)
+	return err == nil && ok
+}
@@ -0,0 +1,194 @@
+package signer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Outcomes of the sign requests recorded in the audit log
+const (
+	blsAuditSigned  = "signed"
+	blsAuditRefused = "refused"
+)
+
+// blsAuditEntry is a line of the audit log, one for each sign request
+type blsAuditEntry struct {
+	Time    time.Time     `json:"time"`
+	Type    string        `json:"type"`
+	Message hexutil.Bytes `json:"message"`
+	Outcome string        `json:"outcome"`
+	Reason  string        `json:"reason,omitempty"`
+}
+
+// ErrBlsBatchNotPending is returned for batch merkle roots the service manager is not waiting a response for
+var ErrBlsBatchNotPending = errors.New("batch merkle root is not a pending batch of the service manager")
+
+// BlsBatchChecker tells if a batch was created in the service manager and is not responded yet
+type BlsBatchChecker interface {
+	IsPendingBatch(ctx context.Context, batchMerkleRoot [32]byte) (bool, error)
+}
+
+// BlsDaemon serves the BLS signing API with a local key, only signing the allowed message types,
+// and only the batch merkle roots the service manager is waiting a response for.
+// Every sign request is recorded in the audit log as a JSON line, with the reason it was refused.
+type BlsDaemon struct {
+	signer              *LocalBlsSigner
+	allowedMessageTypes map[string]bool
+	batchChecker        BlsBatchChecker
+	// Synced after each line when it is a file
+	auditLog io.Writer
+	// Keeps the lines of concurrent requests apart
+	auditLogMutex sync.Mutex
+	logger        logging.Logger
+}
+
+// NewBlsDaemon signs the allowed message types, which are BlsMessage* values. batchChecker
+// is required to sign batch merkle roots
+func NewBlsDaemon(signer *LocalBlsSigner, allowedMessageTypes []string, batchChecker BlsBatchChecker, auditLog io.Writer, logger logging.Logger) (*BlsDaemon, error) {
+	allowed := make(map[string]bool, len(allowedMessageTypes))
+	for _, messageType := range allowedMessageTypes {
+		if messageType != BlsMessageBatchMerkleRoot && messageType != BlsMessagePubkeyRegistration {
+			return nil, fmt.Errorf("unknown bls message type: %s", messageType)
+		}
+		allowed[messageType] = true
+	}
+	if allowed[BlsMessageBatchMerkleRoot] && batchChecker == nil {
+		return nil, errors.New("batch merkle roots can't be signed without checking them in the service manager")
+	}
+
+	return &BlsDaemon{
+		signer:              signer,
+		allowedMessageTypes: allowed,
+		batchChecker:        batchChecker,
+		auditLog:            auditLog,
+		logger:              logger,
+	}, nil
+}
+
+// RegisterHandlers adds the endpoints of the daemon to the mux
+func (d *BlsDaemon) RegisterHandlers(mux *http.ServeMux) {
+	mux.HandleFunc(blsSignerPublicKeyPath, d.handlePublicKey)
+	mux.HandleFunc(blsSignerSignPath, d.handleSign)
+}
+
+func (d *BlsDaemon) handlePublicKey(w http.ResponseWriter, r *http.Request) {
+	pubKeyG1 := d.signer.PubKeyG1().G1Affine.Bytes()
+	pubKeyG2 := d.signer.PubKeyG2().G2Affine.Bytes()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(blsPublicKeyResponse{PubKeyG1: pubKeyG1[:], PubKeyG2: pubKeyG2[:]})
+}
+
+func (d *BlsDaemon) handleSign(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request blsSignRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, daemonMaxRequestBytes)).Decode(&request); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if !d.allowedMessageTypes[request.Type] {
+		_ = d.audit(request, blsAuditRefused, ErrBlsMessageNotAllowed.Error())
+		http.Error(w, ErrBlsMessageNotAllowed.Error(), http.StatusForbidden)
+		return
+	}
+	if err := d.checkBatch(r.Context(), request); err != nil {
+		_ = d.audit(request, blsAuditRefused, err.Error())
+		status := http.StatusForbidden
+		if !errors.Is(err, ErrBlsBatchNotPending) {
+			status = http.StatusServiceUnavailable
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	signature, err := d.sign(r.Context(), request)
+	if err != nil {
+		_ = d.audit(request, blsAuditRefused, err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = d.audit(request, blsAuditSigned, ""); err != nil {
+		// Signatures that can't be audited are not given
+		d.logger.Error("Could not write the bls audit log", "err", err)
+		http.Error(w, "could not write audit log", http.StatusInternalServerError)
+		return
+	}
+
+	encodedSignature := signature.G1Affine.Bytes()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(blsSignResponse{Signature: encodedSignature[:]})
+}
+
+// checkBatch refuses batch merkle roots the service manager is not waiting a response for,
+// so a compromised operator can't get any message signed as a batch merkle root
+func (d *BlsDaemon) checkBatch(ctx context.Context, request blsSignRequest) error {
+	if request.Type != BlsMessageBatchMerkleRoot || len(request.Message) != 32 {
+		return nil
+	}
+	pending, err := d.batchChecker.IsPendingBatch(ctx, [32]byte(request.Message))
+	if err != nil {
+		return fmt.Errorf("could not check the batch in the service manager: %w", err)
+	}
+	if !pending {
+		return ErrBlsBatchNotPending
+	}
+	return nil
+}
+
+func (d *BlsDaemon) sign(ctx context.Context, request blsSignRequest) (*bls.Signature, error) {
+	switch request.Type {
+	case BlsMessageBatchMerkleRoot:
+		if len(request.Message) != 32 {
+			return nil, errors.New("batch merkle roots are 32 bytes")
+		}
+		return d.signer.SignBatchMerkleRoot(ctx, [32]byte(request.Message))
+	case BlsMessagePubkeyRegistration:
+		g1HashedMsg := new(bn254.G1Affine)
+		if _, err := g1HashedMsg.SetBytes(request.Message); err != nil {
+			return nil, fmt.Errorf("invalid pubkey registration message: %w", err)
+		}
+		return d.signer.SignPubkeyRegistration(ctx, g1HashedMsg)
+	default:
+		return nil, ErrBlsMessageNotAllowed
+	}
+}
+
+func (d *BlsDaemon) audit(request blsSignRequest, outcome string, reason string) error {
+	d.logger.Info("Bls sign request", "type", request.Type, "message", request.Message.String(), "outcome", outcome, "reason", reason)
+
+	line, err := json.Marshal(blsAuditEntry{
+		Time:    time.Now().UTC(),
+		Type:    request.Type,
+		Message: request.Message,
+		Outcome: outcome,
+		Reason:  reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	d.auditLogMutex.Lock()
+	defer d.auditLogMutex.Unlock()
+	if _, err = d.auditLog.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	// Files are synced, so the request is recorded before the signature is given
+	if syncer, ok := d.auditLog.(interface{ Sync() error }); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package signer_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/yetanotherco/aligned_layer/core/signer"
+)
+
+// pendingBatches are the batches the service manager is waiting a response for
+type pendingBatches map[[32]byte]bool
+
+func (b pendingBatches) IsPendingBatch(ctx context.Context, batchMerkleRoot [32]byte) (bool, error) {
+	return b[batchMerkleRoot], nil
+}
+
+func startTestBlsDaemon(t *testing.T, keyPair *bls.KeyPair, allowedMessageTypes []string, batches pendingBatches, auditLog io.Writer) string {
+	blsDaemon, err := signer.NewBlsDaemon(signer.NewLocalBlsSigner(keyPair), allowedMessageTypes, batches, auditLog, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	blsDaemon.RegisterHandlers(mux)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestRemoteBlsSignerSignsAllowedMessages(t *testing.T) {
+	keyPair, err := bls.NewKeyPairFromString("12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+	batchMerkleRoot := [32]byte{1, 2, 3}
+	unknownBatchMerkleRoot := [32]byte{4, 5, 6}
+	var auditLog bytes.Buffer
+	url := startTestBlsDaemon(t, keyPair, []string{signer.BlsMessageBatchMerkleRoot}, pendingBatches{batchMerkleRoot: true}, &auditLog)
+
+	remoteSigner, err := signer.NewRemoteBlsSigner(context.Background(), url, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !remoteSigner.PubKeyG1().Equal(keyPair.GetPubKeyG1().G1Affine) {
+		t.Fatal("remote signer returned another public key")
+	}
+
+	signature, err := remoteSigner.SignBatchMerkleRoot(context.Background(), batchMerkleRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := signature.Verify(keyPair.GetPubKeyG2(), batchMerkleRoot); err != nil || !ok {
+		t.Fatal("signature does not verify with the public key")
+	}
+
+	// Only the batches the service manager is waiting a response for are signed
+	if _, err = remoteSigner.SignBatchMerkleRoot(context.Background(), unknownBatchMerkleRoot); err == nil {
+		t.Fatal("expected an unknown batch to be refused")
+	}
+
+	// Only batch merkle roots are allowed
+	_, _, g1Generator, _ := bn254.Generators()
+	if _, err = remoteSigner.SignPubkeyRegistration(context.Background(), &g1Generator); err == nil {
+		t.Fatal("expected a pubkey registration to be refused")
+	}
+
+	var outcomes []string
+	decoder := json.NewDecoder(&auditLog)
+	for decoder.More() {
+		var entry struct {
+			Type    string `json:"type"`
+			Outcome string `json:"outcome"`
+			Reason  string `json:"reason"`
+		}
+		if err = decoder.Decode(&entry); err != nil {
+			t.Fatal(err)
+		}
+		outcomes = append(outcomes, entry.Type+":"+entry.Outcome+":"+entry.Reason)
+	}
+	expected := []string{
+		signer.BlsMessageBatchMerkleRoot + ":signed:",
+		signer.BlsMessageBatchMerkleRoot + ":refused:" + signer.ErrBlsBatchNotPending.Error(),
+		signer.BlsMessagePubkeyRegistration + ":refused:" + signer.ErrBlsMessageNotAllowed.Error(),
+	}
+	if !slices.Equal(outcomes, expected) {
+		t.Fatalf("audit log has %v instead of %v", outcomes, expected)
+	}
+}
+
+func TestBlsDaemonRejectsUnknownMessageTypes(t *testing.T) {
+	keyPair, err := bls.NewKeyPairFromString("12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = signer.NewBlsDaemon(signer.NewLocalBlsSigner(keyPair), []string{"transaction"}, pendingBatches{}, &bytes.Buffer{}, logging.NewNoopLogger()); err == nil {
+		t.Fatal("expected an unknown message type to be rejected")
+	}
+	if _, err = signer.NewBlsDaemon(signer.NewLocalBlsSigner(keyPair), []string{signer.BlsMessageBatchMerkleRoot}, nil, &bytes.Buffer{}, logging.NewNoopLogger()); err == nil {
+		t.Fatal("expected batch merkle roots to be rejected without a batch checker")
+	}
+}
+
+// syncedAuditLog counts the lines written and synced
+type syncedAuditLog struct {
+	mutex  sync.Mutex
+	lines  int
+	synced int
+}
+
+func (l *syncedAuditLog) Write(p []byte) (int, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.lines++
+	return len(p), nil
+}
+
+func (l *syncedAuditLog) Sync() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.synced = l.lines
+	return nil
+}
+
+func TestBlsDaemonSyncsAuditLogBeforeSigning(t *testing.T) {
+	keyPair, err := bls.NewKeyPairFromString("12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditLog := &syncedAuditLog{}
+	url := startTestBlsDaemon(t, keyPair, []string{signer.BlsMessageBatchMerkleRoot}, pendingBatches{{1}: true}, auditLog)
+
+	remoteSigner, err := signer.NewRemoteBlsSigner(context.Background(), url, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = remoteSigner.SignBatchMerkleRoot(context.Background(), [32]byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	auditLog.mutex.Lock()
+	defer auditLog.mutex.Unlock()
+	if auditLog.lines != 1 || auditLog.synced != 1 {
+		t.Fatalf("expected the request to be synced to the audit log, got %d lines and %d synced", auditLog.lines, auditLog.synced)
+	}
+}
+
+func TestCheckSecureSignerUrl(t *testing.T) {
+	tests := []struct {
+		url    string
+		secure bool
+	}{
+		{"https://signer.example.com:9001", true},
+		{"http://localhost:9001", true},
+		{"http://127.0.0.1:9001", true},
+		{"http://[::1]:9001", true},
+		{signer.UnixSocketUrlPrefix + "./signer.sock", true},
+		{"http://signer.example.com:9001", false},
+		{"http://10.0.0.2:9001", false},
+		{"ftp://localhost", false},
+	}
+	for _, test := range tests {
+		if err := signer.CheckSecureSignerUrl(test.url); (err == nil) != test.secure {
+			t.Errorf("%s: expected secure %v, got %v", test.url, test.secure, err)
+		}
+	}
+}
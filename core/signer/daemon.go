@@ -0,0 +1,243 @@
+package signer
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+// Max size of the requests to the daemon, transactions sent by the services are far smaller
+const daemonMaxRequestBytes = 1 << 20
+
+// DaemonPolicy restricts the transactions signed by the daemon
+type DaemonPolicy struct {
+	// Addresses the transactions can be sent to, any address if empty. The address of
+	// the key is always allowed, as stuck transactions are cancelled by sending to it
+	AllowedToAddresses []common.Address
+	// Max wei transferred by a transaction, nil for no limit
+	MaxValue *big.Int
+	// Domain separators of the EIP-712 messages signed besides the aggregator requests,
+	// e.g. the one of the AVS directory to register the operator
+	AllowedEip712Domains []common.Hash
+}
+
+// Daemon serves the subset of the Web3Signer eth1 API used by RemoteSigner with a local key,
+// so the services can run without reading the key, and without running a full Web3Signer
+type Daemon struct {
+	signer    *LocalSigner
+	publicKey string
+	chainId   *big.Int
+	// nil if transactions can be sent to any address
+	allowedToAddresses   map[common.Address]bool
+	maxValue             *big.Int
+	allowedEip712Domains map[common.Hash]bool
+	logger               logging.Logger
+}
+
+func NewDaemon(signer *LocalSigner, chainId *big.Int, policy DaemonPolicy, logger logging.Logger) *Daemon {
+	var allowedToAddresses map[common.Address]bool
+	if len(policy.AllowedToAddresses) > 0 {
+		allowedToAddresses = map[common.Address]bool{signer.Address(): true}
+		for _, address := range policy.AllowedToAddresses {
+			allowedToAddresses[address] = true
+		}
+	}
+
+	allowedEip712Domains := make(map[common.Hash]bool, len(policy.AllowedEip712Domains))
+	for _, domain := range policy.AllowedEip712Domains {
+		allowedEip712Domains[domain] = true
+	}
+
+	return &Daemon{
+		signer:               signer,
+		publicKey:            encodePublicKey(crypto.FromECDSAPub(&signer.privateKey.PublicKey)),
+		chainId:              chainId,
+		allowedToAddresses:   allowedToAddresses,
+		maxValue:             policy.MaxValue,
+		allowedEip712Domains: allowedEip712Domains,
+		logger:               logger,
+	}
+}
+
+// RegisterHandlers adds the endpoints of the daemon to the mux
+func (d *Daemon) RegisterHandlers(mux *http.ServeMux) {
+	mux.HandleFunc(web3SignerUpcheckPath, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "OK")
+	})
+	mux.HandleFunc(web3SignerPublicKeysPath, d.handlePublicKeys)
+	mux.HandleFunc(web3SignerSignPath, d.handleSignData)
+	mux.HandleFunc(web3SignerJsonRpcPath, d.handleJsonRpc)
+}
+
+func (d *Daemon) handlePublicKeys(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode([]string{d.publicKey})
+}
+
+func (d *Daemon) handleSignData(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !strings.EqualFold(strings.TrimPrefix(r.URL.Path, web3SignerSignPath), d.publicKey) {
+		http.Error(w, "unknown key", http.StatusNotFound)
+		return
+	}
+
+	var request signDataRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, daemonMaxRequestBytes)).Decode(&request); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if err := d.checkSignData(request.Data); err != nil {
+		d.logger.Warn("Refused to sign data", "hash", crypto.Keccak256Hash(request.Data).Hex(), "err", err)
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	signature, err := d.signer.SignData(r.Context(), request.Data)
+	if err != nil {
+		d.logger.Error("Could not sign data", "err", err)
+		http.Error(w, "could not sign", http.StatusInternalServerError)
+		return
+	}
+	// Web3Signer returns V as 27 or 28
+	signature[crypto.RecoveryIDOffset] += 27
+	d.logger.Info("Signed data", "hash", crypto.Keccak256Hash(request.Data).Hex())
+	_, _ = io.WriteString(w, hexutil.Encode(signature))
+}
+
+func (d *Daemon) handleJsonRpc(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != web3SignerJsonRpcPath || r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+
+	var request types.JsonRpcRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, daemonMaxRequestBytes)).Decode(&request); err != nil {
+		d.writeJsonRpcResponse(w, nil, nil, types.NewJsonRpcError(types.JsonRpcParseError, "invalid request"))
+		return
+	}
+	if request.Method != web3SignerSignTransaction {
+		d.writeJsonRpcResponse(w, request.Id, nil, types.NewJsonRpcError(types.JsonRpcMethodNotFound, "method not found"))
+		return
+	}
+
+	var params []signTransactionParams
+	if err := json.Unmarshal(request.Params, &params); err != nil || len(params) != 1 {
+		d.writeJsonRpcResponse(w, request.Id, nil, types.NewJsonRpcError(types.JsonRpcInvalidParams, "invalid params"))
+		return
+	}
+	tx, err := d.transaction(params[0])
+	if err == nil {
+		err = d.checkPolicy(tx)
+	}
+	if err != nil {
+		d.logger.Warn("Refused to sign transaction", "to", params[0].To, "err", err)
+		d.writeJsonRpcResponse(w, request.Id, nil, types.NewJsonRpcError(types.JsonRpcInvalidParams, err.Error()))
+		return
+	}
+
+	signedTx, err := d.signer.SignTx(r.Context(), tx)
+	if err != nil {
+		d.logger.Error("Could not sign transaction", "err", err)
+		d.writeJsonRpcResponse(w, request.Id, nil, types.NewJsonRpcError(types.JsonRpcInternalError, "could not sign"))
+		return
+	}
+	rawTx, err := signedTx.MarshalBinary()
+	if err != nil {
+		d.writeJsonRpcResponse(w, request.Id, nil, types.NewJsonRpcError(types.JsonRpcInternalError, "could not encode transaction"))
+		return
+	}
+	d.logger.Info("Signed transaction", "txHash", signedTx.Hash().Hex(), "nonce", signedTx.Nonce(), "to", signedTx.To())
+	d.writeJsonRpcResponse(w, request.Id, hexutil.Bytes(rawTx), nil)
+}
+
+// transaction builds the transaction of the eth_signTransaction params
+func (d *Daemon) transaction(params signTransactionParams) (*gethtypes.Transaction, error) {
+	if params.From != d.signer.Address() {
+		return nil, errors.New("unknown from address")
+	}
+	value := new(big.Int)
+	if params.Value != nil {
+		value = params.Value.ToInt()
+	}
+
+	if params.MaxFeePerGas != nil && params.MaxPriorityFeePerGas != nil {
+		return gethtypes.NewTx(&gethtypes.DynamicFeeTx{
+			ChainID:   d.chainId,
+			Nonce:     uint64(params.Nonce),
+			GasTipCap: params.MaxPriorityFeePerGas.ToInt(),
+			GasFeeCap: params.MaxFeePerGas.ToInt(),
+			Gas:       uint64(params.Gas),
+			To:        params.To,
+			Value:     value,
+			Data:      params.Data,
+		}), nil
+	}
+	if params.GasPrice != nil {
+		return gethtypes.NewTx(&gethtypes.LegacyTx{
+			Nonce:    uint64(params.Nonce),
+			GasPrice: params.GasPrice.ToInt(),
+			Gas:      uint64(params.Gas),
+			To:       params.To,
+			Value:    value,
+			Data:     params.Data,
+		}), nil
+	}
+	return nil, errors.New("missing gas price")
+}
+
+// checkSignData returns an error unless the data is an aggregator request for the chain of the
+// daemon or an EIP-712 message of an allowed domain. Any other data could be the signing payload
+// of a transaction or a permit, bypassing the policy of the transactions.
+func (d *Daemon) checkSignData(data []byte) error {
+	if types.IsRequestSigningData(data, d.chainId) {
+		return nil
+	}
+	// EIP-712 messages are 0x1901 followed by the domain separator and the hash of the message
+	if len(data) == 2+2*common.HashLength && data[0] == 0x19 && data[1] == 0x01 {
+		if d.allowedEip712Domains[common.BytesToHash(data[2:2+common.HashLength])] {
+			return nil
+		}
+		return errors.New("eip-712 domain not allowed")
+	}
+	return errors.New("data is not an aggregator request")
+}
+
+// checkPolicy returns an error if the transaction is not allowed by the policy of the daemon
+func (d *Daemon) checkPolicy(tx *gethtypes.Transaction) error {
+	if d.allowedToAddresses != nil && (tx.To() == nil || !d.allowedToAddresses[*tx.To()]) {
+		return errors.New("to address not allowed")
+	}
+	if d.maxValue != nil && tx.Value().Cmp(d.maxValue) > 0 {
+		return errors.New("value over the max allowed")
+	}
+	return nil
+}
+
+func (d *Daemon) writeJsonRpcResponse(w http.ResponseWriter, id json.RawMessage, result any, rpcErr *types.JsonRpcError) {
+	response := types.JsonRpcResponse{JsonRpc: types.JsonRpcVersion, Error: rpcErr, Id: id}
+	if rpcErr == nil {
+		encodedResult, err := json.Marshal(result)
+		if err != nil {
+			response.Error = types.NewJsonRpcError(types.JsonRpcInternalError, "could not encode result")
+		} else {
+			response.Result = encodedResult
+		}
+	}
+	if response.Id == nil {
+		response.Id = json.RawMessage("null")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
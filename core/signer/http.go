@@ -0,0 +1,102 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Urls of signers reached over a unix socket are this prefix followed by the socket path
+	UnixSocketUrlPrefix        = "unix://"
+	DefaultRemoteSignerTimeout = 10 * time.Second
+	// Responses of the signers are small, bigger ones are not read
+	remoteSignerMaxResponseBytes = 1 << 20
+)
+
+// newSignerHttpClient returns the base url and the client for the requests to a signer,
+// which is either an http(s) url or UnixSocketUrlPrefix followed by the path of a unix socket
+func newSignerHttpClient(url string, timeout time.Duration) (string, *http.Client) {
+	baseUrl := strings.TrimSuffix(url, "/")
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if socketPath, ok := strings.CutPrefix(url, UnixSocketUrlPrefix); ok {
+		baseUrl = "http://signer"
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}
+	}
+	return baseUrl, &http.Client{Timeout: timeout, Transport: transport}
+}
+
+var ErrInsecureSignerUrl = errors.New("signer url must use https, a unix socket or plain http to localhost")
+
+// CheckSecureSignerUrl returns an error unless the signer is reached over https, a unix
+// socket or plain http on the loopback interface, so the requests can't be read or changed
+func CheckSecureSignerUrl(signerUrl string) error {
+	if strings.HasPrefix(signerUrl, UnixSocketUrlPrefix) {
+		return nil
+	}
+	parsedUrl, err := url.Parse(signerUrl)
+	if err != nil {
+		return fmt.Errorf("invalid signer url: %w", err)
+	}
+	switch parsedUrl.Scheme {
+	case "https":
+		return nil
+	case "http":
+		host := parsedUrl.Hostname()
+		if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+			return nil
+		}
+		return fmt.Errorf("%w: %s", ErrInsecureSignerUrl, signerUrl)
+	default:
+		return fmt.Errorf("%w: unsupported scheme %s", ErrInsecureSignerUrl, parsedUrl.Scheme)
+	}
+}
+
+func signerGet(ctx context.Context, httpClient *http.Client, url string, result any) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	body, err := signerDo(httpClient, request)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, result)
+}
+
+func signerPost(ctx context.Context, httpClient *http.Client, url string, body []byte) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	return signerDo(httpClient, request)
+}
+
+func signerDo(httpClient *http.Client, request *http.Request) ([]byte, error) {
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, remoteSignerMaxResponseBytes))
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected http status from the remote signer: %s %s", response.Status, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
@@ -0,0 +1,99 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"time"
+
+	eigensigner "github.com/Layr-Labs/eigensdk-go/signer"
+	"github.com/Layr-Labs/eigensdk-go/signerv2"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Max time a transaction signature can take, since the bind signer function has no context
+const txSignTimeout = 30 * time.Second
+
+var ErrSendNotSupported = errors.New("signer does not send transactions")
+
+// Signer signs with an ECDSA key, which a remote signer can keep out of the process.
+// It is also an eigensdk signer, so it can be used to send transactions.
+type Signer interface {
+	eigensigner.Signer
+	// Address of the key
+	Address() common.Address
+	// SignData signs the keccak256 hash of the data, returning a [R || S || V] signature with V 0 or 1
+	SignData(ctx context.Context, data []byte) ([]byte, error)
+	// SignTx signs the transaction for the chain of the signer
+	SignTx(ctx context.Context, tx *types.Transaction) (*types.Transaction, error)
+}
+
+// LocalSigner signs with a private key held in memory, read from a local keystore
+type LocalSigner struct {
+	privateKey *ecdsa.PrivateKey
+	address    common.Address
+	txSigner   types.Signer
+}
+
+func NewLocalSigner(privateKey *ecdsa.PrivateKey, chainId *big.Int) *LocalSigner {
+	return &LocalSigner{
+		privateKey: privateKey,
+		address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+		txSigner:   types.LatestSignerForChainID(chainId),
+	}
+}
+
+func (s *LocalSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *LocalSigner) SignData(ctx context.Context, data []byte) ([]byte, error) {
+	return crypto.Sign(crypto.Keccak256(data), s.privateKey)
+}
+
+func (s *LocalSigner) SignTx(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	return types.SignTx(tx, s.txSigner, s.privateKey)
+}
+
+func (s *LocalSigner) GetTxOpts() *bind.TransactOpts {
+	return txOpts(s)
+}
+
+func (s *LocalSigner) SendToExternal(ctx context.Context, tx *types.Transaction) (common.Hash, error) {
+	return common.Hash{}, ErrSendNotSupported
+}
+
+// txOpts signs the transactions of the bindings with the signer
+func txOpts(s Signer) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From: s.Address(),
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != s.Address() {
+				return nil, bind.ErrNotAuthorized
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), txSignTimeout)
+			defer cancel()
+			return s.SignTx(ctx, tx)
+		},
+	}
+}
+
+// SignerFn returns the signer function used by the eigensdk wallets
+func SignerFn(s Signer) signerv2.SignerFn {
+	return func(ctx context.Context, address common.Address) (bind.SignerFn, error) {
+		return txOpts(s).Signer, nil
+	}
+}
+
+// RecoverAddress returns the address whose key signed the keccak256 hash of the data
+func RecoverAddress(data []byte, signature []byte) (common.Address, error) {
+	publicKey, err := crypto.SigToPub(crypto.Keccak256(data), signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
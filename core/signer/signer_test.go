@@ -0,0 +1,203 @@
+package signer_test
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/yetanotherco/aligned_layer/core/signer"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+func newTestLocalSigner(t *testing.T, chainId *big.Int) *signer.LocalSigner {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer.NewLocalSigner(privateKey, chainId)
+}
+
+// startTestDaemon serves the daemon over a unix socket, returning the url of the socket
+func startTestDaemon(t *testing.T, localSigner *signer.LocalSigner, chainId *big.Int, policy signer.DaemonPolicy) string {
+	socketPath := filepath.Join(t.TempDir(), "signer.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	signer.NewDaemon(localSigner, chainId, policy, logging.NewNoopLogger()).RegisterHandlers(mux)
+	server := &http.Server{Handler: mux}
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() { _ = server.Close() })
+	return "unix://" + socketPath
+}
+
+func TestRemoteSignerSignsWithDaemonKey(t *testing.T) {
+	chainId := big.NewInt(17000)
+	localSigner := newTestLocalSigner(t, chainId)
+	url := startTestDaemon(t, localSigner, chainId, signer.DaemonPolicy{})
+
+	remoteSigner, err := signer.NewRemoteSigner(context.Background(), url, localSigner.Address(), chainId, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := &types.SignedTaskResponseParams{BatchMerkleRoot: [32]byte{1}, Timestamp: 10}
+	data := params.SigningData(types.RequestDomain{ChainId: chainId, ServiceManagerAddress: common.HexToAddress("0x2")})
+	signature, err := remoteSigner.SignData(context.Background(), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, err := signer.RecoverAddress(data, signature)
+	if err != nil || address != localSigner.Address() {
+		t.Fatalf("signature recovers %s instead of %s", address.Hex(), localSigner.Address().Hex())
+	}
+
+	to := common.HexToAddress("0x1")
+	tx := gethtypes.NewTx(&gethtypes.DynamicFeeTx{
+		ChainID:   chainId,
+		Nonce:     3,
+		GasTipCap: big.NewInt(1_000),
+		GasFeeCap: big.NewInt(30_000),
+		Gas:       21_000,
+		To:        &to,
+		Value:     big.NewInt(5),
+		Data:      []byte{1, 2, 3},
+	})
+	signedTx, err := remoteSigner.GetTxOpts().Signer(localSigner.Address(), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txSigner := gethtypes.LatestSignerForChainID(chainId)
+	sender, err := gethtypes.Sender(txSigner, signedTx)
+	if err != nil || sender != localSigner.Address() {
+		t.Fatalf("transaction signed by %s instead of %s", sender.Hex(), localSigner.Address().Hex())
+	}
+	if txSigner.Hash(signedTx) != txSigner.Hash(tx) {
+		t.Error("signed transaction differs from the requested one")
+	}
+}
+
+func TestRemoteSignerRequiresKeyOfAddress(t *testing.T) {
+	chainId := big.NewInt(17000)
+	localSigner := newTestLocalSigner(t, chainId)
+	url := startTestDaemon(t, localSigner, chainId, signer.DaemonPolicy{})
+
+	otherAddress := newTestLocalSigner(t, chainId).Address()
+	if _, err := signer.NewRemoteSigner(context.Background(), url, otherAddress, chainId, time.Second); err == nil {
+		t.Fatal("expected an error for an address whose key the signer does not hold")
+	}
+}
+
+func TestRemoteSignerRejectsTransactionsForOtherChains(t *testing.T) {
+	localSigner := newTestLocalSigner(t, big.NewInt(1))
+	// The daemon signs for another chain than the services
+	mux := http.NewServeMux()
+	signer.NewDaemon(localSigner, big.NewInt(1), signer.DaemonPolicy{}, logging.NewNoopLogger()).RegisterHandlers(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	chainId := big.NewInt(17000)
+	remoteSigner, err := signer.NewRemoteSigner(context.Background(), server.URL, localSigner.Address(), chainId, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := gethtypes.NewTx(&gethtypes.DynamicFeeTx{ChainID: chainId, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Gas: 21_000})
+	if _, err = remoteSigner.SignTx(context.Background(), tx); err == nil {
+		t.Fatal("expected a transaction signed for another chain to be rejected")
+	}
+}
+
+func TestDaemonPolicyRestrictsTransactions(t *testing.T) {
+	chainId := big.NewInt(17000)
+	localSigner := newTestLocalSigner(t, chainId)
+	allowed := common.HexToAddress("0x1")
+	url := startTestDaemon(t, localSigner, chainId, signer.DaemonPolicy{AllowedToAddresses: []common.Address{allowed}, MaxValue: big.NewInt(10)})
+
+	remoteSigner, err := signer.NewRemoteSigner(context.Background(), url, localSigner.Address(), chainId, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signTx := func(to *common.Address, value int64) error {
+		tx := gethtypes.NewTx(&gethtypes.DynamicFeeTx{ChainID: chainId, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Gas: 21_000, To: to, Value: big.NewInt(value)})
+		_, err := remoteSigner.SignTx(context.Background(), tx)
+		return err
+	}
+
+	ownAddress := localSigner.Address()
+	if err = signTx(&allowed, 10); err != nil {
+		t.Fatalf("expected a transaction to an allowed address to be signed, got %v", err)
+	}
+	if err = signTx(&ownAddress, 0); err != nil {
+		t.Fatalf("expected a transaction to the own address to be signed, got %v", err)
+	}
+	other := common.HexToAddress("0x2")
+	if err = signTx(&other, 0); err == nil {
+		t.Error("expected a transaction to another address to be rejected")
+	}
+	if err = signTx(nil, 0); err == nil {
+		t.Error("expected a contract creation to be rejected")
+	}
+	if err = signTx(&allowed, 11); err == nil {
+		t.Error("expected a transaction over the max value to be rejected")
+	}
+}
+
+func TestDaemonOnlySignsRequestsAndAllowedEip712Messages(t *testing.T) {
+	chainId := big.NewInt(17000)
+	localSigner := newTestLocalSigner(t, chainId)
+	allowedDomain := common.Hash{7}
+	url := startTestDaemon(t, localSigner, chainId, signer.DaemonPolicy{AllowedEip712Domains: []common.Hash{allowedDomain}})
+
+	remoteSigner, err := signer.NewRemoteSigner(context.Background(), url, localSigner.Address(), chainId, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The signing payload of a transaction, whose hash is the one signed for the transaction
+	to := common.HexToAddress("0x1")
+	tx := gethtypes.NewTx(&gethtypes.LegacyTx{Nonce: 1, GasPrice: big.NewInt(2), Gas: 21_000, To: &to, Value: big.NewInt(1_000)})
+	txPayload, err := rlp.EncodeToBytes([]any{tx.Nonce(), tx.GasPrice(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), chainId, uint(0), uint(0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crypto.Keccak256Hash(txPayload) != gethtypes.LatestSignerForChainID(chainId).Hash(tx) {
+		t.Fatal("the payload is not the signing payload of the transaction")
+	}
+
+	eip712Message := func(domain common.Hash) []byte {
+		return append(append([]byte{0x19, 0x01}, domain[:]...), common.Hash{8}.Bytes()...)
+	}
+	otherChainRequest := (&types.SignedTaskResponseParams{}).SigningData(types.RequestDomain{ChainId: big.NewInt(1)})
+
+	tests := []struct {
+		name   string
+		data   []byte
+		signed bool
+	}{
+		{"request", (&types.SignedTaskResponseParams{}).SigningData(types.RequestDomain{ChainId: chainId}), true},
+		{"allowed eip-712 domain", eip712Message(allowedDomain), true},
+		{"transaction", txPayload, false},
+		{"other eip-712 domain", eip712Message(common.Hash{9}), false},
+		{"request for another chain", otherChainRequest, false},
+		{"arbitrary data", []byte("aggregator_submitSignedTaskResponse"), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := remoteSigner.SignData(context.Background(), test.data)
+			if (err == nil) != test.signed {
+				t.Fatalf("expected signed %v, got %v", test.signed, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package signer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+// Endpoints of the Web3Signer eth1 API used by the remote signer
+const (
+	web3SignerPublicKeysPath = "/api/v1/eth1/publicKeys"
+	web3SignerSignPath       = "/api/v1/eth1/sign/"
+	web3SignerUpcheckPath    = "/upcheck"
+	// eth_signTransaction is served by the JSON-RPC endpoint in the root path
+	web3SignerJsonRpcPath     = "/"
+	web3SignerSignTransaction = "eth_signTransaction"
+)
+
+// signTransactionParams are the params of eth_signTransaction
+type signTransactionParams struct {
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to,omitempty"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas,omitempty"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Value                *hexutil.Big    `json:"value"`
+	Data                 hexutil.Bytes   `json:"data"`
+}
+
+// signDataRequest is the body of the Web3Signer eth1 sign endpoint
+type signDataRequest struct {
+	Data hexutil.Bytes `json:"data"`
+}
+
+// RemoteSigner signs with a key held by a Web3Signer compatible signer, either a remote
+// one reached over HTTP or the local signing daemon reached over its unix socket
+type RemoteSigner struct {
+	baseUrl    string
+	httpClient *http.Client
+	address    common.Address
+	// Web3Signer identifies the keys by their public key
+	publicKey string
+	txSigner  gethtypes.Signer
+	chainId   *big.Int
+	nextId    atomic.Uint64
+}
+
+// NewRemoteSigner connects to the signer at the url, which is either an http(s) url or
+// unix:// followed by the path of a unix socket, and checks it holds the key of the address
+func NewRemoteSigner(ctx context.Context, url string, address common.Address, chainId *big.Int, timeout time.Duration) (*RemoteSigner, error) {
+	baseUrl, httpClient := newSignerHttpClient(url, timeout)
+	s := &RemoteSigner{
+		baseUrl:    baseUrl,
+		httpClient: httpClient,
+		address:    address,
+		txSigner:   gethtypes.LatestSignerForChainID(chainId),
+		chainId:    chainId,
+	}
+
+	var publicKeys []string
+	if err := signerGet(ctx, s.httpClient, s.baseUrl+web3SignerPublicKeysPath, &publicKeys); err != nil {
+		return nil, fmt.Errorf("could not list the keys of the remote signer: %w", err)
+	}
+	for _, publicKey := range publicKeys {
+		keyAddress, err := publicKeyAddress(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %s listed by the remote signer: %w", publicKey, err)
+		}
+		if keyAddress == address {
+			s.publicKey = publicKey
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("remote signer does not hold the key of %s", address.Hex())
+}
+
+func (s *RemoteSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *RemoteSigner) SignData(ctx context.Context, data []byte) ([]byte, error) {
+	body, err := json.Marshal(signDataRequest{Data: data})
+	if err != nil {
+		return nil, err
+	}
+	response, err := signerPost(ctx, s.httpClient, s.baseUrl+web3SignerSignPath+s.publicKey, body)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := hexutil.Decode(strings.TrimSpace(string(response)))
+	if err != nil || len(signature) != crypto.SignatureLength {
+		return nil, fmt.Errorf("invalid signature returned by the remote signer: %s", response)
+	}
+	// Web3Signer returns V as 27 or 28
+	if signature[crypto.RecoveryIDOffset] >= 27 {
+		signature[crypto.RecoveryIDOffset] -= 27
+	}
+
+	signer, err := RecoverAddress(data, signature)
+	if err != nil || signer != s.address {
+		return nil, fmt.Errorf("remote signer signed with a different key than %s", s.address.Hex())
+	}
+	return signature, nil
+}
+
+func (s *RemoteSigner) SignTx(ctx context.Context, tx *gethtypes.Transaction) (*gethtypes.Transaction, error) {
+	params := signTransactionParams{
+		From:  s.address,
+		To:    tx.To(),
+		Gas:   hexutil.Uint64(tx.Gas()),
+		Nonce: hexutil.Uint64(tx.Nonce()),
+		Value: (*hexutil.Big)(tx.Value()),
+		Data:  tx.Data(),
+	}
+	if tx.Type() == gethtypes.LegacyTxType {
+		params.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	} else {
+		params.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		params.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	}
+
+	encodedParams, err := json.Marshal([]signTransactionParams{params})
+	if err != nil {
+		return nil, err
+	}
+	request, err := json.Marshal(types.JsonRpcRequest{
+		JsonRpc: types.JsonRpcVersion,
+		Method:  web3SignerSignTransaction,
+		Params:  encodedParams,
+		Id:      json.RawMessage(fmt.Sprint(s.nextId.Add(1))),
+	})
+	if err != nil {
+		return nil, err
+	}
+	body, err := signerPost(ctx, s.httpClient, s.baseUrl+web3SignerJsonRpcPath, request)
+	if err != nil {
+		return nil, err
+	}
+
+	var response types.JsonRpcResponse
+	if err = json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("remote signer refused to sign the transaction: %w", response.Error)
+	}
+	var rawTx hexutil.Bytes
+	if err = json.Unmarshal(response.Result, &rawTx); err != nil {
+		return nil, err
+	}
+	signedTx := new(gethtypes.Transaction)
+	if err = signedTx.UnmarshalBinary(rawTx); err != nil {
+		return nil, fmt.Errorf("invalid transaction returned by the remote signer: %w", err)
+	}
+
+	// The signer could be configured for another chain, or sign other fields than the requested
+	if signedTx.ChainId().Cmp(s.chainId) != 0 || s.txSigner.Hash(signedTx) != s.txSigner.Hash(tx) {
+		return nil, fmt.Errorf("remote signer signed a different transaction than requested")
+	}
+	sender, err := gethtypes.Sender(s.txSigner, signedTx)
+	if err != nil || sender != s.address {
+		return nil, fmt.Errorf("remote signer signed with a different key than %s", s.address.Hex())
+	}
+	return signedTx, nil
+}
+
+func (s *RemoteSigner) GetTxOpts() *bind.TransactOpts {
+	return txOpts(s)
+}
+
+func (s *RemoteSigner) SendToExternal(ctx context.Context, tx *gethtypes.Transaction) (common.Hash, error) {
+	return common.Hash{}, ErrSendNotSupported
+}
+
+// publicKeyAddress returns the address of a hex public key, with or without the 0x04 prefix
+func publicKeyAddress(publicKey string) (common.Address, error) {
+	publicKeyBytes, err := hexutil.Decode(publicKey)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(publicKeyBytes) == 64 {
+		publicKeyBytes = append([]byte{4}, publicKeyBytes...)
+	}
+	key, err := crypto.UnmarshalPubkey(publicKeyBytes)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*key), nil
+}
+
+// encodePublicKey encodes the public key as Web3Signer does, without the 0x04 prefix
+func encodePublicKey(publicKey []byte) string {
+	return hexutil.Encode(publicKey[1:])
+}
@@ -0,0 +1,252 @@
+package types
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Aggregator JSON-RPC 2.0 API, served over HTTP POST at AggregatorJsonRpcPath.
+// Byte fields are 0x prefixed hex strings, so it can be used from any language.
+// Requests are signed with the ECDSA key of the operator registered address,
+// over the keccak256 hash of the data returned by the SigningData method of their params.
+const AggregatorJsonRpcPath = "/aggregator/v1"
+
+// Aggregator JSON-RPC methods
+const (
+	MethodSubmitSignedTaskResponse  = "aggregator_submitSignedTaskResponse"
+	MethodReportVerificationFailure = "aggregator_reportVerificationFailure"
+)
+
+// Aggregator JSON-RPC application error codes. The outcome of a submitted
+// signature is given by the status of its result instead.
+const (
+	// The aggregator is shutting down and does not accept requests
+	AggregatorErrShuttingDown = 1003
+	// The operator id is not registered in the AVS
+	AggregatorErrOperatorNotRegistered = 1004
+	// The request signature is missing or not from the registered operator address
+	AggregatorErrInvalidSignature = 1005
+)
+
+// SignedTaskResponseParams are the params of MethodSubmitSignedTaskResponse
+type SignedTaskResponseParams struct {
+	BatchMerkleRoot ethcommon.Hash `json:"batch_merkle_root"`
+	OperatorId      ethcommon.Hash `json:"operator_id"`
+	// Compressed BN254 G1 point
+	BlsSignature hexutil.Bytes `json:"bls_signature"`
+	// Unix time in seconds the request was signed at
+	Timestamp uint64 `json:"timestamp,omitempty"`
+	// ECDSA signature of the SigningHash, as [R || S || V]
+	Signature hexutil.Bytes `json:"signature,omitempty"`
+}
+
+// TaskResponseStatus is the outcome of a signed task response sent to the aggregator
+type TaskResponseStatus string
+
+const (
+	// The signature was added to the aggregation of the task
+	TaskResponseAccepted TaskResponseStatus = "accepted"
+	// The operator signature was already added to the aggregation of the task
+	TaskResponseDuplicate TaskResponseStatus = "duplicate"
+	// The aggregator did not receive the batch event yet
+	TaskResponseTaskUnknownYet TaskResponseStatus = "task_unknown_yet"
+	// The task expired or was already responded
+	TaskResponseTaskExpired TaskResponseStatus = "task_expired"
+	// The BLS signature or the request signature is not valid
+	TaskResponseInvalidSignature TaskResponseStatus = "invalid_signature"
+	// The operator is not registered in the AVS or not part of the task quorum
+	TaskResponseOperatorNotRegistered TaskResponseStatus = "operator_not_registered"
+	// The aggregator could not process the signature
+	TaskResponseInternal TaskResponseStatus = "internal"
+)
+
+// SignedTaskResponseResult is the result of MethodSubmitSignedTaskResponse
+type SignedTaskResponseResult struct {
+	Status TaskResponseStatus `json:"status"`
+	// Seconds to wait before sending the response again. Zero if sending it again
+	// would get the same answer.
+	RetryAfter uint32 `json:"retry_after,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
+
+// Delivered is true if the aggregator has the operator signature
+func (r *SignedTaskResponseResult) Delivered() bool {
+	return r.Status == TaskResponseAccepted || r.Status == TaskResponseDuplicate
+}
+
+// RetryAfterDuration returns the time to wait before sending the response again,
+// or zero if it must not be sent again
+func (r *SignedTaskResponseResult) RetryAfterDuration() time.Duration {
+	if r.Delivered() {
+		return 0
+	}
+	return time.Duration(r.RetryAfter) * time.Second
+}
+
+// VerificationFailureParams are the params of MethodReportVerificationFailure
+type VerificationFailureParams struct {
+	BatchMerkleRoot ethcommon.Hash `json:"batch_merkle_root"`
+	OperatorId      ethcommon.Hash `json:"operator_id"`
+	BatchIndex      uint32         `json:"batch_index"`
+	ProvingSystem   string         `json:"proving_system"`
+	Reason          string         `json:"reason"`
+	Message         string         `json:"message"`
+	// Unix time in seconds the request was signed at
+	Timestamp uint64 `json:"timestamp,omitempty"`
+	// ECDSA signature of the SigningHash, as [R || S || V]
+	Signature hexutil.Bytes `json:"signature,omitempty"`
+}
+
+// VerificationFailureResult is the result of MethodReportVerificationFailure
+type VerificationFailureResult struct{}
+
+func NewSignedTaskResponseParams(signedTaskResponse *SignedTaskResponse) (*SignedTaskResponseParams, error) {
+	if signedTaskResponse.BlsSignature.G1Point == nil || signedTaskResponse.BlsSignature.G1Affine == nil {
+		return nil, errors.New("missing bls signature")
+	}
+	signature := signedTaskResponse.BlsSignature.G1Affine.Bytes()
+
+	return &SignedTaskResponseParams{
+		BatchMerkleRoot: signedTaskResponse.BatchMerkleRoot,
+		OperatorId:      ethcommon.Hash(signedTaskResponse.OperatorId),
+		BlsSignature:    signature[:],
+	}, nil
+}
+
+// SignedTaskResponse decodes the params, checking the signature is a valid G1 point
+func (p *SignedTaskResponseParams) SignedTaskResponse() (*SignedTaskResponse, error) {
+	point := new(bn254.G1Affine)
+	n, err := point.SetBytes(p.BlsSignature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bls signature: %w", err)
+	}
+	if n != len(p.BlsSignature) {
+		return nil, errors.New("invalid bls signature: trailing bytes")
+	}
+
+	return &SignedTaskResponse{
+		BatchMerkleRoot: p.BatchMerkleRoot,
+		BlsSignature:    bls.Signature{G1Point: &bls.G1Point{G1Affine: point}},
+		OperatorId:      eigentypes.OperatorId(p.OperatorId),
+	}, nil
+}
+
+// SigningData is the domain, the method name, the timestamp and the request fields
+func (p *SignedTaskResponseParams) SigningData(domain RequestDomain) []byte {
+	return bytes.Join([][]byte{
+		domain.signingData(),
+		[]byte(MethodSubmitSignedTaskResponse),
+		binary.BigEndian.AppendUint64(nil, p.Timestamp),
+		p.BatchMerkleRoot[:],
+		p.OperatorId[:],
+		p.BlsSignature,
+	}, nil)
+}
+
+// SigningHash is the keccak256 of the SigningData
+func (p *SignedTaskResponseParams) SigningHash(domain RequestDomain) ethcommon.Hash {
+	return crypto.Keccak256Hash(p.SigningData(domain))
+}
+
+func NewVerificationFailureParams(report *VerificationFailureReport) *VerificationFailureParams {
+	return &VerificationFailureParams{
+		BatchMerkleRoot: report.BatchMerkleRoot,
+		OperatorId:      ethcommon.Hash(report.OperatorId),
+		BatchIndex:      report.BatchIndex,
+		ProvingSystem:   report.ProvingSystem,
+		Reason:          report.Reason,
+		Message:         report.Message,
+	}
+}
+
+func (p *VerificationFailureParams) VerificationFailureReport() *VerificationFailureReport {
+	return &VerificationFailureReport{
+		BatchMerkleRoot: p.BatchMerkleRoot,
+		OperatorId:      eigentypes.OperatorId(p.OperatorId),
+		BatchIndex:      p.BatchIndex,
+		ProvingSystem:   p.ProvingSystem,
+		Reason:          p.Reason,
+		Message:         p.Message,
+	}
+}
+
+// SigningData is the domain, the method name, the timestamp and the request fields,
+// with the strings prefixed by their big endian uint32 length
+func (p *VerificationFailureParams) SigningData(domain RequestDomain) []byte {
+	return bytes.Join([][]byte{
+		domain.signingData(),
+		[]byte(MethodReportVerificationFailure),
+		binary.BigEndian.AppendUint64(nil, p.Timestamp),
+		p.BatchMerkleRoot[:],
+		p.OperatorId[:],
+		binary.BigEndian.AppendUint32(nil, p.BatchIndex),
+		lengthPrefixed(p.ProvingSystem),
+		lengthPrefixed(p.Reason),
+		lengthPrefixed(p.Message),
+	}, nil)
+}
+
+// SigningHash is the keccak256 of the SigningData
+func (p *VerificationFailureParams) SigningHash(domain RequestDomain) ethcommon.Hash {
+	return crypto.Keccak256Hash(p.SigningData(domain))
+}
+
+// Prefix of the signed data of every request, so it can't be taken for another kind of signed message
+const requestDomainTag = "aligned-aggregator-request"
+
+// RequestDomain is the deployment a request is meant for. It is part of the signed data,
+// so a request signature can't be replayed against the aggregator of another chain or AVS
+type RequestDomain struct {
+	ChainId               *big.Int
+	ServiceManagerAddress ethcommon.Address
+}
+
+func (d RequestDomain) signingData() []byte {
+	chainId := new(big.Int)
+	if d.ChainId != nil {
+		chainId = d.ChainId
+	}
+	return bytes.Join([][]byte{
+		[]byte(requestDomainTag),
+		ethcommon.LeftPadBytes(chainId.Bytes(), 32),
+		d.ServiceManagerAddress[:],
+	}, nil)
+}
+
+// IsRequestSigningData returns true if the data is the signing data of a request for the
+// chain, so signers can refuse to sign other messages with the operator key
+func IsRequestSigningData(data []byte, chainId *big.Int) bool {
+	// The service manager address is not checked, the operator may serve several AVSs
+	prefix := RequestDomain{ChainId: chainId}.signingData()[:len(requestDomainTag)+32]
+	return len(data) > len(prefix) && bytes.Equal(data[:len(prefix)], prefix)
+}
+
+func lengthPrefixed(value string) []byte {
+	return append(binary.BigEndian.AppendUint32(nil, uint32(len(value))), value...)
+}
+
+// SignRequest signs the hash of the request params with the operator ECDSA key
+func SignRequest(signingHash ethcommon.Hash, privateKey *ecdsa.PrivateKey) ([]byte, error) {
+	return crypto.Sign(signingHash[:], privateKey)
+}
+
+// RecoverRequestSigner returns the address whose key signed the hash of the request params
+func RecoverRequestSigner(signingHash ethcommon.Hash, signature []byte) (ethcommon.Address, error) {
+	publicKey, err := crypto.SigToPub(signingHash[:], signature)
+	if err != nil {
+		return ethcommon.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
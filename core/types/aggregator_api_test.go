@@ -0,0 +1,133 @@
+package types_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+func TestSignedTaskResponseParamsRoundTrip(t *testing.T) {
+	keyPair, err := bls.NewKeyPairFromString("12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+	batchMerkleRoot := [32]byte{1, 2, 3}
+	signedTaskResponse := &types.SignedTaskResponse{
+		BatchMerkleRoot: batchMerkleRoot,
+		BlsSignature:    *keyPair.SignMessage(batchMerkleRoot),
+		OperatorId:      eigentypes.OperatorIdFromKeyPair(keyPair),
+	}
+
+	params, err := types.NewSignedTaskResponseParams(signedTaskResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(encoded), `"batch_merkle_root":"0x0102030000`) {
+		t.Errorf("byte fields are not hex encoded: %s", encoded)
+	}
+
+	var decodedParams types.SignedTaskResponseParams
+	if err = json.Unmarshal(encoded, &decodedParams); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := decodedParams.SignedTaskResponse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.BatchMerkleRoot != signedTaskResponse.BatchMerkleRoot || decoded.OperatorId != signedTaskResponse.OperatorId {
+		t.Errorf("decoded response does not match: %+v", decoded)
+	}
+	if !decoded.BlsSignature.G1Affine.Equal(signedTaskResponse.BlsSignature.G1Affine) {
+		t.Errorf("decoded signature does not match")
+	}
+}
+
+func TestSignedTaskResponseParamsRejectsInvalidSignature(t *testing.T) {
+	params := types.SignedTaskResponseParams{BlsSignature: []byte{1, 2, 3}}
+	if _, err := params.SignedTaskResponse(); err == nil {
+		t.Error("expected invalid signature to be rejected")
+	}
+}
+
+func TestRequestSignatureRecoversOperatorAddress(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	domain := types.RequestDomain{ChainId: big.NewInt(17000), ServiceManagerAddress: ethcommon.Address{5}}
+	params := types.VerificationFailureParams{
+		BatchMerkleRoot: ethcommon.Hash{1},
+		OperatorId:      ethcommon.Hash{2},
+		BatchIndex:      3,
+		ProvingSystem:   "SP1",
+		Reason:          "invalid_proof",
+		Timestamp:       1_700_000_000,
+	}
+	params.Signature, err = types.SignRequest(params.SigningHash(domain), privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := types.RecoverRequestSigner(params.SigningHash(domain), params.Signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer != crypto.PubkeyToAddress(privateKey.PublicKey) {
+		t.Errorf("recovered signer %s does not match the operator address", signer)
+	}
+
+	// The signature covers the domain, the timestamp and every field of the request
+	otherChain := types.RequestDomain{ChainId: big.NewInt(1), ServiceManagerAddress: domain.ServiceManagerAddress}
+	otherServiceManager := types.RequestDomain{ChainId: domain.ChainId, ServiceManagerAddress: ethcommon.Address{6}}
+	for _, otherDomain := range []types.RequestDomain{otherChain, otherServiceManager} {
+		signer, err = types.RecoverRequestSigner(params.SigningHash(otherDomain), params.Signature)
+		if err == nil && signer == crypto.PubkeyToAddress(privateKey.PublicKey) {
+			t.Error("signature valid in another domain")
+		}
+	}
+	params.Timestamp++
+	signer, err = types.RecoverRequestSigner(params.SigningHash(domain), params.Signature)
+	if err == nil && signer == crypto.PubkeyToAddress(privateKey.PublicKey) {
+		t.Error("signature still valid after changing the timestamp")
+	}
+	params.Timestamp--
+	params.BatchIndex = 4
+	signer, err = types.RecoverRequestSigner(params.SigningHash(domain), params.Signature)
+	if err == nil && signer == crypto.PubkeyToAddress(privateKey.PublicKey) {
+		t.Error("signature still valid after changing the request")
+	}
+}
+
+func TestSignedTaskResponseResultRetryAfter(t *testing.T) {
+	var result types.SignedTaskResponseResult
+	if err := json.Unmarshal([]byte(`{"status":"task_unknown_yet","retry_after":4}`), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Delivered() || result.RetryAfterDuration() != 4*time.Second {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	// Delivered signatures must not be sent again, whatever the hint
+	result = types.SignedTaskResponseResult{Status: types.TaskResponseDuplicate, RetryAfter: 4}
+	if !result.Delivered() || result.RetryAfterDuration() != 0 {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	result = types.SignedTaskResponseResult{Status: types.TaskResponseInvalidSignature}
+	if result.Delivered() || result.RetryAfterDuration() != 0 {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
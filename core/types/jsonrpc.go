@@ -0,0 +1,46 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const JsonRpcVersion = "2.0"
+
+// Standard JSON-RPC 2.0 error codes
+const (
+	JsonRpcParseError     = -32700
+	JsonRpcInvalidRequest = -32600
+	JsonRpcMethodNotFound = -32601
+	JsonRpcInvalidParams  = -32602
+	JsonRpcInternalError  = -32603
+)
+
+type JsonRpcRequest struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	// Requests without id are notifications, which are not answered
+	Id json.RawMessage `json:"id,omitempty"`
+}
+
+type JsonRpcResponse struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *JsonRpcError   `json:"error,omitempty"`
+	Id      json.RawMessage `json:"id"`
+}
+
+type JsonRpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func NewJsonRpcError(code int, message string) *JsonRpcError {
+	return &JsonRpcError{Code: code, Message: message}
+}
+
+func (e *JsonRpcError) Error() string {
+	return fmt.Sprintf("json-rpc error %d: %s", e.Code, e.Message)
+}
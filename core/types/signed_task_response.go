@@ -10,3 +10,16 @@ type SignedTaskResponse struct {
 	BlsSignature    bls.Signature
 	OperatorId      eigentypes.OperatorId
 }
+
+// VerificationFailureReport is sent by an operator to the aggregator when it
+// refuses to sign a batch because one of its entries failed to verify
+type VerificationFailureReport struct {
+	BatchMerkleRoot [32]byte
+	OperatorId      eigentypes.OperatorId
+	// Index of the failing entry in the batch
+	BatchIndex    uint32
+	ProvingSystem string
+	// One of the common.Reason* constants
+	Reason  string
+	Message string
+}
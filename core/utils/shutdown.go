@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Exit code of a process that was asked to stop but could not finish its in-flight work
+// in time. Other failures exit with 1.
+const ExitCodeShutdownTimeout = 2
+
+var ErrShutdownTimeout = errors.New("shutdown timed out, in-flight work was abandoned")
+
+// NewShutdownContext returns a context that is cancelled when the process receives SIGINT or SIGTERM
+func NewShutdownContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// WaitWithTimeout waits for the wait group, returning ErrShutdownTimeout if it takes longer than timeout
+func WaitWithTimeout(wg *sync.WaitGroup, timeout time.Duration) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		return ErrShutdownTimeout
+	}
+}
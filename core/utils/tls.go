@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"crypto/x509"
+	"errors"
+	"os"
+)
+
+// LoadCertPool reads a PEM file with one or more CA certificates
+func LoadCertPool(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("no certificates found in " + caFile)
+	}
+	return pool, nil
+}
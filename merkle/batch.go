@@ -0,0 +1,38 @@
+package merkle
+
+import (
+	"github.com/yetanotherco/aligned_layer/common"
+)
+
+func NewVerificationDataCommitment(verificationData common.VerificationData) VerificationDataCommitment {
+	commitment := VerificationDataCommitment{
+		ProofCommitment:    Commit(verificationData.Proof),
+		PubInputCommitment: Commit(verificationData.PubInput),
+		ProofGeneratorAddr: verificationData.ProofGeneratorAddress,
+	}
+
+	if verificationData.VmProgramCode != nil {
+		commitment.ProvingSystemAuxDataCommitment = Commit(verificationData.VmProgramCode)
+	} else {
+		commitment.ProvingSystemAuxDataCommitment = Commit(verificationData.VerificationKey)
+	}
+
+	return commitment
+}
+
+// BatchMerkleTree builds the merkle tree of the batch the same way the batcher does
+func BatchMerkleTree(batch []common.VerificationData) (*MerkleTree, error) {
+	commitments := make([]VerificationDataCommitment, len(batch))
+	for i, verificationData := range batch {
+		commitments[i] = NewVerificationDataCommitment(verificationData)
+	}
+	return NewMerkleTreeFromCommitments(commitments)
+}
+
+func BatchMerkleRoot(batch []common.VerificationData) ([32]byte, error) {
+	tree, err := BatchMerkleTree(batch)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return tree.Root(), nil
+}
@@ -0,0 +1,159 @@
+// Package merkle builds the batch merkle tree used by Aligned, compatible with
+// the one built by the batcher and with AlignedLayerServiceManager.verifyBatchInclusion.
+//
+// Leaves are keccak256(proofCommitment || pubInputCommitment || provingSystemAuxDataCommitment || proofGeneratorAddr).
+// The leaves are padded to the next power of two repeating the last one, and each
+// parent node is keccak256(left || right).
+package merkle
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// VerificationDataCommitment is the data committed in each leaf of the batch merkle tree
+type VerificationDataCommitment struct {
+	ProofCommitment    [32]byte `json:"proof_commitment"`
+	PubInputCommitment [32]byte `json:"pub_input_commitment"`
+	// This could be either the VM code (ELF, bytecode) or the verification key
+	// depending on the proving system.
+	ProvingSystemAuxDataCommitment [32]byte `json:"proving_system_aux_data_commitment"`
+	ProofGeneratorAddr             [20]byte `json:"proof_generator_addr"`
+}
+
+// Commit returns the keccak256 hash of data, or zero if data is nil,
+// which is how the batcher commits to missing fields
+func Commit(data []byte) [32]byte {
+	if data == nil {
+		return [32]byte{}
+	}
+	return crypto.Keccak256Hash(data)
+}
+
+// Hash returns the leaf hash, keccak256(abi.encodePacked(commitments, address))
+func (c VerificationDataCommitment) Hash() [32]byte {
+	return crypto.Keccak256Hash(
+		c.ProofCommitment[:],
+		c.PubInputCommitment[:],
+		c.ProvingSystemAuxDataCommitment[:],
+		c.ProofGeneratorAddr[:],
+	)
+}
+
+// InclusionProof proves that a leaf is part of a tree with a given root
+type InclusionProof struct {
+	// Siblings of the nodes in the path from the leaf to the root, leaf level first
+	MerklePath [][32]byte `json:"merkle_path"`
+}
+
+// Bytes returns the proof as expected by the merkleProof argument of verifyBatchInclusion
+func (p InclusionProof) Bytes() []byte {
+	proofBytes := make([]byte, 0, len(p.MerklePath)*32)
+	for _, node := range p.MerklePath {
+		proofBytes = append(proofBytes, node[:]...)
+	}
+	return proofBytes
+}
+
+// BatchInclusionData is the information returned by the batcher to a client once
+// its verification data was included in a batch
+type BatchInclusionData struct {
+	VerificationDataCommitment VerificationDataCommitment `json:"verification_data_commitment"`
+	BatchMerkleRoot            [32]byte                   `json:"batch_merkle_root"`
+	BatchInclusionProof        InclusionProof             `json:"batch_inclusion_proof"`
+	VerificationDataBatchIndex uint64                     `json:"verification_data_batch_index"`
+}
+
+// Verify checks the inclusion proof against the batch merkle root
+func (d BatchInclusionData) Verify() bool {
+	return VerifyInclusionProof(d.BatchMerkleRoot, d.VerificationDataCommitment.Hash(), d.VerificationDataBatchIndex, d.BatchInclusionProof)
+}
+
+// MerkleTree is a batch merkle tree. levels[0] holds the padded leaves and the
+// last level holds the root.
+type MerkleTree struct {
+	levels    [][][32]byte
+	leavesLen int
+}
+
+// NewMerkleTree builds a tree from the already hashed leaves
+func NewMerkleTree(leaves [][32]byte) (*MerkleTree, error) {
+	if len(leaves) == 0 {
+		return nil, errors.New("cannot build a merkle tree without leaves")
+	}
+
+	nodes := make([][32]byte, len(leaves))
+	copy(nodes, leaves)
+	for !isPowerOfTwo(len(nodes)) {
+		nodes = append(nodes, nodes[len(nodes)-1])
+	}
+
+	levels := [][][32]byte{nodes}
+	for len(nodes) > 1 {
+		parents := make([][32]byte, len(nodes)/2)
+		for i := range parents {
+			parents[i] = hashParent(nodes[2*i], nodes[2*i+1])
+		}
+		levels = append(levels, parents)
+		nodes = parents
+	}
+
+	return &MerkleTree{
+		levels:    levels,
+		leavesLen: len(leaves),
+	}, nil
+}
+
+// NewMerkleTreeFromCommitments builds a tree hashing each commitment as a leaf
+func NewMerkleTreeFromCommitments(commitments []VerificationDataCommitment) (*MerkleTree, error) {
+	leaves := make([][32]byte, len(commitments))
+	for i, commitment := range commitments {
+		leaves[i] = commitment.Hash()
+	}
+	return NewMerkleTree(leaves)
+}
+
+func (t *MerkleTree) Root() [32]byte {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Proof returns the inclusion proof of the leaf at the given index
+func (t *MerkleTree) Proof(index uint64) (InclusionProof, error) {
+	if index >= uint64(t.leavesLen) {
+		return InclusionProof{}, fmt.Errorf("leaf index %d out of range, tree has %d leaves", index, t.leavesLen)
+	}
+
+	merklePath := make([][32]byte, 0, len(t.levels)-1)
+	for _, level := range t.levels[:len(t.levels)-1] {
+		merklePath = append(merklePath, level[index^1])
+		index /= 2
+	}
+
+	return InclusionProof{MerklePath: merklePath}, nil
+}
+
+// VerifyInclusionProof checks that leaf is at the given index of the tree with
+// the given root. It follows Merkle.verifyInclusionKeccak, so an empty proof is
+// only valid for single leaf trees off chain; the contract rejects it.
+func VerifyInclusionProof(root [32]byte, leaf [32]byte, index uint64, proof InclusionProof) bool {
+	computedHash := leaf
+	for _, sibling := range proof.MerklePath {
+		if index%2 == 0 {
+			computedHash = hashParent(computedHash, sibling)
+		} else {
+			computedHash = hashParent(sibling, computedHash)
+		}
+		index /= 2
+	}
+	return computedHash == root
+}
+
+func hashParent(left [32]byte, right [32]byte) [32]byte {
+	return crypto.Keccak256Hash(left[:], right[:])
+}
+
+func isPowerOfTwo(n int) bool {
+	return n != 0 && n&(n-1) == 0
+}
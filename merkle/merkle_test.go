@@ -0,0 +1,80 @@
+package merkle_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yetanotherco/aligned_layer/merkle"
+)
+
+func TestBatchInclusionDataFromBatcherVerifies(t *testing.T) {
+	content, err := os.ReadFile("../batcher/aligned/test_files/batch_inclusion_data/17bd5db82ef731ba3710b22df8e3c1ca6a5cde0a8d1ca1681664e4ff9b25574f_295.json")
+	if err != nil {
+		t.Fatalf("could not read batch inclusion data file: %s", err)
+	}
+
+	var batchInclusionData merkle.BatchInclusionData
+	if err := json.Unmarshal(content, &batchInclusionData); err != nil {
+		t.Fatalf("could not decode batch inclusion data: %s", err)
+	}
+
+	if !batchInclusionData.Verify() {
+		t.Errorf("batch inclusion proof did not verify")
+	}
+
+	batchInclusionData.VerificationDataBatchIndex += 1
+	if batchInclusionData.Verify() {
+		t.Errorf("batch inclusion proof verified with a wrong index")
+	}
+}
+
+func TestParentHashMatchesOpenzeppelin(t *testing.T) {
+	child1 := crypto.Keccak256Hash([]byte{1})
+	child2 := crypto.Keccak256Hash([]byte{2})
+
+	tree, err := merkle.NewMerkleTree([][32]byte{child1, child2})
+	if err != nil {
+		t.Fatalf("could not build merkle tree: %s", err)
+	}
+
+	// Same value as the one used in the batcher tests, built with Openzeppelin's SimpleMerkleTree
+	root := tree.Root()
+	expectedRoot := "71d8979cbfae9b197a4fbcc7d387b1fae9560e2f284d30b4e90c80f6bc074f57"
+	if hex.EncodeToString(root[:]) != expectedRoot {
+		t.Errorf("unexpected root %x, expected %s", root, expectedRoot)
+	}
+}
+
+func TestInclusionProofsVerify(t *testing.T) {
+	for leavesLen := 1; leavesLen <= 9; leavesLen++ {
+		leaves := make([][32]byte, leavesLen)
+		for i := range leaves {
+			leaves[i] = crypto.Keccak256Hash([]byte{byte(i)})
+		}
+
+		tree, err := merkle.NewMerkleTree(leaves)
+		if err != nil {
+			t.Fatalf("could not build merkle tree: %s", err)
+		}
+
+		for i, leaf := range leaves {
+			proof, err := tree.Proof(uint64(i))
+			if err != nil {
+				t.Fatalf("could not get proof for leaf %d: %s", i, err)
+			}
+			if len(proof.Bytes()) != 32*len(proof.MerklePath) {
+				t.Errorf("unexpected proof bytes length %d", len(proof.Bytes()))
+			}
+			if !merkle.VerifyInclusionProof(tree.Root(), leaf, uint64(i), proof) {
+				t.Errorf("proof for leaf %d of %d did not verify", i, leavesLen)
+			}
+		}
+
+		if _, err := tree.Proof(uint64(leavesLen)); err == nil {
+			t.Errorf("expected error getting proof for out of range leaf")
+		}
+	}
+}
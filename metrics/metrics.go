@@ -9,13 +9,29 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"net/http"
+	"time"
 )
 
+const metricsShutdownTimeout = 5 * time.Second
+
 type Metrics struct {
 	ipPortAddress            string
 	logger                   logging.Logger
 	numAggregatedResponses   prometheus.Counter
 	numOperatorTaskResponses prometheus.Counter
+	numMerkleRootMismatches  prometheus.Counter
+	numVerificationFailures  *prometheus.CounterVec
+	numReportedFailures      *prometheus.CounterVec
+	numCacheHits             prometheus.Counter
+	numCacheMisses           prometheus.Counter
+	numFetchAttempts         *prometheus.CounterVec
+	fetchAttemptDuration     *prometheus.HistogramVec
+	batchQueueSize           prometheus.Gauge
+	numExpiredBatches        prometheus.Counter
+	numDroppedBatches        prometheus.Counter
+	walletBalances           *prometheus.GaugeVec
+	fundedWallets            prometheus.Gauge
+	numQuorumRefreshFailures prometheus.Counter
 }
 
 const alignedNamespace = "aligned"
@@ -34,24 +50,101 @@ func NewMetrics(ipPortAddress string, reg prometheus.Registerer, logger logging.
 			Name:      "operator_responses",
 			Help:      "Number of proof verified by the operator and sent to the Aligned Service Manager",
 		}),
+		numMerkleRootMismatches: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_merkle_root_mismatches",
+			Help:      "Number of downloaded batches whose merkle root did not match the one emitted in the NewBatch event",
+		}),
+		numVerificationFailures: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_verification_failures",
+			Help:      "Number of proofs that failed to verify in the operator, by proving system and reason",
+		}, []string{"proving_system", "reason"}),
+		numReportedFailures: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_reported_verification_failures",
+			Help:      "Number of verification failures reported by operators to the aggregator, by proving system and reason",
+		}, []string{"proving_system", "reason"}),
+		numCacheHits: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_verification_cache_hits",
+			Help:      "Number of proofs found in the operator verification cache",
+		}),
+		numCacheMisses: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_verification_cache_misses",
+			Help:      "Number of proofs not found in the operator verification cache",
+		}),
+		numFetchAttempts: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_batch_fetch_attempts",
+			Help:      "Number of batch download attempts made by the operator, by result",
+		}, []string{"result"}),
+		fetchAttemptDuration: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_batch_fetch_attempt_duration_seconds",
+			Help:      "Duration of the batch download attempts made by the operator, by result",
+			Buckets:   []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+		}, []string{"result"}),
+		batchQueueSize: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_batch_queue_size",
+			Help:      "Number of batches waiting to be processed by the operator",
+		}),
+		numExpiredBatches: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_expired_batches",
+			Help:      "Number of batches abandoned because their response window expired",
+		}),
+		numDroppedBatches: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_dropped_batches",
+			Help:      "Number of batches dropped because the operator batch queue was full",
+		}),
+		walletBalances: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_wallet_balance_eth",
+			Help:      "Balance of the aggregator wallets that respond to tasks, by address",
+		}, []string{"address"}),
+		fundedWallets: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_funded_wallets",
+			Help:      "Number of aggregator wallets with enough balance to respond to tasks",
+		}),
+		numQuorumRefreshFailures: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_quorum_refresh_failures",
+			Help:      "Number of quorum refreshes that failed, or found a threshold different from the configured one",
+		}),
 	}
 }
 
 // Start creates a http handler for reg and starts the prometheus server in a goroutine, listening at m.ipPortAddress.
-// reg needs to be the prometheus registry that was passed in the NewMetrics constructor
+// reg needs to be the prometheus registry that was passed in the NewMetrics constructor.
+// The server is shut down when ctx is done, and only its failures are sent to the returned channel.
 func (m *Metrics) Start(ctx context.Context, reg prometheus.Gatherer) <-chan error {
 	m.logger.Infof("Starting metrics server at port %v", m.ipPortAddress)
 	errC := make(chan error, 1)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(
+		reg,
+		promhttp.HandlerOpts{},
+	))
+	server := &http.Server{Addr: m.ipPortAddress, Handler: mux}
+
 	go func() {
-		http.Handle("/metrics", promhttp.HandlerFor(
-			reg,
-			promhttp.HandlerOpts{},
-		))
-		err := http.ListenAndServe(m.ipPortAddress, nil)
-		if err != nil {
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			errC <- types.WrapError(errors.New("prometheus server failed"), err)
-		} else {
-			errC <- nil
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			m.logger.Warn("Could not shut down metrics server", "err", err)
 		}
 	}()
 	return errC
@@ -64,3 +157,52 @@ func (m *Metrics) IncAggregatedResponses() {
 func (m *Metrics) IncOperatorTaskResponses() {
 	m.numOperatorTaskResponses.Inc()
 }
+
+func (m *Metrics) IncOperatorMerkleRootMismatches() {
+	m.numMerkleRootMismatches.Inc()
+}
+
+func (m *Metrics) IncOperatorVerificationFailures(provingSystem string, reason string) {
+	m.numVerificationFailures.WithLabelValues(provingSystem, reason).Inc()
+}
+
+func (m *Metrics) IncReportedVerificationFailures(provingSystem string, reason string) {
+	m.numReportedFailures.WithLabelValues(provingSystem, reason).Inc()
+}
+
+func (m *Metrics) IncOperatorVerificationCacheHits() {
+	m.numCacheHits.Inc()
+}
+
+func (m *Metrics) IncOperatorVerificationCacheMisses() {
+	m.numCacheMisses.Inc()
+}
+
+func (m *Metrics) ObserveBatchFetchAttempt(result string, duration time.Duration) {
+	m.numFetchAttempts.WithLabelValues(result).Inc()
+	m.fetchAttemptDuration.WithLabelValues(result).Observe(duration.Seconds())
+}
+
+func (m *Metrics) SetOperatorBatchQueueSize(size int) {
+	m.batchQueueSize.Set(float64(size))
+}
+
+func (m *Metrics) IncOperatorExpiredBatches() {
+	m.numExpiredBatches.Inc()
+}
+
+func (m *Metrics) IncOperatorDroppedBatches() {
+	m.numDroppedBatches.Inc()
+}
+
+func (m *Metrics) SetAggregatorWalletBalance(address string, balanceEth float64) {
+	m.walletBalances.WithLabelValues(address).Set(balanceEth)
+}
+
+func (m *Metrics) SetAggregatorFundedWallets(count int) {
+	m.fundedWallets.Set(float64(count))
+}
+
+func (m *Metrics) IncAggregatorQuorumRefreshFailures() {
+	m.numQuorumRefreshFailures.Inc()
+}
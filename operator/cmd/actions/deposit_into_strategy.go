@@ -9,10 +9,10 @@ import (
 	"github.com/Layr-Labs/eigensdk-go/chainio/clients/wallet"
 	"github.com/Layr-Labs/eigensdk-go/chainio/txmgr"
 	"github.com/Layr-Labs/eigensdk-go/metrics"
-	"github.com/Layr-Labs/eigensdk-go/signerv2"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/urfave/cli/v2"
 	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/signer"
 )
 
 var (
@@ -62,22 +62,16 @@ func depositIntoStrategyMain(ctx *cli.Context) error {
 	delegationManagerAddr := config.BaseConfig.EigenLayerDeploymentConfig.DelegationManagerAddr
 	avsDirectoryAddr := config.BaseConfig.EigenLayerDeploymentConfig.AVSDirectoryAddr
 
-	signerConfig := signerv2.Config{
-		PrivateKey: config.EcdsaConfig.PrivateKey,
-	}
-	signerFn, _, err := signerv2.SignerFromConfig(signerConfig, config.BaseConfig.ChainId)
-	if err != nil {
-		return err
-	}
+	signerFn := signer.SignerFn(config.EcdsaConfig.Signer)
 	w, err := wallet.NewPrivateKeyWallet(config.BaseConfig.EthRpcClient, signerFn,
-		config.Operator.Address, config.BaseConfig.Logger)
+		config.EcdsaConfig.Signer.Address(), config.BaseConfig.Logger)
 
 	if err != nil {
 		return err
 	}
 
 	txMgr := txmgr.NewSimpleTxManager(w, config.BaseConfig.EthRpcClient, config.BaseConfig.Logger,
-		config.Operator.Address)
+		config.EcdsaConfig.Signer.Address())
 	eigenMetrics := metrics.NewNoopMetrics()
 	eigenLayerWriter, err := elcontracts.BuildELChainWriter(delegationManagerAddr, avsDirectoryAddr,
 		config.BaseConfig.EthRpcClient, config.BaseConfig.Logger, eigenMetrics, txMgr)
@@ -25,13 +25,11 @@ var RegisterCommand = &cli.Command{
 func registerOperatorMain(ctx *cli.Context) error {
 	config := config.NewOperatorConfig(ctx.String(config.ConfigFileFlag.Name))
 
-	quorumNumbers := []byte{0}
-
 	// Generate salt and expiry
-	privateKeyBytes := []byte(config.BlsConfig.KeyPair.PrivKey.String())
+	publicKeyBytes := config.BlsConfig.Signer.PubKeyG1().Serialize()
 	salt := [32]byte{}
 
-	copy(salt[:], crypto.Keccak256([]byte("churn"), []byte(time.Now().String()), quorumNumbers, privateKeyBytes))
+	copy(salt[:], crypto.Keccak256([]byte("churn"), []byte(time.Now().String()), publicKeyBytes))
 
 	err := operator.RegisterOperator(context.Background(), config, salt)
 	if err != nil {
@@ -1,12 +1,13 @@
 package actions
 
 import (
-	"context"
+	"errors"
 	"log"
 
 	sdkutils "github.com/Layr-Labs/eigensdk-go/utils"
 	"github.com/urfave/cli/v2"
 	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/utils"
 	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
 )
 
@@ -34,13 +35,20 @@ func operatorMain(ctx *cli.Context) error {
 		return err
 	}
 
+	// SIGINT and SIGTERM stop the operator after the batches in process are finished
+	shutdownCtx, stop := utils.NewShutdownContext()
+	defer stop()
+
 	log.Println("Operator starting...")
-	err = operator.Start(context.Background())
+	err = operator.Start(shutdownCtx)
+	if errors.Is(err, utils.ErrShutdownTimeout) {
+		return cli.Exit(err.Error(), utils.ExitCodeShutdownTimeout)
+	}
 	if err != nil {
 		return err
 	}
 
-	log.Println("Operator started")
+	log.Println("Operator stopped")
 
 	return nil
 }
@@ -0,0 +1,126 @@
+package fetcher
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/yetanotherco/aligned_layer/common"
+)
+
+// BatchEntryHandler is called for each entry of a batch as soon as it is decoded
+type BatchEntryHandler func(ctx context.Context, index int, verificationData common.VerificationData) error
+
+// sizeCappedReader fails with ErrBatchTooLarge once more than maxSize bytes are read,
+// regardless of the size announced by the storage
+type sizeCappedReader struct {
+	reader  io.Reader
+	read    int64
+	maxSize int64
+}
+
+func newSizeCappedReader(reader io.Reader, maxSize int64) *sizeCappedReader {
+	return &sizeCappedReader{
+		reader:  io.LimitReader(reader, maxSize+1),
+		maxSize: maxSize,
+	}
+}
+
+func (r *sizeCappedReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	if r.read > r.maxSize {
+		return 0, fmt.Errorf("%w: max batch size %d", ErrBatchTooLarge, r.maxSize)
+	}
+	return n, err
+}
+
+// decodeBatch decodes a JSON or binary batch one entry at a time, so the whole
+// batch is never held in memory. The format is detected from the batch prefix.
+func decodeBatch(ctx context.Context, reader io.Reader, handle func(index int, verificationData common.VerificationData) error) error {
+	bufferedReader := bufio.NewReader(reader)
+	prefix, err := bufferedReader.Peek(len(common.BinaryBatchMagic))
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	if common.DetectBatchFormat(prefix) == common.BatchFormatBinary {
+		return decodeBinaryBatch(ctx, bufferedReader, handle)
+	}
+	return decodeJsonBatch(ctx, bufferedReader, handle)
+}
+
+func decodeBinaryBatch(ctx context.Context, reader io.Reader, handle func(index int, verificationData common.VerificationData) error) error {
+	decoder, err := common.NewBinaryBatchDecoder(reader)
+	if err != nil {
+		if errors.Is(err, common.ErrUnsupportedBatchVersion) {
+			return fmt.Errorf("%w: %v", ErrMalformedBatch, err)
+		}
+		return malformedBatchError(err)
+	}
+
+	for index := 0; ; index++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		verificationData, err := decoder.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return malformedBatchError(err)
+		}
+		if err = handle(index, verificationData); err != nil {
+			return err
+		}
+	}
+}
+
+func decodeJsonBatch(ctx context.Context, reader io.Reader, handle func(index int, verificationData common.VerificationData) error) error {
+	decoder := json.NewDecoder(reader)
+
+	token, err := decoder.Token()
+	if err != nil {
+		return malformedBatchError(err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("%w: expected an array of verification data", ErrMalformedBatch)
+	}
+
+	for index := 0; decoder.More(); index++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		var verificationData common.VerificationData
+		if err = decoder.Decode(&verificationData); err != nil {
+			return malformedBatchError(err)
+		}
+		if err = handle(index, verificationData); err != nil {
+			return err
+		}
+	}
+
+	if _, err = decoder.Token(); err != nil {
+		return malformedBatchError(err)
+	}
+	return nil
+}
+
+// malformedBatchError keeps read errors, like ErrBatchTooLarge, and marks decoding errors as ErrMalformedBatch
+func malformedBatchError(err error) error {
+	switch err.(type) {
+	case *json.SyntaxError, *json.UnmarshalTypeError, *cbor.SyntaxError, *cbor.SemanticError, *cbor.UnmarshalTypeError:
+		return fmt.Errorf("%w: %v", ErrMalformedBatch, err)
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: unexpected end of batch", ErrMalformedBatch)
+	}
+	return err
+}
@@ -0,0 +1,209 @@
+// Package fetcher downloads the batches referenced by NewBatch events.
+//
+// The batch data pointer is dispatched on its URL scheme to a SourceFetcher.
+// The primary pointer is tried first and then the configured mirrors. Batches
+// are decoded while they are downloaded, and are only accepted if their merkle
+// root is the one committed on chain.
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/yetanotherco/aligned_layer/common"
+	"github.com/yetanotherco/aligned_layer/merkle"
+)
+
+var (
+	ErrUnsupportedScheme  = errors.New("unsupported batch data pointer scheme")
+	ErrMerkleRootMismatch = errors.New("batch merkle root mismatch")
+	ErrBatchTooLarge      = errors.New("batch exceeds max batch size")
+	ErrMalformedBatch     = errors.New("malformed batch")
+	ErrFetchTimeout       = errors.New("batch fetch timed out")
+)
+
+// SourceFetcher opens a raw batch in the storage of a URL scheme. maxSize is
+// only used to fail early, the reader is size capped by the BatchFetcher.
+type SourceFetcher interface {
+	Open(ctx context.Context, pointer *url.URL, maxSize int64) (io.ReadCloser, error)
+}
+
+type Config struct {
+	MaxBatchSize int64
+	// Base URLs tried in order, after the batch data pointer, with the batch file name appended
+	Mirrors []string
+	// Directory file:// pointers must be in. file:// pointers are rejected if empty
+	FilesDir       string
+	IpfsGatewayUrl string
+	S3Region       string
+	// Optional endpoint of an S3 compatible storage
+	S3Endpoint string
+	// Max time to download and decode a batch from a source, zero means no limit
+	Timeout time.Duration
+	Http    HttpConfig
+}
+
+type BatchFetcher struct {
+	sourceFetchers map[string]SourceFetcher
+	mirrors        []string
+	maxBatchSize   int64
+	timeout        time.Duration
+	logger         logging.Logger
+}
+
+// NewBatchFetcher creates a fetcher for the https, http, ipfs, s3 and, if a files
+// directory is configured, file schemes. metrics can be nil.
+func NewBatchFetcher(config Config, metrics Metrics, logger logging.Logger) (*BatchFetcher, error) {
+	fetcher := &BatchFetcher{
+		sourceFetchers: make(map[string]SourceFetcher),
+		mirrors:        config.Mirrors,
+		maxBatchSize:   config.MaxBatchSize,
+		timeout:        config.Timeout,
+		logger:         logger,
+	}
+
+	httpFetcher := NewHttpFetcher(config.Http, metrics)
+	fetcher.RegisterSourceFetcher("https", httpFetcher)
+	fetcher.RegisterSourceFetcher("http", httpFetcher)
+
+	if config.IpfsGatewayUrl != "" {
+		ipfsFetcher, err := NewIpfsFetcher(config.IpfsGatewayUrl, httpFetcher)
+		if err != nil {
+			return nil, err
+		}
+		fetcher.RegisterSourceFetcher("ipfs", ipfsFetcher)
+	}
+
+	if config.FilesDir != "" {
+		fetcher.RegisterSourceFetcher("file", NewFileFetcher(config.FilesDir))
+	}
+
+	s3Fetcher, err := NewS3Fetcher(config.S3Region, config.S3Endpoint, httpFetcher.client, config.Http.MaxRetries)
+	if err != nil {
+		return nil, err
+	}
+	fetcher.RegisterSourceFetcher("s3", s3Fetcher)
+
+	return fetcher, nil
+}
+
+// RegisterSourceFetcher sets the fetcher used for the pointers with the given scheme
+func (f *BatchFetcher) RegisterSourceFetcher(scheme string, sourceFetcher SourceFetcher) {
+	f.sourceFetchers[strings.ToLower(scheme)] = sourceFetcher
+}
+
+// FetchBatch downloads the batch from the batch data pointer or its mirrors and
+// checks it against the batch merkle root. The errors of every source are
+// returned if none of them has the batch.
+func (f *BatchFetcher) FetchBatch(ctx context.Context, batchDataPointer string, batchMerkleRoot [32]byte) ([]common.VerificationData, error) {
+	sources, err := f.Sources(batchDataPointer)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, 0, len(sources))
+	for _, source := range sources {
+		batch := make([]common.VerificationData, 0)
+		err := f.StreamBatch(ctx, source, batchMerkleRoot, func(_ context.Context, _ int, verificationData common.VerificationData) error {
+			batch = append(batch, verificationData)
+			return nil
+		})
+		if err == nil {
+			return batch, nil
+		}
+
+		f.logger.Warn("Could not fetch batch", "source", source.Redacted(), "err", err)
+		errs = append(errs, SourceError(source, err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// StreamBatch downloads the batch from a single source, calling handle for each
+// entry as soon as it is decoded. The batch merkle root can only be checked once
+// the whole batch is read, so the entries must not be trusted unless StreamBatch
+// returns nil.
+func (f *BatchFetcher) StreamBatch(ctx context.Context, source *url.URL, batchMerkleRoot [32]byte, handle BatchEntryHandler) error {
+	sourceFetcher, ok := f.sourceFetchers[strings.ToLower(source.Scheme)]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedScheme, source.Scheme)
+	}
+
+	attemptCtx := ctx
+	if f.timeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(ctx, f.timeout)
+		defer cancel()
+	}
+
+	err := f.streamBatch(attemptCtx, sourceFetcher, source, batchMerkleRoot, handle)
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s: %v", ErrFetchTimeout, f.timeout, err)
+	}
+	return err
+}
+
+func (f *BatchFetcher) streamBatch(ctx context.Context, sourceFetcher SourceFetcher, source *url.URL, batchMerkleRoot [32]byte, handle BatchEntryHandler) error {
+	reader, err := sourceFetcher.Open(ctx, source, f.maxBatchSize)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// Only the commitments of the entries are kept to compute the merkle root
+	commitments := make([]merkle.VerificationDataCommitment, 0)
+	err = decodeBatch(ctx, newSizeCappedReader(reader, f.maxBatchSize), func(index int, verificationData common.VerificationData) error {
+		commitments = append(commitments, merkle.NewVerificationDataCommitment(verificationData))
+		return handle(ctx, index, verificationData)
+	})
+	if err != nil {
+		return err
+	}
+
+	tree, err := merkle.NewMerkleTreeFromCommitments(commitments)
+	if err != nil {
+		return err
+	}
+	if root := tree.Root(); root != batchMerkleRoot {
+		return fmt.Errorf("%w: expected %x, got %x", ErrMerkleRootMismatch, batchMerkleRoot, root)
+	}
+	return nil
+}
+
+// SourceError adds the redacted source URL to an error fetching from it
+func SourceError(source *url.URL, err error) error {
+	return fmt.Errorf("%s: %w", source.Redacted(), err)
+}
+
+// Sources returns the batch data pointer followed by the mirror URLs of the batch
+func (f *BatchFetcher) Sources(batchDataPointer string) ([]*url.URL, error) {
+	pointer, err := url.Parse(batchDataPointer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid batch data pointer %s: %w", batchDataPointer, err)
+	}
+
+	sources := []*url.URL{pointer}
+	fileName := path.Base(pointer.Path)
+	if fileName == "/" || fileName == "." {
+		return sources, nil
+	}
+
+	for _, mirror := range f.mirrors {
+		mirrorUrl, err := url.Parse(mirror)
+		if err != nil {
+			return nil, fmt.Errorf("invalid batch mirror %s: %w", mirror, err)
+		}
+		sources = append(sources, mirrorUrl.JoinPath(fileName))
+	}
+	return sources, nil
+}
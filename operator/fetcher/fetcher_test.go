@@ -0,0 +1,249 @@
+package fetcher_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/yetanotherco/aligned_layer/common"
+	"github.com/yetanotherco/aligned_layer/merkle"
+	"github.com/yetanotherco/aligned_layer/operator/fetcher"
+	_ "github.com/yetanotherco/aligned_layer/operator/gnark"
+)
+
+const maxBatchSize = 1024 * 1024
+
+func TestFetchBatchFromFile(t *testing.T) {
+	dir := t.TempDir()
+	batch, root := writeBatch(t, dir, "batch.json")
+
+	batchFetcher := newBatchFetcher(t, dir, nil)
+	fetchedBatch, err := batchFetcher.FetchBatch(context.Background(), "file://"+filepath.Join(dir, "batch.json"), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fetchedBatch) != len(batch) {
+		t.Errorf("expected %d entries, got %d", len(batch), len(fetchedBatch))
+	}
+}
+
+func TestFetchBinaryBatchFromFile(t *testing.T) {
+	dir := t.TempDir()
+	batch, root := writeBatchWithFormat(t, dir, "batch.bin", common.BatchFormatBinary)
+
+	batchFetcher := newBatchFetcher(t, dir, nil)
+	fetchedBatch, err := batchFetcher.FetchBatch(context.Background(), "file://"+filepath.Join(dir, "batch.bin"), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fetchedBatch) != len(batch) {
+		t.Errorf("expected %d entries, got %d", len(batch), len(fetchedBatch))
+	}
+}
+
+func TestFetchBatchRejectsTruncatedBinaryBatch(t *testing.T) {
+	dir := t.TempDir()
+	_, root := writeBatchWithFormat(t, dir, "batch.bin", common.BatchFormatBinary)
+	filePath := filepath.Join(dir, "batch.bin")
+	batchBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filePath, batchBytes[:len(batchBytes)/2], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = newBatchFetcher(t, dir, nil).FetchBatch(context.Background(), "file://"+filePath, root)
+	if !errors.Is(err, fetcher.ErrMalformedBatch) {
+		t.Errorf("expected malformed batch, got %v", err)
+	}
+}
+
+func TestFetchBatchRejectsMerkleRootMismatch(t *testing.T) {
+	dir := t.TempDir()
+	writeBatch(t, dir, "batch.json")
+
+	batchFetcher := newBatchFetcher(t, dir, nil)
+	_, err := batchFetcher.FetchBatch(context.Background(), "file://"+filepath.Join(dir, "batch.json"), [32]byte{1})
+	if !errors.Is(err, fetcher.ErrMerkleRootMismatch) {
+		t.Errorf("expected merkle root mismatch, got %v", err)
+	}
+}
+
+func TestFetchBatchFallsBackToMirrors(t *testing.T) {
+	dir := t.TempDir()
+	mirrorDir := filepath.Join(dir, "mirror")
+	if err := os.Mkdir(mirrorDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	_, root := writeBatch(t, mirrorDir, "batch.json")
+
+	batchFetcher := newBatchFetcher(t, dir, []string{"file://" + mirrorDir})
+	_, err := batchFetcher.FetchBatch(context.Background(), "file://"+filepath.Join(dir, "batch.json"), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFetchBatchRejectsFilesOutsideDir(t *testing.T) {
+	dir := t.TempDir()
+	otherDir := t.TempDir()
+	_, root := writeBatch(t, otherDir, "batch.json")
+
+	batchFetcher := newBatchFetcher(t, dir, nil)
+	_, err := batchFetcher.FetchBatch(context.Background(), "file://"+filepath.Join(otherDir, "batch.json"), root)
+	if err == nil {
+		t.Errorf("expected file outside of the batch files directory to be rejected")
+	}
+}
+
+func TestFetchBatchRejectsUnknownScheme(t *testing.T) {
+	batchFetcher := newBatchFetcher(t, t.TempDir(), nil)
+	_, err := batchFetcher.FetchBatch(context.Background(), "ftp://example.com/batch.json", [32]byte{})
+	if !errors.Is(err, fetcher.ErrUnsupportedScheme) {
+		t.Errorf("expected unsupported scheme, got %v", err)
+	}
+}
+
+func TestStreamBatchRejectsChunkedResponseOverMaxSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Flushing before writing everything sends a chunked response without Content-Length
+		w.Write([]byte(`[{"proof": [`))
+		w.(http.Flusher).Flush()
+		chunk := []byte(strings.Repeat("0,", 2048))
+		for i := 0; i < 2*maxBatchSize; i += len(chunk) {
+			w.Write(chunk)
+		}
+	}))
+	defer server.Close()
+
+	err := streamBatch(t, newBatchFetcher(t, "", nil), server.URL+"/batch.json", [32]byte{})
+	if !errors.Is(err, fetcher.ErrBatchTooLarge) {
+		t.Errorf("expected batch too large, got %v", err)
+	}
+}
+
+func TestStreamBatchRejectsMalformedBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"proof": [1, 2`))
+	}))
+	defer server.Close()
+
+	err := streamBatch(t, newBatchFetcher(t, "", nil), server.URL+"/batch.json", [32]byte{})
+	if !errors.Is(err, fetcher.ErrMalformedBatch) {
+		t.Errorf("expected malformed batch, got %v", err)
+	}
+}
+
+func TestStreamBatchTimesOut(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("["))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	batchFetcher, err := fetcher.NewBatchFetcher(fetcher.Config{
+		MaxBatchSize: maxBatchSize,
+		S3Region:     "us-east-2",
+		Timeout:      100 * time.Millisecond,
+	}, nil, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = streamBatch(t, batchFetcher, server.URL+"/batch.json", [32]byte{})
+	if !errors.Is(err, fetcher.ErrFetchTimeout) {
+		t.Errorf("expected fetch timeout, got %v", err)
+	}
+}
+
+func TestStreamBatchHandlesEntriesAsDecoded(t *testing.T) {
+	dir := t.TempDir()
+	batch, root := writeBatch(t, dir, "batch.json")
+
+	handled := 0
+	source, _ := url.Parse("file://" + filepath.Join(dir, "batch.json"))
+	err := newBatchFetcher(t, dir, nil).StreamBatch(context.Background(), source, root, func(_ context.Context, index int, _ common.VerificationData) error {
+		if index != handled {
+			t.Errorf("expected entry %d, got %d", handled, index)
+		}
+		handled++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handled != len(batch) {
+		t.Errorf("expected %d entries, got %d", len(batch), handled)
+	}
+}
+
+func streamBatch(t *testing.T, batchFetcher *fetcher.BatchFetcher, pointer string, batchMerkleRoot [32]byte) error {
+	source, err := url.Parse(pointer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return batchFetcher.StreamBatch(context.Background(), source, batchMerkleRoot, func(context.Context, int, common.VerificationData) error {
+		return nil
+	})
+}
+
+func newBatchFetcher(t *testing.T, filesDir string, mirrors []string) *fetcher.BatchFetcher {
+	batchFetcher, err := fetcher.NewBatchFetcher(fetcher.Config{
+		MaxBatchSize: maxBatchSize,
+		Mirrors:      mirrors,
+		FilesDir:     filesDir,
+		S3Region:     "us-east-2",
+	}, nil, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return batchFetcher
+}
+
+func writeBatch(t *testing.T, dir string, fileName string) ([]common.VerificationData, [32]byte) {
+	return writeBatchWithFormat(t, dir, fileName, common.BatchFormatJson)
+}
+
+func writeBatchWithFormat(t *testing.T, dir string, fileName string, format common.BatchFormat) ([]common.VerificationData, [32]byte) {
+	exampleDir := "../../task_sender/test_examples/gnark_plonk_bn254_script/"
+	proof, err := os.ReadFile(exampleDir + "plonk.proof")
+	if err != nil {
+		t.Fatal(err)
+	}
+	verificationKey, err := os.ReadFile(exampleDir + "plonk.vk")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batch := []common.VerificationData{{
+		ProvingSystemId: common.GnarkPlonkBn254,
+		Proof:           proof,
+		VerificationKey: verificationKey,
+	}}
+	batchBytes, err := common.MarshalBatch(batch, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, fileName), batchBytes, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := merkle.BatchMerkleRoot(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return batch, root
+}
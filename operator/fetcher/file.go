@@ -0,0 +1,54 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileFetcher reads batches from the local filesystem. Only files inside its
+// directory can be read, as the batch data pointer comes from the chain.
+type FileFetcher struct {
+	dir string
+}
+
+func NewFileFetcher(dir string) *FileFetcher {
+	return &FileFetcher{dir: dir}
+}
+
+func (f *FileFetcher) Open(_ context.Context, pointer *url.URL, maxSize int64) (io.ReadCloser, error) {
+	dir, err := filepath.Abs(f.dir)
+	if err != nil {
+		return nil, err
+	}
+	filePath, err := filepath.Abs(filepath.FromSlash(pointer.Path))
+	if err != nil {
+		return nil, err
+	}
+
+	relativePath, err := filepath.Rel(dir, filePath)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("batch file %s is outside of the batch files directory %s", filePath, dir)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.Size() > maxSize {
+		file.Close()
+		return nil, fmt.Errorf("%w: batch size %d, max batch size %d", ErrBatchTooLarge, info.Size(), maxSize)
+	}
+
+	return file, nil
+}
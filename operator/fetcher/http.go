@@ -0,0 +1,319 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrReadTimeout = errors.New("batch download stalled")
+
+// Results of a download attempt reported to the metrics
+const (
+	AttemptResultSuccess = "success"
+	AttemptResultError   = "error"
+	AttemptResultAborted = "aborted"
+)
+
+type HttpConfig struct {
+	// Max time to establish a connection, including the TLS handshake
+	ConnectTimeout time.Duration
+	// Max time waiting for the response headers or for the next bytes of the body
+	ReadTimeout time.Duration
+	// Max amount of retries of a download, interrupted downloads are resumed with a Range request
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Metrics receives the result and duration of each download attempt
+type Metrics interface {
+	ObserveBatchFetchAttempt(result string, duration time.Duration)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveBatchFetchAttempt(string, time.Duration) {}
+
+type HttpFetcher struct {
+	client  *http.Client
+	config  HttpConfig
+	metrics Metrics
+}
+
+func NewHttpFetcher(config HttpConfig, metrics Metrics) *HttpFetcher {
+	if metrics == nil {
+		metrics = noopMetrics{}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: config.ConnectTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = config.ConnectTimeout
+	transport.ResponseHeaderTimeout = config.ReadTimeout
+
+	return &HttpFetcher{
+		client:  &http.Client{Transport: transport},
+		config:  config,
+		metrics: metrics,
+	}
+}
+
+func (f *HttpFetcher) Open(ctx context.Context, pointer *url.URL, maxSize int64) (io.ReadCloser, error) {
+	body := &resumableBody{
+		ctx:     ctx,
+		fetcher: f,
+		url:     pointer.String(),
+	}
+	if err := body.connect(); err != nil {
+		return nil, err
+	}
+
+	// The announced length is only used to fail early, chunked responses
+	// or servers lying about it are capped while reading
+	if body.contentLength > maxSize {
+		body.Close()
+		return nil, fmt.Errorf("%w: batch size %d, max batch size %d", ErrBatchTooLarge, body.contentLength, maxSize)
+	}
+
+	return body, nil
+}
+
+type httpStatusError struct {
+	statusCode int
+	status     string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("error getting batch: %s", e.status)
+}
+
+// resumableBody reads a batch over HTTP, reconnecting with a Range request
+// from the last read byte when the download fails
+type resumableBody struct {
+	ctx     context.Context
+	fetcher *HttpFetcher
+	url     string
+
+	body          io.ReadCloser
+	cancelAttempt context.CancelFunc
+	attemptStart  time.Time
+	// Bytes of the batch already read
+	offset        int64
+	contentLength int64
+	retries       int
+}
+
+func (b *resumableBody) Read(p []byte) (int, error) {
+	if b.body == nil {
+		if err := b.connect(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := b.body.Read(p)
+	b.offset += int64(n)
+	if err == nil {
+		return n, nil
+	}
+	if err == io.EOF {
+		b.endAttempt(AttemptResultSuccess)
+		return n, io.EOF
+	}
+
+	b.endAttempt(AttemptResultError)
+	if !b.retryable(err) {
+		return n, err
+	}
+	if err = b.waitBackoff(); err != nil {
+		return n, err
+	}
+	if n > 0 {
+		// Return the data read so far, the next Read reconnects
+		return n, nil
+	}
+	return b.Read(p)
+}
+
+func (b *resumableBody) Close() error {
+	if b.body != nil {
+		b.endAttempt(AttemptResultAborted)
+	}
+	return nil
+}
+
+// connect starts a download attempt from the current offset, retrying with
+// exponential backoff on failure
+func (b *resumableBody) connect() error {
+	for {
+		err := b.attempt()
+		if err == nil {
+			return nil
+		}
+		b.fetcher.metrics.ObserveBatchFetchAttempt(AttemptResultError, time.Since(b.attemptStart))
+
+		if !b.retryable(err) {
+			return err
+		}
+		if err = b.waitBackoff(); err != nil {
+			return err
+		}
+	}
+}
+
+func (b *resumableBody) attempt() error {
+	b.attemptStart = time.Now()
+	attemptCtx, cancel := context.WithCancel(b.ctx)
+
+	request, err := http.NewRequestWithContext(attemptCtx, http.MethodGet, b.url, nil)
+	if err != nil {
+		cancel()
+		return err
+	}
+	if b.offset > 0 {
+		request.Header.Set("Range", fmt.Sprintf("bytes=%d-", b.offset))
+	}
+
+	response, err := b.fetcher.client.Do(request)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	switch {
+	case response.StatusCode == http.StatusOK:
+		if b.offset == 0 {
+			b.contentLength = response.ContentLength
+		}
+		// The server ignored the Range header, skip what was already read
+		if b.offset > 0 {
+			if _, err = io.CopyN(io.Discard, response.Body, b.offset); err != nil {
+				response.Body.Close()
+				cancel()
+				return err
+			}
+		}
+	case response.StatusCode == http.StatusPartialContent && b.offset > 0:
+		if start, ok := contentRangeStart(response.Header.Get("Content-Range")); !ok || start != b.offset {
+			response.Body.Close()
+			cancel()
+			return fmt.Errorf("unexpected content range %q resuming at %d", response.Header.Get("Content-Range"), b.offset)
+		}
+	default:
+		response.Body.Close()
+		cancel()
+		return &httpStatusError{statusCode: response.StatusCode, status: response.Status}
+	}
+
+	b.body = newIdleTimeoutReader(response.Body, b.fetcher.config.ReadTimeout, cancel)
+	b.cancelAttempt = cancel
+	return nil
+}
+
+func (b *resumableBody) endAttempt(result string) {
+	b.fetcher.metrics.ObserveBatchFetchAttempt(result, time.Since(b.attemptStart))
+	b.body.Close()
+	b.cancelAttempt()
+	b.body = nil
+}
+
+func (b *resumableBody) retryable(err error) bool {
+	if b.ctx.Err() != nil || b.retries >= b.fetcher.config.MaxRetries {
+		return false
+	}
+
+	var statusError *httpStatusError
+	if errors.As(err, &statusError) {
+		return statusError.statusCode >= 500 ||
+			statusError.statusCode == http.StatusTooManyRequests ||
+			statusError.statusCode == http.StatusRequestTimeout
+	}
+	return true
+}
+
+func (b *resumableBody) waitBackoff() error {
+	backoff := b.fetcher.config.InitialBackoff << b.retries
+	if backoff > b.fetcher.config.MaxBackoff || backoff <= 0 {
+		backoff = b.fetcher.config.MaxBackoff
+	}
+	// Jitter so operators don't retry against the storage at the same time
+	if backoff > 0 {
+		backoff = backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+	}
+	b.retries++
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-b.ctx.Done():
+		return b.ctx.Err()
+	}
+}
+
+// contentRangeStart parses the first byte of a "bytes <start>-<end>/<size>" Content-Range
+func contentRangeStart(contentRange string) (int64, bool) {
+	rangeSpec, ok := strings.CutPrefix(contentRange, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	start, _, ok := strings.Cut(rangeSpec, "-")
+	if !ok {
+		return 0, false
+	}
+	offset, err := strconv.ParseInt(start, 10, 64)
+	return offset, err == nil
+}
+
+// idleTimeoutReader cancels the request if no bytes are received for timeout
+type idleTimeoutReader struct {
+	reader   io.ReadCloser
+	timeout  time.Duration
+	timer    *time.Timer
+	mutex    sync.Mutex
+	timedOut bool
+}
+
+func newIdleTimeoutReader(reader io.ReadCloser, timeout time.Duration, cancel context.CancelFunc) io.ReadCloser {
+	if timeout <= 0 {
+		return reader
+	}
+
+	idleReader := &idleTimeoutReader{reader: reader, timeout: timeout}
+	idleReader.timer = time.AfterFunc(timeout, func() {
+		idleReader.mutex.Lock()
+		idleReader.timedOut = true
+		idleReader.mutex.Unlock()
+		cancel()
+	})
+	return idleReader
+}
+
+func (r *idleTimeoutReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.timer.Reset(r.timeout)
+	}
+	if err != nil && err != io.EOF {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		if r.timedOut {
+			return n, fmt.Errorf("%w: no data received for %s", ErrReadTimeout, r.timeout)
+		}
+	}
+	return n, err
+}
+
+func (r *idleTimeoutReader) Close() error {
+	r.timer.Stop()
+	return r.reader.Close()
+}
@@ -0,0 +1,154 @@
+package fetcher_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/operator/fetcher"
+)
+
+type attemptsRecorder struct {
+	mutex    sync.Mutex
+	attempts map[string]int
+}
+
+func (r *attemptsRecorder) ObserveBatchFetchAttempt(result string, _ time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.attempts[result]++
+}
+
+func newHttpFetcher(maxRetries int, recorder *attemptsRecorder) *fetcher.HttpFetcher {
+	var metrics fetcher.Metrics
+	if recorder != nil {
+		metrics = recorder
+	}
+	return fetcher.NewHttpFetcher(fetcher.HttpConfig{
+		ConnectTimeout: time.Second,
+		ReadTimeout:    200 * time.Millisecond,
+		MaxRetries:     maxRetries,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+	}, metrics)
+}
+
+func readAll(t *testing.T, httpFetcher *fetcher.HttpFetcher, serverUrl string) ([]byte, error) {
+	pointer, err := url.Parse(serverUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := httpFetcher.Open(context.Background(), pointer, maxBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+func TestHttpFetcherRetriesServerErrors(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("batch"))
+	}))
+	defer server.Close()
+
+	recorder := &attemptsRecorder{attempts: make(map[string]int)}
+	data, err := readAll(t, newHttpFetcher(3, recorder), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "batch" {
+		t.Errorf("unexpected batch %q", data)
+	}
+	if recorder.attempts[fetcher.AttemptResultError] != 2 || recorder.attempts[fetcher.AttemptResultSuccess] != 1 {
+		t.Errorf("unexpected attempts %v", recorder.attempts)
+	}
+}
+
+func TestHttpFetcherDoesNotRetryClientErrors(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := readAll(t, newHttpFetcher(3, nil), server.URL)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
+
+func TestHttpFetcherResumesWithRange(t *testing.T) {
+	batch := strings.Repeat("0123456789", 1000)
+	ranges := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rangeHeader := r.Header.Get("Range")
+		ranges = append(ranges, rangeHeader)
+		if rangeHeader == "" {
+			// Announce the whole batch but drop the connection halfway
+			w.Header().Set("Content-Length", strconv.Itoa(len(batch)))
+			w.Write([]byte(batch[:len(batch)/2]))
+			w.(http.Flusher).Flush()
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+
+		start, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rangeHeader, "bytes="), "-"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(batch)-1, len(batch)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte(batch[start:]))
+	}))
+	defer server.Close()
+
+	data, err := readAll(t, newHttpFetcher(3, nil), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != batch {
+		t.Errorf("resumed batch does not match")
+	}
+	if len(ranges) != 2 || ranges[1] != fmt.Sprintf("bytes=%d-", len(batch)/2) {
+		t.Errorf("unexpected range requests %v", ranges)
+	}
+}
+
+func TestHttpFetcherReadTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("["))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	_, err := readAll(t, newHttpFetcher(1, nil), server.URL)
+	if !errors.Is(err, fetcher.ErrReadTimeout) {
+		t.Errorf("expected read timeout, got %v", err)
+	}
+}
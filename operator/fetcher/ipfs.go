@@ -0,0 +1,32 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// IpfsFetcher gets ipfs://<cid>/<path> pointers through an HTTP gateway
+type IpfsFetcher struct {
+	gatewayUrl  *url.URL
+	httpFetcher *HttpFetcher
+}
+
+func NewIpfsFetcher(gatewayUrl string, httpFetcher *HttpFetcher) (*IpfsFetcher, error) {
+	parsedGatewayUrl, err := url.Parse(gatewayUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IPFS gateway url %s: %w", gatewayUrl, err)
+	}
+	return &IpfsFetcher{
+		gatewayUrl:  parsedGatewayUrl,
+		httpFetcher: httpFetcher,
+	}, nil
+}
+
+func (f *IpfsFetcher) Open(ctx context.Context, pointer *url.URL, maxSize int64) (io.ReadCloser, error) {
+	if pointer.Host == "" {
+		return nil, fmt.Errorf("IPFS pointer %s has no CID", pointer)
+	}
+	return f.httpFetcher.Open(ctx, f.gatewayUrl.JoinPath("ipfs", pointer.Host, pointer.Path), maxSize)
+}
@@ -0,0 +1,59 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// S3Fetcher gets s3://<bucket>/<key> pointers using the default AWS credentials chain.
+// Retries are handled by the AWS SDK.
+type S3Fetcher struct {
+	client *s3.S3
+}
+
+// NewS3Fetcher creates a fetcher retrying each request maxRetries times, a negative
+// amount disables retries
+func NewS3Fetcher(region string, endpoint string, httpClient *http.Client, maxRetries int) (*S3Fetcher, error) {
+	config := &aws.Config{
+		Region:     aws.String(region),
+		HTTPClient: httpClient,
+		// -1 is aws.UseServiceDefaultRetries for the SDK
+		MaxRetries: aws.Int(max(maxRetries, 0)),
+	}
+	if endpoint != "" {
+		config.Endpoint = aws.String(endpoint)
+		config.S3ForcePathStyle = aws.Bool(true)
+	}
+
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, fmt.Errorf("could not create AWS session: %w", err)
+	}
+	return &S3Fetcher{client: s3.New(sess)}, nil
+}
+
+func (f *S3Fetcher) Open(ctx context.Context, pointer *url.URL, maxSize int64) (io.ReadCloser, error) {
+	key := strings.TrimPrefix(pointer.Path, "/")
+	output, err := f.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(pointer.Host),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if output.ContentLength != nil && *output.ContentLength > maxSize {
+		output.Body.Close()
+		return nil, fmt.Errorf("%w: batch size %d, max batch size %d", ErrBatchTooLarge, *output.ContentLength, maxSize)
+	}
+
+	return output.Body, nil
+}
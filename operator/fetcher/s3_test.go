@@ -0,0 +1,48 @@
+package fetcher_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/yetanotherco/aligned_layer/operator/fetcher"
+)
+
+func TestS3FetcherRetries(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	tests := []struct {
+		name       string
+		maxRetries int
+		requests   int32
+	}{
+		{"retries disabled", -1, 1},
+		{"no retries", 0, 1},
+		{"two retries", 2, 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			s3Fetcher, err := fetcher.NewS3Fetcher("us-east-1", server.URL, server.Client(), test.maxRetries)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = s3Fetcher.Open(context.Background(), &url.URL{Scheme: "s3", Host: "bucket", Path: "/batch"}, maxBatchSize); err == nil {
+				t.Fatal("expected an error")
+			}
+			if requests.Load() != test.requests {
+				t.Errorf("expected %d requests, got %d", test.requests, requests.Load())
+			}
+		})
+	}
+}
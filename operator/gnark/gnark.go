@@ -0,0 +1,127 @@
+package gnark
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/yetanotherco/aligned_layer/common"
+)
+
+func init() {
+	common.RegisterVerifier(&plonkVerifier{
+		provingSystemId: common.GnarkPlonkBls12_381,
+		name:            "GnarkPlonkBls12_381",
+		alias:           "plonk_bls12_381",
+		curve:           ecc.BLS12_381,
+	})
+	common.RegisterVerifier(&plonkVerifier{
+		provingSystemId: common.GnarkPlonkBn254,
+		name:            "GnarkPlonkBn254",
+		alias:           "plonk_bn254",
+		curve:           ecc.BN254,
+	})
+	common.RegisterVerifier(&groth16Verifier{
+		provingSystemId: common.Groth16Bn254,
+		name:            "Groth16Bn254",
+		alias:           "groth16_bn254",
+		curve:           ecc.BN254,
+	})
+}
+
+type plonkVerifier struct {
+	provingSystemId common.ProvingSystemId
+	name            string
+	alias           string
+	curve           ecc.ID
+}
+
+func (v *plonkVerifier) ProvingSystemId() common.ProvingSystemId { return v.provingSystemId }
+func (v *plonkVerifier) Name() string                            { return v.name }
+func (v *plonkVerifier) Alias() string                           { return v.alias }
+
+func (v *plonkVerifier) Limits() common.VerificationDataLimits {
+	return common.VerificationDataLimits{}
+}
+
+func (v *plonkVerifier) Verify(_ context.Context, verificationData common.VerificationData) (bool, error) {
+	return VerifyPlonkProof(verificationData.Proof, verificationData.PubInput, verificationData.VerificationKey, v.curve)
+}
+
+type groth16Verifier struct {
+	provingSystemId common.ProvingSystemId
+	name            string
+	alias           string
+	curve           ecc.ID
+}
+
+func (v *groth16Verifier) ProvingSystemId() common.ProvingSystemId { return v.provingSystemId }
+func (v *groth16Verifier) Name() string                            { return v.name }
+func (v *groth16Verifier) Alias() string                           { return v.alias }
+
+func (v *groth16Verifier) Limits() common.VerificationDataLimits {
+	return common.VerificationDataLimits{}
+}
+
+func (v *groth16Verifier) Verify(_ context.Context, verificationData common.VerificationData) (bool, error) {
+	return VerifyGroth16Proof(verificationData.Proof, verificationData.PubInput, verificationData.VerificationKey, v.curve)
+}
+
+// VerifyPlonkProof verifies a PLONK proof on the given curve.
+// An error is returned if the proof, public input or verification key can't be deserialized.
+func VerifyPlonkProof(proofBytes []byte, pubInputBytes []byte, verificationKeyBytes []byte, curve ecc.ID) (bool, error) {
+	proof := plonk.NewProof(curve)
+	if _, err := proof.ReadFrom(bytes.NewReader(proofBytes)); err != nil {
+		return false, fmt.Errorf("%w: PLONK proof: %v", common.ErrDeserialization, err)
+	}
+
+	pubInput, err := readPubInput(pubInputBytes, curve)
+	if err != nil {
+		return false, fmt.Errorf("%w: PLONK public input: %v", common.ErrDeserialization, err)
+	}
+
+	verificationKey := plonk.NewVerifyingKey(curve)
+	if _, err = verificationKey.ReadFrom(bytes.NewReader(verificationKeyBytes)); err != nil {
+		return false, fmt.Errorf("%w: PLONK verifying key: %v", common.ErrDeserialization, err)
+	}
+
+	err = plonk.Verify(proof, verificationKey, pubInput)
+	return err == nil, nil
+}
+
+// VerifyGroth16Proof verifies a Groth16 proof on the given curve.
+// An error is returned if the proof, public input or verification key can't be deserialized.
+func VerifyGroth16Proof(proofBytes []byte, pubInputBytes []byte, verificationKeyBytes []byte, curve ecc.ID) (bool, error) {
+	proof := groth16.NewProof(curve)
+	if _, err := proof.ReadFrom(bytes.NewReader(proofBytes)); err != nil {
+		return false, fmt.Errorf("%w: Groth16 proof: %v", common.ErrDeserialization, err)
+	}
+
+	pubInput, err := readPubInput(pubInputBytes, curve)
+	if err != nil {
+		return false, fmt.Errorf("%w: Groth16 public input: %v", common.ErrDeserialization, err)
+	}
+
+	verificationKey := groth16.NewVerifyingKey(curve)
+	if _, err = verificationKey.ReadFrom(bytes.NewReader(verificationKeyBytes)); err != nil {
+		return false, fmt.Errorf("%w: Groth16 verifying key: %v", common.ErrDeserialization, err)
+	}
+
+	err = groth16.Verify(proof, verificationKey, pubInput)
+	return err == nil, nil
+}
+
+func readPubInput(pubInputBytes []byte, curve ecc.ID) (witness.Witness, error) {
+	pubInput, err := witness.New(curve.ScalarField())
+	if err != nil {
+		return nil, err
+	}
+	if _, err = pubInput.ReadFrom(bytes.NewReader(pubInputBytes)); err != nil {
+		return nil, err
+	}
+	return pubInput, nil
+}
@@ -0,0 +1,77 @@
+package gnark_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/yetanotherco/aligned_layer/common"
+	_ "github.com/yetanotherco/aligned_layer/operator/gnark"
+)
+
+func TestPlonkBn254ProofVerifies(t *testing.T) {
+	verifier, err := common.GetVerifierByAlias("plonk_bn254")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verificationData := readVerificationData(t, common.GnarkPlonkBn254, "../../task_sender/test_examples/gnark_plonk_bn254_script/")
+	verified, err := verifier.Verify(context.Background(), verificationData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verified {
+		t.Errorf("proof did not verify")
+	}
+}
+
+func TestMalformedProofReturnsError(t *testing.T) {
+	verifier, err := common.GetVerifier(common.GnarkPlonkBls12_381)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verificationData := readVerificationData(t, common.GnarkPlonkBls12_381, "../../task_sender/test_examples/gnark_plonk_bls12_381_script/")
+	verificationData.Proof = verificationData.Proof[:len(verificationData.Proof)/2]
+	verified, err := verifier.Verify(context.Background(), verificationData)
+	if !errors.Is(err, common.ErrDeserialization) {
+		t.Errorf("expected a deserialization error, got %v", err)
+	}
+	if verified {
+		t.Errorf("malformed proof verified")
+	}
+}
+
+func TestUnknownProvingSystem(t *testing.T) {
+	_, err := common.GetVerifier(common.ProvingSystemId(1000))
+	if !errors.Is(err, common.ErrUnsupportedProvingSystem) {
+		t.Fatalf("expected an unsupported proving system error, got %v", err)
+	}
+
+	verificationError := &common.VerificationError{BatchIndex: 3, ProvingSystemId: common.ProvingSystemId(1000), Err: err}
+	if verificationError.Reason() != common.ReasonUnsupportedProvingSystem {
+		t.Errorf("unexpected reason %s", verificationError.Reason())
+	}
+}
+
+func readVerificationData(t *testing.T, provingSystemId common.ProvingSystemId, dir string) common.VerificationData {
+	proof, err := os.ReadFile(dir + "plonk.proof")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubInput, err := os.ReadFile(dir + "plonk_pub_input.pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	verificationKey, err := os.ReadFile(dir + "plonk.vk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return common.VerificationData{
+		ProvingSystemId: provingSystemId,
+		Proof:           proof,
+		PubInput:        pubInput,
+		VerificationKey: verificationKey,
+	}
+}
@@ -0,0 +1,80 @@
+package halo2kzg
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/yetanotherco/aligned_layer/common"
+)
+
+// paramsHeaderSize is the size of the constraint system, verifier key and
+// kzg params lengths at the start of the serialized params
+const paramsHeaderSize = 12
+
+func init() {
+	common.RegisterVerifier(&verifier{})
+}
+
+type verifier struct{}
+
+func (v *verifier) ProvingSystemId() common.ProvingSystemId { return common.Halo2KZG }
+func (v *verifier) Name() string                            { return "Halo2KZG" }
+func (v *verifier) Alias() string                           { return "halo2_kzg" }
+
+func (v *verifier) Limits() common.VerificationDataLimits {
+	return common.VerificationDataLimits{
+		MaxProofSize:           MaxProofSize,
+		MaxPubInputSize:        MaxPublicInputSize,
+		MaxVerificationKeySize: paramsHeaderSize + MaxConstraintSystemSize + MaxVerifierKeySize + MaxKzgParamsSize,
+	}
+}
+
+// Verify expects the verification key field to hold the serialized params: the
+// little endian u32 lengths of the constraint system, verifier key and kzg params,
+// followed by each of them
+func (v *verifier) Verify(_ context.Context, verificationData common.VerificationData) (bool, error) {
+	paramsBytes := verificationData.VerificationKey
+	if len(paramsBytes) < paramsHeaderSize {
+		return false, fmt.Errorf("%w: Halo2-KZG params size %d is smaller than its header", common.ErrDeserialization, len(paramsBytes))
+	}
+
+	csLen := binary.LittleEndian.Uint32(paramsBytes[:4])
+	vkLen := binary.LittleEndian.Uint32(paramsBytes[4:8])
+	kzgParamsLen := binary.LittleEndian.Uint32(paramsBytes[8:12])
+	if csLen > MaxConstraintSystemSize || vkLen > MaxVerifierKeySize || kzgParamsLen > MaxKzgParamsSize {
+		return false, fmt.Errorf("%w: Halo2-KZG params lengths exceed max sizes: cs %d, vk %d, kzg params %d", common.ErrSizeLimit, csLen, vkLen, kzgParamsLen)
+	}
+
+	csOffset := uint32(paramsHeaderSize)
+	vkOffset := csOffset + csLen
+	kzgParamsOffset := vkOffset + vkLen
+	if uint64(kzgParamsOffset)+uint64(kzgParamsLen) > uint64(len(paramsBytes)) {
+		return false, fmt.Errorf("%w: Halo2-KZG params are truncated: expected %d bytes, got %d", common.ErrDeserialization, kzgParamsOffset+kzgParamsLen, len(paramsBytes))
+	}
+
+	proofBytes := make([]byte, MaxProofSize)
+	copy(proofBytes, verificationData.Proof)
+	proofLen := (uint32)(len(verificationData.Proof))
+
+	csBytes := make([]byte, MaxConstraintSystemSize)
+	copy(csBytes, paramsBytes[csOffset:vkOffset])
+
+	vkBytes := make([]byte, MaxVerifierKeySize)
+	copy(vkBytes, paramsBytes[vkOffset:kzgParamsOffset])
+
+	kzgParamsBytes := make([]byte, MaxKzgParamsSize)
+	copy(kzgParamsBytes, paramsBytes[kzgParamsOffset:kzgParamsOffset+kzgParamsLen])
+
+	publicInputBytes := make([]byte, MaxPublicInputSize)
+	copy(publicInputBytes, verificationData.PubInput)
+	publicInputLen := (uint32)(len(verificationData.PubInput))
+
+	return VerifyHalo2KzgProof(
+		([MaxProofSize]byte)(proofBytes), proofLen,
+		([MaxConstraintSystemSize]byte)(csBytes), csLen,
+		([MaxVerifierKeySize]byte)(vkBytes), vkLen,
+		([MaxKzgParamsSize]byte)(kzgParamsBytes), kzgParamsLen,
+		([MaxPublicInputSize]byte)(publicInputBytes), publicInputLen,
+	), nil
+}
@@ -0,0 +1,80 @@
+package operator
+
+import (
+	"context"
+)
+
+// Amount of recently processed batch merkle roots kept to skip duplicated events
+const processedBatchesCacheSize = 1024
+
+// backfillMissedBatches schedules the batches emitted since the last processed block,
+// looking back at most MaxBackfillBlocks blocks. Batches that were already responded
+// are skipped, since the operator signature is no longer needed.
+func (o *Operator) backfillMissedBatches(ctx context.Context) {
+	currentBlock, err := o.avsReader.GetBlockNumber(ctx)
+	if err != nil {
+		o.Logger.Error("Could not get current block, missed batches will not be backfilled", "err", err)
+		return
+	}
+
+	lastProcessedBlock, ok := o.blockCursor.Get()
+	if !ok {
+		// Nothing was processed before, start from the current block
+		o.updateBlockCursor(currentBlock)
+		return
+	}
+
+	// The last processed block is queried again, since the operator could have
+	// stopped before processing all of its batches
+	fromBlock, truncated := backfillFromBlock(lastProcessedBlock, currentBlock, o.Config.Operator.MaxBackfillBlocks)
+	if truncated {
+		o.Logger.Warn("Last processed block is older than the max backfill window, older batches will be skipped",
+			"lastProcessedBlock", lastProcessedBlock, "maxBackfillBlocks", o.Config.Operator.MaxBackfillBlocks)
+	}
+
+	o.Logger.Info("Backfilling missed batches", "fromBlock", fromBlock, "toBlock", currentBlock)
+	newBatchLogs, err := o.avsReader.FilterNewBatches(ctx, fromBlock, currentBlock)
+	if err != nil {
+		o.Logger.Error("Could not get missed batches", "err", err)
+		return
+	}
+
+	for _, newBatchLog := range newBatchLogs {
+		if o.processedBatches.Contains(newBatchLog.BatchMerkleRoot) {
+			continue
+		}
+
+		responded, err := o.avsReader.IsBatchResponded(newBatchLog.BatchMerkleRoot)
+		if err != nil {
+			o.Logger.Error("Could not check if batch was responded", "err", err)
+			return
+		}
+		if responded {
+			o.Logger.Info("Skipping already responded batch", "batch merkle root", newBatchLog.BatchMerkleRoot)
+			o.processedBatches.Add(newBatchLog.BatchMerkleRoot, struct{}{})
+			continue
+		}
+
+		o.scheduleBatch(ctx, newBatchLog)
+	}
+
+	// The cursor only moves past the scheduled batches once they are processed
+	o.batchScheduler.Advance(currentBlock)
+}
+
+// backfillFromBlock returns the first block to backfill, which is the last processed one
+// unless it is more than maxBackfillBlocks behind the current block. In that case
+// the returned block is maxBackfillBlocks behind, and truncated is true.
+func backfillFromBlock(lastProcessedBlock uint64, currentBlock uint64, maxBackfillBlocks uint64) (fromBlock uint64, truncated bool) {
+	if currentBlock > maxBackfillBlocks && lastProcessedBlock < currentBlock-maxBackfillBlocks {
+		return currentBlock - maxBackfillBlocks, true
+	}
+	return lastProcessedBlock, false
+}
+
+func (o *Operator) updateBlockCursor(block uint64) {
+	err := o.blockCursor.Update(block)
+	if err != nil {
+		o.Logger.Warn("Could not persist last processed block", "block", block, "err", err)
+	}
+}
@@ -0,0 +1,28 @@
+package operator
+
+import "testing"
+
+func TestBackfillFromBlock(t *testing.T) {
+	tests := []struct {
+		name               string
+		lastProcessedBlock uint64
+		currentBlock       uint64
+		maxBackfillBlocks  uint64
+		fromBlock          uint64
+		truncated          bool
+	}{
+		{"within the window", 950, 1000, 100, 950, false},
+		{"at the window start", 900, 1000, 100, 900, false},
+		{"older than the window", 800, 1000, 100, 900, true},
+		{"chain shorter than the window", 10, 50, 100, 10, false},
+		{"already at the current block", 1000, 1000, 100, 1000, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fromBlock, truncated := backfillFromBlock(test.lastProcessedBlock, test.currentBlock, test.maxBackfillBlocks)
+			if fromBlock != test.fromBlock || truncated != test.truncated {
+				t.Fatalf("expected block %d (truncated %t), got %d (truncated %t)", test.fromBlock, test.truncated, fromBlock, truncated)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package operator
+
+import (
+	"github.com/yetanotherco/aligned_layer/merkle"
+)
+
+func NewVerificationDataCommitment(verificationData VerificationData) merkle.VerificationDataCommitment {
+	return merkle.NewVerificationDataCommitment(verificationData)
+}
+
+// BatchMerkleTree builds the merkle tree of the batch the same way the batcher does
+func BatchMerkleTree(batch []VerificationData) (*merkle.MerkleTree, error) {
+	return merkle.BatchMerkleTree(batch)
+}
+
+func BatchMerkleRoot(batch []VerificationData) ([32]byte, error) {
+	return merkle.BatchMerkleRoot(batch)
+}
@@ -0,0 +1,245 @@
+package operator
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+)
+
+var ErrBatchQueueFull = errors.New("batch queue is full")
+
+type NewBatchLog = servicemanager.ContractAlignedLayerServiceManagerNewBatch
+
+// BatchHandler processes a batch, the context is cancelled when the batch deadline is reached
+type BatchHandler func(ctx context.Context, newBatchLog *NewBatchLog)
+
+type scheduledBatch struct {
+	newBatchLog *NewBatchLog
+	deadline    time.Time
+}
+
+// batchQueue is a heap of batches ordered by the block and log index where
+// they were emitted, so the oldest batch is processed first
+type batchQueue []*scheduledBatch
+
+func (q batchQueue) Len() int { return len(q) }
+
+func (q batchQueue) Less(i, j int) bool {
+	a, b := q[i].newBatchLog.Raw, q[j].newBatchLog.Raw
+	if a.BlockNumber != b.BlockNumber {
+		return a.BlockNumber < b.BlockNumber
+	}
+	return a.Index < b.Index
+}
+
+func (q batchQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *batchQueue) Push(x any) { *q = append(*q, x.(*scheduledBatch)) }
+
+func (q *batchQueue) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return item
+}
+
+// BatchScheduler processes up to parallelBatches batches at the same time,
+// oldest first. Batches wait in a bounded queue and are abandoned once their
+// deadline is reached, since the aggregator no longer accepts their signatures.
+type BatchScheduler struct {
+	handle          BatchHandler
+	parallelBatches int
+	maxQueueSize    int
+
+	// Called when a batch is dropped without being processed
+	onExpired func(newBatchLog *NewBatchLog)
+	// Called with the newest block whose batches, and all the previous ones, are done
+	onProgress func(block uint64)
+	// Called with the amount of queued batches when it changes
+	onQueueSize func(size int)
+
+	mutex            sync.Mutex
+	queue            batchQueue
+	pendingBlocks    map[uint64]int
+	highestDoneBlock uint64
+	wake             chan struct{}
+}
+
+func NewBatchScheduler(parallelBatches int, maxQueueSize int, handle BatchHandler) *BatchScheduler {
+	return &BatchScheduler{
+		handle:          handle,
+		parallelBatches: max(parallelBatches, 1),
+		maxQueueSize:    max(maxQueueSize, 1),
+		onExpired:       func(*NewBatchLog) {},
+		onProgress:      func(uint64) {},
+		onQueueSize:     func(int) {},
+		pendingBlocks:   make(map[uint64]int),
+		wake:            make(chan struct{}, 1),
+	}
+}
+
+// Submit queues a batch to be processed before the deadline, it is dropped if the deadline already passed.
+// ErrBatchQueueFull is returned if there is no room for it after removing the expired batches.
+func (s *BatchScheduler) Submit(newBatchLog *NewBatchLog, deadline time.Time) error {
+	s.mutex.Lock()
+	if !time.Now().Before(deadline) {
+		// The aggregator no longer accepts its signatures, it is done right away
+		s.onExpired(newBatchLog)
+		s.mutex.Unlock()
+		s.Advance(newBatchLog.Raw.BlockNumber)
+		return nil
+	}
+	if len(s.queue) >= s.maxQueueSize {
+		s.removeExpired(time.Now())
+	}
+	if len(s.queue) >= s.maxQueueSize {
+		s.mutex.Unlock()
+		return ErrBatchQueueFull
+	}
+	heap.Push(&s.queue, &scheduledBatch{newBatchLog: newBatchLog, deadline: deadline})
+	s.pendingBlocks[newBatchLog.Raw.BlockNumber]++
+	s.onQueueSize(len(s.queue))
+	s.mutex.Unlock()
+
+	s.signal()
+	return nil
+}
+
+// Advance marks every batch up to the given block as done, except the ones still queued or in process
+func (s *BatchScheduler) Advance(block uint64) {
+	s.mutex.Lock()
+	s.highestDoneBlock = max(s.highestDoneBlock, block)
+	progress := s.progress()
+	s.mutex.Unlock()
+
+	s.onProgress(progress)
+}
+
+// Run processes the queued batches until ctx is done, and then returns once the
+// batches in process finish. These are only cancelled when abortCtx is done.
+func (s *BatchScheduler) Run(ctx context.Context, abortCtx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < s.parallelBatches; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				batch, ok := s.next(ctx)
+				if !ok {
+					return
+				}
+				s.process(abortCtx, batch)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *BatchScheduler) process(ctx context.Context, batch *scheduledBatch) {
+	defer s.done(batch.newBatchLog)
+
+	batchCtx, cancel := context.WithDeadline(ctx, batch.deadline)
+	defer cancel()
+	s.handle(batchCtx, batch.newBatchLog)
+}
+
+// next waits for the oldest unexpired batch, dropping the expired ones found before it
+func (s *BatchScheduler) next(ctx context.Context) (*scheduledBatch, bool) {
+	for {
+		if ctx.Err() != nil {
+			return nil, false
+		}
+		s.mutex.Lock()
+		for len(s.queue) > 0 {
+			batch := heap.Pop(&s.queue).(*scheduledBatch)
+			if time.Now().Before(batch.deadline) {
+				remaining := len(s.queue)
+				s.onQueueSize(remaining)
+				s.mutex.Unlock()
+				// Other workers may be waiting for the remaining batches
+				if remaining > 0 {
+					s.signal()
+				}
+				return batch, true
+			}
+			s.expire(batch)
+		}
+		s.onQueueSize(0)
+		s.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-s.wake:
+		}
+	}
+}
+
+// removeExpired drops the queued batches whose deadline is before now. Must be called with the mutex held.
+func (s *BatchScheduler) removeExpired(now time.Time) {
+	queue := s.queue[:0]
+	for _, batch := range s.queue {
+		if now.Before(batch.deadline) {
+			queue = append(queue, batch)
+		} else {
+			s.expire(batch)
+		}
+	}
+	clear(s.queue[len(queue):])
+	s.queue = queue
+	heap.Init(&s.queue)
+}
+
+// expire drops a batch without processing it. Must be called with the mutex held.
+func (s *BatchScheduler) expire(batch *scheduledBatch) {
+	s.onExpired(batch.newBatchLog)
+	s.removePending(batch.newBatchLog.Raw.BlockNumber)
+}
+
+func (s *BatchScheduler) done(newBatchLog *NewBatchLog) {
+	s.mutex.Lock()
+	s.removePending(newBatchLog.Raw.BlockNumber)
+	progress := s.progress()
+	s.mutex.Unlock()
+
+	s.onProgress(progress)
+}
+
+// removePending must be called with the mutex held
+func (s *BatchScheduler) removePending(block uint64) {
+	s.highestDoneBlock = max(s.highestDoneBlock, block)
+	s.pendingBlocks[block]--
+	if s.pendingBlocks[block] <= 0 {
+		delete(s.pendingBlocks, block)
+	}
+}
+
+// progress returns the oldest block with pending batches, or the newest done
+// block if there are none. Must be called with the mutex held.
+func (s *BatchScheduler) progress() uint64 {
+	if len(s.pendingBlocks) == 0 {
+		return s.highestDoneBlock
+	}
+	oldest := uint64(0)
+	first := true
+	for block := range s.pendingBlocks {
+		if first || block < oldest {
+			oldest = block
+			first = false
+		}
+	}
+	return oldest
+}
+
+func (s *BatchScheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
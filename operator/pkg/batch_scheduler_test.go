@@ -0,0 +1,55 @@
+package operator_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+)
+
+func TestBatchSchedulerDropsExpiredBatches(t *testing.T) {
+	var mutex sync.Mutex
+	var handled []uint64
+	scheduler := operator.NewBatchScheduler(1, 4, func(ctx context.Context, newBatchLog *operator.NewBatchLog) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		handled = append(handled, newBatchLog.Raw.BlockNumber)
+	})
+
+	expired := &operator.NewBatchLog{BatchMerkleRoot: [32]byte{1}, Raw: gethtypes.Log{BlockNumber: 1}}
+	pending := &operator.NewBatchLog{BatchMerkleRoot: [32]byte{2}, Raw: gethtypes.Log{BlockNumber: 2}}
+	if err := scheduler.Submit(expired, time.Now().Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if err := scheduler.Submit(pending, time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scheduler.Run(ctx, context.Background())
+	}()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		mutex.Lock()
+		count := len(handled)
+		mutex.Unlock()
+		if count > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(handled) != 1 || handled[0] != 2 {
+		t.Fatalf("expected only the batch within its deadline to be processed, got blocks %v", handled)
+	}
+}
@@ -0,0 +1,83 @@
+package operator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BlockCursor keeps track of the last block whose NewBatch events were
+// processed by the operator, and persists it to disk so missed batches
+// can be backfilled after a restart
+type BlockCursor struct {
+	filePath  string
+	lastBlock uint64
+	isSet     bool
+	mutex     *sync.Mutex
+}
+
+func NewBlockCursor(filePath string) (*BlockCursor, error) {
+	cursor := &BlockCursor{
+		filePath: filePath,
+		mutex:    &sync.Mutex{},
+	}
+
+	content, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return cursor, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lastBlock, err := strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	cursor.lastBlock = lastBlock
+	cursor.isSet = true
+
+	return cursor, nil
+}
+
+// Get returns the last processed block, and false if no block was processed yet
+func (c *BlockCursor) Get() (uint64, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.lastBlock, c.isSet
+}
+
+// Update moves the cursor to the given block and persists it.
+// Blocks older than the current one are ignored.
+func (c *BlockCursor) Update(block uint64) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.isSet && block <= c.lastBlock {
+		return nil
+	}
+
+	// Write to a temporary file and rename it, so a crash never leaves a half written cursor
+	tmpFilePath := c.filePath + ".tmp"
+	err := os.MkdirAll(filepath.Dir(c.filePath), 0755)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(tmpFilePath, []byte(strconv.FormatUint(block, 10)), 0644)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmpFilePath, c.filePath)
+	if err != nil {
+		return err
+	}
+
+	c.lastBlock = block
+	c.isSet = true
+
+	return nil
+}
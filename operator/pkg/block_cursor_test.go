@@ -0,0 +1,51 @@
+package operator_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+)
+
+func TestBlockCursorPersistsLastBlock(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "state", "last_processed_block")
+
+	cursor, err := operator.NewBlockCursor(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cursor.Get(); ok {
+		t.Fatal("expected a new cursor to be unset")
+	}
+
+	if err = cursor.Update(100); err != nil {
+		t.Fatal(err)
+	}
+	// Older blocks don't move the cursor back
+	if err = cursor.Update(90); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := operator.NewBlockCursor(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block, ok := reopened.Get(); !ok || block != 100 {
+		t.Fatalf("expected block 100 after reopening, got %d", block)
+	}
+	if _, err = os.Stat(filePath + ".tmp"); !os.IsNotExist(err) {
+		t.Error("temporary cursor file was left behind")
+	}
+}
+
+func TestBlockCursorRejectsCorruptedFile(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "last_processed_block")
+	if err := os.WriteFile(filePath, []byte("not a block"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := operator.NewBlockCursor(filePath); err == nil {
+		t.Fatal("expected a corrupted cursor file to be rejected")
+	}
+}
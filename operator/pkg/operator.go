@@ -1,36 +1,32 @@
 package operator
 
 import (
-	"bytes"
 	"context"
 	"crypto/ecdsa"
-	"encoding/binary"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"github.com/ethereum/go-ethereum/common/lru"
 	"github.com/ethereum/go-ethereum/crypto"
 	"log"
+	"net/url"
 	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/yetanotherco/aligned_layer/metrics"
-
-	"github.com/yetanotherco/aligned_layer/operator/halo2ipa"
-	"github.com/yetanotherco/aligned_layer/operator/halo2kzg"
-	"github.com/yetanotherco/aligned_layer/operator/sp1"
+	"github.com/yetanotherco/aligned_layer/operator/fetcher"
 
 	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
 	"github.com/Layr-Labs/eigensdk-go/logging"
 	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
-	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark/backend/groth16"
-	"github.com/consensys/gnark/backend/plonk"
-	"github.com/consensys/gnark/backend/witness"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/yetanotherco/aligned_layer/common"
 	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
 	"github.com/yetanotherco/aligned_layer/core/chainio"
 	"github.com/yetanotherco/aligned_layer/core/types"
+	"github.com/yetanotherco/aligned_layer/core/utils"
 
 	"github.com/yetanotherco/aligned_layer/core/config"
 )
@@ -41,14 +37,27 @@ type Operator struct {
 	Socket             string
 	Timeout            time.Duration
 	PrivKey            *ecdsa.PrivateKey
-	KeyPair            *bls.KeyPair
 	OperatorId         eigentypes.OperatorId
 	avsSubscriber      chainio.AvsSubscriber
+	avsReader          chainio.AvsReader
 	NewTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatch
 	Logger             logging.Logger
 	aggRpcClient       AggregatorRpcClient
 	metricsReg         *prometheus.Registry
 	metrics            *metrics.Metrics
+	// Last block whose batches were processed, used to backfill missed batches
+	blockCursor *BlockCursor
+	// Bounds the resources used to verify the proofs of a batch
+	verificationPool  *VerificationPool
+	verificationCache *VerificationCache
+	batchFetcher      *fetcher.BatchFetcher
+	// Processes the received batches concurrently, oldest first
+	batchScheduler *BatchScheduler
+	// Recently processed batches, to avoid processing twice a batch
+	// received both from the backfill and the live subscription
+	processedBatches lru.BasicLRU[[32]byte, struct{}]
+	// Messages being sent to the aggregator, waited for on shutdown
+	pendingSends sync.WaitGroup
 	//Socket  string
 	//Timeout time.Duration
 }
@@ -56,7 +65,7 @@ type Operator struct {
 func NewOperatorFromConfig(configuration config.OperatorConfig) (*Operator, error) {
 	logger := configuration.BaseConfig.Logger
 
-	avsReader, err := chainio.NewAvsReaderFromConfig(configuration.BaseConfig, configuration.EcdsaConfig)
+	avsReader, err := chainio.NewAvsReaderFromConfig(configuration.BaseConfig)
 	if err != nil {
 		log.Fatalf("Could not create AVS reader")
 	}
@@ -68,13 +77,12 @@ func NewOperatorFromConfig(configuration config.OperatorConfig) (*Operator, erro
 
 	if !registered {
 		log.Println("Operator is not registered with AlignedLayer AVS, registering...")
-		quorumNumbers := []byte{0}
 
 		// Generate salt and expiry
-		privateKeyBytes := []byte(configuration.BlsConfig.KeyPair.PrivKey.String())
+		publicKeyBytes := configuration.BlsConfig.Signer.PubKeyG1().Serialize()
 		salt := [32]byte{}
 
-		copy(salt[:], crypto.Keccak256([]byte("churn"), []byte(time.Now().String()), quorumNumbers, privateKeyBytes))
+		copy(salt[:], crypto.Keccak256([]byte("churn"), []byte(time.Now().String()), publicKeyBytes))
 
 		err = RegisterOperator(context.Background(), &configuration, salt)
 		if err != nil {
@@ -88,32 +96,112 @@ func NewOperatorFromConfig(configuration config.OperatorConfig) (*Operator, erro
 	}
 	newTaskCreatedChan := make(chan *servicemanager.ContractAlignedLayerServiceManagerNewBatch)
 
-	rpcClient, err := NewAggregatorRpcClient(configuration.Operator.AggregatorServerIpPortAddress, logger)
+	aggregatorTlsConfig, err := newAggregatorTlsConfig(configuration)
+	if err != nil {
+		return nil, fmt.Errorf("could not load aggregator TLS config: %s", err)
+	}
+	rpcClient, err := NewAggregatorRpcClient(AggregatorRpcClientConfig{
+		AggregatorIpPortAddr: configuration.Operator.AggregatorServerIpPortAddress,
+		Api:                  configuration.Operator.AggregatorRpcApi,
+		TlsConfig:            aggregatorTlsConfig,
+		Signer:               configuration.EcdsaConfig.Signer,
+		Domain: types.RequestDomain{
+			ChainId:               configuration.BaseConfig.ChainId,
+			ServiceManagerAddress: configuration.BaseConfig.AlignedLayerDeploymentConfig.AlignedLayerServiceManagerAddr,
+		},
+	}, logger)
 	if err != nil {
 		return nil, fmt.Errorf("Could not create RPC client: %s. Is aggregator running?", err)
 	}
 
-	operatorId := eigentypes.OperatorIdFromKeyPair(configuration.BlsConfig.KeyPair)
+	blockCursor, err := NewBlockCursor(configuration.Operator.LastProcessedBlockFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not load last processed block: %s", err)
+	}
+
+	operatorId := eigentypes.OperatorIdFromPubkey(configuration.BlsConfig.Signer.PubKeyG1())
 	address := configuration.Operator.Address
 
 	// Metrics
 	reg := prometheus.NewRegistry()
 	operatorMetrics := metrics.NewMetrics(configuration.Operator.MetricsIpPortAddress, reg, logger)
 
+	var verificationCache *VerificationCache
+	if !configuration.Operator.DisableVerificationCache {
+		verificationCache, err = NewVerificationCache(
+			configuration.Operator.VerificationCacheSize,
+			configuration.Operator.VerificationCacheTtl,
+			configuration.Operator.VerificationCachePath,
+			operatorMetrics,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("could not open verification cache: %s", err)
+		}
+	}
+
+	verificationPool, err := NewVerificationPoolFromLimitsByName(
+		configuration.Operator.VerificationWorkers,
+		configuration.Operator.VerificationConcurrencyLimits,
+		configuration.Operator.ProofVerificationTimeout,
+		verificationCache,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	batchFetcher, err := fetcher.NewBatchFetcher(fetcher.Config{
+		MaxBatchSize:   configuration.Operator.MaxBatchSize,
+		Mirrors:        configuration.Operator.BatchMirrors,
+		FilesDir:       configuration.Operator.BatchFilesDir,
+		IpfsGatewayUrl: configuration.Operator.IpfsGatewayUrl,
+		S3Region:       configuration.Operator.S3Region,
+		S3Endpoint:     configuration.Operator.S3Endpoint,
+		Timeout:        configuration.Operator.BatchFetchTimeout,
+		Http: fetcher.HttpConfig{
+			ConnectTimeout: configuration.Operator.BatchFetchConnectTimeout,
+			ReadTimeout:    configuration.Operator.BatchFetchReadTimeout,
+			MaxRetries:     configuration.Operator.BatchFetchMaxRetries,
+			InitialBackoff: configuration.Operator.BatchFetchRetryBackoff,
+			MaxBackoff:     configuration.Operator.BatchFetchMaxRetryBackoff,
+		},
+	}, operatorMetrics, logger)
+	if err != nil {
+		return nil, fmt.Errorf("could not create batch fetcher: %s", err)
+	}
+
 	operator := &Operator{
 		Config:             configuration,
 		Logger:             logger,
 		avsSubscriber:      *avsSubscriber,
+		avsReader:          *avsReader,
 		Address:            address,
 		NewTaskCreatedChan: newTaskCreatedChan,
 		aggRpcClient:       *rpcClient,
 		OperatorId:         operatorId,
 		metricsReg:         reg,
 		metrics:            operatorMetrics,
+		blockCursor:        blockCursor,
+		verificationPool:   verificationPool,
+		verificationCache:  verificationCache,
+		batchFetcher:       batchFetcher,
+		processedBatches:   lru.NewBasicLRU[[32]byte, struct{}](processedBatchesCacheSize),
 		// Timeout
 		// Socket
 	}
 
+	operator.batchScheduler = NewBatchScheduler(
+		configuration.Operator.ParallelBatches,
+		configuration.Operator.BatchQueueSize,
+		operator.handleNewBatchLog,
+	)
+	operator.batchScheduler.onExpired = func(newBatchLog *NewBatchLog) {
+		operatorMetrics.IncOperatorExpiredBatches()
+		logger.Warn("Batch response window expired before processing it, skipping",
+			"batch merkle root", hex.EncodeToString(newBatchLog.BatchMerkleRoot[:]))
+	}
+	operator.batchScheduler.onProgress = operator.updateBlockCursor
+	operator.batchScheduler.onQueueSize = operatorMetrics.SetOperatorBatchQueueSize
+
 	return operator, nil
 }
 
@@ -122,9 +210,22 @@ func (o *Operator) SubscribeToNewTasks() event.Subscription {
 	return sub
 }
 
+// Start processes the new batches until ctx is done, then waits for the
+// batches in process and returns. ErrShutdownTimeout is returned if they
+// don't finish within the configured shutdown timeout.
 func (o *Operator) Start(ctx context.Context) error {
 	sub := o.SubscribeToNewTasks()
 
+	abortCtx, abort := context.WithCancel(context.Background())
+	defer abort()
+	schedulerDone := make(chan struct{})
+	go func() {
+		o.batchScheduler.Run(ctx, abortCtx)
+		close(schedulerDone)
+	}()
+
+	o.backfillMissedBatches(ctx)
+
 	var metricsErrChan <-chan error
 	if o.Config.Operator.EnableMetrics {
 		metricsErrChan = o.metrics.Start(ctx, o.metricsReg)
@@ -134,294 +235,265 @@ func (o *Operator) Start(ctx context.Context) error {
 
 	for {
 		select {
-		case <-context.Background().Done():
+		case <-ctx.Done():
 			o.Logger.Info("Operator shutting down...")
-			return nil
+			sub.Unsubscribe()
+			return o.shutdown(schedulerDone, abort)
 		case err := <-metricsErrChan:
 			o.Logger.Fatal("Metrics server failed", "err", err)
 		case err := <-sub.Err():
 			o.Logger.Infof("Error in websocket subscription", "err", err)
 			sub.Unsubscribe()
 			sub = o.SubscribeToNewTasks()
+			o.backfillMissedBatches(ctx)
 		case newBatchLog := <-o.NewTaskCreatedChan:
-			err := o.ProcessNewBatchLog(newBatchLog)
-			if err != nil {
-				o.Logger.Infof("batch %x did not verify. Err: %v", newBatchLog.BatchMerkleRoot, err)
-				continue
-			}
-			responseSignature := o.SignTaskResponse(newBatchLog.BatchMerkleRoot)
+			o.scheduleBatch(ctx, newBatchLog)
+		}
+	}
+}
+
+// shutdown waits for the batches in process and the messages to the aggregator,
+// aborting them once the shutdown timeout is reached, and closes the operator storage
+func (o *Operator) shutdown(schedulerDone <-chan struct{}, abort context.CancelFunc) error {
+	deadline := time.Now().Add(o.Config.Operator.ShutdownTimeout)
+	var err error
+
+	select {
+	case <-schedulerDone:
+	case <-time.After(time.Until(deadline)):
+		o.Logger.Warn("Batches in process did not finish before the shutdown timeout, aborting them")
+		abort()
+		<-schedulerDone
+		err = utils.ErrShutdownTimeout
+	}
+
+	if waitErr := utils.WaitWithTimeout(&o.pendingSends, time.Until(deadline)); waitErr != nil {
+		o.Logger.Warn("Messages to the aggregator were not sent before the shutdown timeout")
+		err = waitErr
+	}
+
+	// The block cursor is persisted on every update, only the cache needs to be flushed
+	if o.verificationCache != nil {
+		if closeErr := o.verificationCache.Close(); closeErr != nil {
+			o.Logger.Warn("Could not close verification cache", "err", closeErr)
+		}
+	}
 
-			signedTaskResponse := types.SignedTaskResponse{
+	o.Logger.Info("Operator stopped")
+	return err
+}
+
+// sendToAggregator runs send in the background, shutdown waits for it to finish
+func (o *Operator) sendToAggregator(send func()) {
+	o.pendingSends.Add(1)
+	go func() {
+		defer o.pendingSends.Done()
+		send()
+	}()
+}
+
+// scheduleBatch queues the batch to be processed before the aggregator stops
+// accepting signatures for it. Must only be called from the event loop.
+func (o *Operator) scheduleBatch(ctx context.Context, newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatch) {
+	if o.processedBatches.Contains(newBatchLog.BatchMerkleRoot) {
+		o.Logger.Info("Batch already processed, skipping", "batch merkle root", newBatchLog.BatchMerkleRoot)
+		return
+	}
+	o.processedBatches.Add(newBatchLog.BatchMerkleRoot, struct{}{})
+
+	// The aggregator response window starts around when the batch is emitted, which is
+	// before it is received by the operator, specially for backfilled batches. These
+	// are dropped by the scheduler if the window is already over
+	createdAt, err := o.avsReader.GetBlockTimestamp(ctx, newBatchLog.Raw.BlockNumber)
+	if err != nil {
+		o.Logger.Warn("Could not get batch block timestamp, using the current time", "block", newBatchLog.Raw.BlockNumber, "err", err)
+		createdAt = time.Now()
+	}
+	deadline := createdAt.Add(o.Config.Operator.TaskResponseWindow)
+
+	err = o.batchScheduler.Submit(newBatchLog, deadline)
+	if err != nil {
+		o.metrics.IncOperatorDroppedBatches()
+		o.Logger.Error("Could not schedule batch, skipping",
+			"batch merkle root", hex.EncodeToString(newBatchLog.BatchMerkleRoot[:]),
+			"err", err)
+		o.batchScheduler.Advance(newBatchLog.Raw.BlockNumber)
+	}
+}
+
+// handleNewBatchLog verifies the batch and, if every proof is valid, signs its
+// merkle root and sends the signature to the aggregator
+func (o *Operator) handleNewBatchLog(ctx context.Context, newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatch) {
+	err := o.ProcessNewBatchLog(ctx, newBatchLog)
+	if ctx.Err() != nil {
+		o.metrics.IncOperatorExpiredBatches()
+		o.Logger.Warn("Batch response window expired while processing it, not signing",
+			"batch merkle root", hex.EncodeToString(newBatchLog.BatchMerkleRoot[:]))
+		return
+	}
+	if err != nil {
+		o.Logger.Infof("batch %x did not verify. Err: %v", newBatchLog.BatchMerkleRoot, err)
+		var verificationError *common.VerificationError
+		if errors.As(err, &verificationError) {
+			report := &types.VerificationFailureReport{
 				BatchMerkleRoot: newBatchLog.BatchMerkleRoot,
-				BlsSignature:    *responseSignature,
 				OperatorId:      o.OperatorId,
+				BatchIndex:      uint32(verificationError.BatchIndex),
+				ProvingSystem:   verificationError.ProvingSystemId.String(),
+				Reason:          verificationError.Reason(),
+				Message:         verificationError.Err.Error(),
 			}
-
-			o.Logger.Infof("Signed hash: %+v", *responseSignature)
-			go o.aggRpcClient.SendSignedTaskResponseToAggregator(&signedTaskResponse)
+			o.sendToAggregator(func() {
+				o.aggRpcClient.SendVerificationFailureToAggregator(report)
+			})
 		}
+		return
 	}
+	responseSignature, err := o.SignTaskResponse(ctx, newBatchLog.BatchMerkleRoot)
+	if err != nil {
+		o.Logger.Error("Could not sign the batch merkle root",
+			"batch merkle root", hex.EncodeToString(newBatchLog.BatchMerkleRoot[:]), "err", err)
+		return
+	}
+
+	signedTaskResponse := types.SignedTaskResponse{
+		BatchMerkleRoot: newBatchLog.BatchMerkleRoot,
+		BlsSignature:    *responseSignature,
+		OperatorId:      o.OperatorId,
+	}
+
+	o.Logger.Infof("Signed hash: %+v", *responseSignature)
+
+	// The aggregator stops accepting signatures for the batch when its response window ends
+	deadline, _ := ctx.Deadline()
+	o.sendToAggregator(func() {
+		sendCtx, cancel := context.WithDeadline(context.Background(), deadline)
+		defer cancel()
+		o.aggRpcClient.SendSignedTaskResponseToAggregator(sendCtx, &signedTaskResponse)
+	})
 }
 
 // Takes a NewTaskCreatedLog struct as input and returns a TaskResponseHeader struct.
 // The TaskResponseHeader struct is the struct that is signed and sent to the contract as a task response.
-func (o *Operator) ProcessNewBatchLog(newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatch) error {
+func (o *Operator) ProcessNewBatchLog(ctx context.Context, newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatch) error {
 
 	o.Logger.Info("Received new batch with proofs to verify",
 		"batch merkle root", newBatchLog.BatchMerkleRoot,
 	)
 
-	verificationDataBatch, err := o.getBatchFromS3(newBatchLog.BatchDataPointer)
+	sources, err := o.batchFetcher.Sources(newBatchLog.BatchDataPointer)
 	if err != nil {
-		o.Logger.Errorf("Could not get proofs from S3 bucket: %v", err)
 		return err
 	}
 
-	verificationDataBatchLen := len(verificationDataBatch)
-	results := make(chan bool, verificationDataBatchLen)
-	var wg sync.WaitGroup
-	wg.Add(verificationDataBatchLen)
-	for _, verificationData := range verificationDataBatch {
-		go func(data VerificationData) {
-			defer wg.Done()
-			o.verify(data, results)
-			o.metrics.IncOperatorTaskResponses()
-		}(verificationData)
-	}
-
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
+	// The batch storage is not trusted, the proofs are verified while the batch is
+	// downloaded but the result is only used if it matches the batch merkle root
+	errs := make([]error, 0, len(sources))
+	for _, source := range sources {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		failures, err := o.fetchAndVerifyBatch(ctx, source, newBatchLog.BatchMerkleRoot)
+		if err != nil {
+			if errors.Is(err, fetcher.ErrMerkleRootMismatch) {
+				o.metrics.IncOperatorMerkleRootMismatches()
+			}
+			o.Logger.Warn("Could not get batch",
+				"batch merkle root", hex.EncodeToString(newBatchLog.BatchMerkleRoot[:]),
+				"source", source.Redacted(),
+				"err", err)
+			errs = append(errs, fetcher.SourceError(source, err))
+			continue
+		}
 
-	for result := range results {
-		if !result {
-			return fmt.Errorf("invalid proof")
+		for _, failure := range failures {
+			o.metrics.IncOperatorVerificationFailures(failure.ProvingSystemId.String(), failure.Reason())
+			o.Logger.Error("Proof verification failed",
+				"batch index", failure.BatchIndex,
+				"proving system", failure.ProvingSystemId.String(),
+				"reason", failure.Reason(),
+				"err", failure.Err)
+		}
+		if len(failures) > 0 {
+			return failures[0]
 		}
+		return nil
 	}
 
-	return nil
+	o.Logger.Error("Could not get batch from any source, refusing to sign",
+		"batch merkle root", hex.EncodeToString(newBatchLog.BatchMerkleRoot[:]),
+		"batch data pointer", newBatchLog.BatchDataPointer)
+	return errors.Join(errs...)
 }
 
-func (o *Operator) verify(verificationData VerificationData, results chan bool) {
-	switch verificationData.ProvingSystemId {
-	case common.GnarkPlonkBls12_381:
-		verificationResult := o.verifyPlonkProofBLS12_381(verificationData.Proof, verificationData.PubInput, verificationData.VerificationKey)
-		o.Logger.Infof("PLONK BLS12-381 proof verification result: %t", verificationResult)
-
-		results <- verificationResult
-
-	case common.GnarkPlonkBn254:
-		verificationResult := o.verifyPlonkProofBN254(verificationData.Proof, verificationData.PubInput, verificationData.VerificationKey)
-		o.Logger.Infof("PLONK BN254 proof verification result: %t", verificationResult)
-
-		results <- verificationResult
-
-	case common.Groth16Bn254:
-		verificationResult := o.verifyGroth16ProofBN254(verificationData.Proof, verificationData.PubInput, verificationData.VerificationKey)
-
-		o.Logger.Infof("GROTH16 BN254 proof verification result: %t", verificationResult)
-		results <- verificationResult
-
-	case common.SP1:
-		proofLen := (uint32)(len(verificationData.Proof))
-		elfLen := (uint32)(len(verificationData.VmProgramCode))
-
-		verificationResult := sp1.VerifySp1Proof(verificationData.Proof, proofLen, verificationData.VmProgramCode, elfLen)
-		o.Logger.Infof("SP1 proof verification result: %t", verificationResult)
-		results <- verificationResult
-	case common.Halo2IPA:
-		// Extract Proof Bytes
-		proofBytes := make([]byte, halo2ipa.MaxProofSize)
-		copy(proofBytes, verificationData.Proof)
-		proofLen := (uint32)(len(verificationData.Proof))
-
-		// Extract Verification Key Bytes
-		paramsBytes := verificationData.VerificationKey
-
-		// Deserialize csLen
-		csLenBuffer := make([]byte, 4)
-		copy(csLenBuffer, paramsBytes[:4])
-		csLen := (uint32)(binary.LittleEndian.Uint32(csLenBuffer))
-
-		// Deserialize vkLen
-		vkLenBuffer := make([]byte, 4)
-		copy(vkLenBuffer, paramsBytes[4:8])
-		vkLen := (uint32)(binary.LittleEndian.Uint32(vkLenBuffer))
-
-		// Deserialize ipaParamLen
-		IpaParamsLenBuffer := make([]byte, 4)
-		copy(IpaParamsLenBuffer, paramsBytes[8:12])
-		IpaParamsLen := (uint32)(binary.LittleEndian.Uint32(IpaParamsLenBuffer))
-
-		// Extract Constraint System Bytes
-		csBytes := make([]byte, halo2ipa.MaxConstraintSystemSize)
-		csOffset := uint32(12)
-		copy(csBytes, paramsBytes[csOffset:(csOffset + csLen)])
-
-		// Extract Verification Key Bytes
-		vkBytes := make([]byte, halo2ipa.MaxVerifierKeySize)
-		vkOffset := csOffset + csLen
-		copy(vkBytes, paramsBytes[vkOffset:(vkOffset + vkLen)])
-
-		// Extract ipa Parameter Bytes
-		IpaParamsBytes := make([]byte,(halo2ipa.MaxIpaParamsSize))
-		IpaParamsOffset := vkOffset + vkLen
-		copy(IpaParamsBytes, paramsBytes[IpaParamsOffset:])
-
-		// Extract Public Input Bytes
-		publicInput := verificationData.PubInput
-		publicInputBytes := make([]byte, halo2ipa.MaxPublicInputSize)
-		copy(publicInputBytes, publicInput)
-		publicInputLen := (uint32)(len(publicInput))
-
-		verificationResult := halo2ipa.VerifyHalo2IpaProof(
-			([halo2ipa.MaxProofSize]byte)(proofBytes), proofLen, 
-			([halo2ipa.MaxConstraintSystemSize]byte)(csBytes), csLen,
-			([halo2ipa.MaxVerifierKeySize]byte)(vkBytes), vkLen, 
-			([halo2ipa.MaxIpaParamsSize]byte)(IpaParamsBytes), IpaParamsLen, 
-			([halo2ipa.MaxPublicInputSize]byte)(publicInputBytes), publicInputLen,)
-
-		o.Logger.Infof("Halo2-IPA proof verification result: %t", verificationResult)
-		results <- verificationResult
-	case common.Halo2KZG:
-		// Extract Proof Bytes
-		proofBytes := make([]byte, halo2kzg.MaxProofSize)
-		copy(proofBytes, verificationData.Proof)
-		proofLen := (uint32)(len(verificationData.Proof))
-
-		// Extract Verification Key Bytes
-		paramsBytes := verificationData.VerificationKey
-
-		// Deserialize csLen
-		csLenBuffer := make([]byte, 4)
-		copy(csLenBuffer, paramsBytes[:4])
-		csLen := (uint32)(binary.LittleEndian.Uint32(csLenBuffer))
-
-		// Deserialize vkLen
-		vkLenBuffer := make([]byte, 4)
-		copy(vkLenBuffer, paramsBytes[4:8])
-		vkLen := (uint32)(binary.LittleEndian.Uint32(vkLenBuffer))
-
-		// Deserialize kzgParamLen
-		kzgParamsLenBuffer := make([]byte, 4)
-		copy(kzgParamsLenBuffer, paramsBytes[8:12])
-		kzgParamsLen := (uint32)(binary.LittleEndian.Uint32(kzgParamsLenBuffer))
-
-		// Extract Constraint System Bytes
-		csBytes := make([]byte, halo2kzg.MaxConstraintSystemSize)
-		csOffset := uint32(12)
-		copy(csBytes, paramsBytes[csOffset:(csOffset + csLen)])
-
-		// Extract Verification Key Bytes
-		vkBytes := make([]byte, halo2kzg.MaxVerifierKeySize)
-		vkOffset := csOffset + csLen
-		copy(vkBytes, paramsBytes[vkOffset:(vkOffset + vkLen)])
-
-		// Extract Kzg Parameter Bytes
-		kzgParamsBytes := make([]byte,(halo2kzg.MaxKzgParamsSize))
-		kzgParamsOffset := vkOffset + vkLen
-		copy(kzgParamsBytes, paramsBytes[kzgParamsOffset:])
-
-		// Extract Public Input Bytes
-		publicInput := verificationData.PubInput
-		publicInputBytes := make([]byte, halo2kzg.MaxPublicInputSize)
-		copy(publicInputBytes, publicInput)
-		publicInputLen := (uint32)(len(publicInput))
-
-		verificationResult := halo2kzg.VerifyHalo2KzgProof(
-			([halo2kzg.MaxProofSize]byte)(proofBytes), proofLen, 
-			([halo2kzg.MaxConstraintSystemSize]byte)(csBytes), csLen,
-			([halo2kzg.MaxVerifierKeySize]byte)(vkBytes), vkLen, 
-			([halo2kzg.MaxKzgParamsSize]byte)(kzgParamsBytes), kzgParamsLen, 
-			([halo2kzg.MaxPublicInputSize]byte)(publicInputBytes), publicInputLen,)
-
-		o.Logger.Infof("Halo2-KZG proof verification result: %t", verificationResult)
-		results <- verificationResult
-	default:
-		o.Logger.Error("Unrecognized proving system ID")
-		results <- false
-	}
-}
+// fetchAndVerifyBatch streams the batch from the source into the verification pool.
+// It returns an error if the batch could not be fetched or does not match the
+// batch merkle root, in which case the verification failures are meaningless.
+func (o *Operator) fetchAndVerifyBatch(ctx context.Context, source *url.URL, batchMerkleRoot [32]byte) ([]*common.VerificationError, error) {
+	verificationCtx, cancelVerification := context.WithCancel(ctx)
+	defer cancelVerification()
 
-// VerifyPlonkProofBLS12_381 verifies a PLONK proof using BLS12-381 curve.
-func (o *Operator) verifyPlonkProofBLS12_381(proofBytes []byte, pubInputBytes []byte, verificationKeyBytes []byte) bool {
-	return o.verifyPlonkProof(proofBytes, pubInputBytes, verificationKeyBytes, ecc.BLS12_381)
-}
+	entries := make(chan BatchEntry)
+	failuresChan := make(chan []*common.VerificationError, 1)
+	go func() {
+		failuresChan <- o.verificationPool.VerifyStream(verificationCtx, entries, o.verify)
+	}()
 
-// VerifyPlonkProofBN254 verifies a PLONK proof using BN254 curve.
-func (o *Operator) verifyPlonkProofBN254(proofBytes []byte, pubInputBytes []byte, verificationKeyBytes []byte) bool {
-	return o.verifyPlonkProof(proofBytes, pubInputBytes, verificationKeyBytes, ecc.BN254)
-}
+	err := o.batchFetcher.StreamBatch(ctx, source, batchMerkleRoot, func(ctx context.Context, index int, verificationData VerificationData) error {
+		select {
+		case entries <- BatchEntry{Index: index, VerificationData: verificationData}:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	if err != nil {
+		cancelVerification()
+	}
+	close(entries)
+	failures := <-failuresChan
 
-// VerifyGroth16ProofBN254 verifies a GROTH16 proof using BN254 curve.
-func (o *Operator) verifyGroth16ProofBN254(proofBytes []byte, pubInputBytes []byte, verificationKeyBytes []byte) bool {
-	return o.verifyGroth16Proof(proofBytes, pubInputBytes, verificationKeyBytes, ecc.BN254)
+	if err != nil {
+		return nil, err
+	}
+	return failures, nil
 }
 
-// verifyPlonkProof contains the common proof verification logic.
-func (o *Operator) verifyPlonkProof(proofBytes []byte, pubInputBytes []byte, verificationKeyBytes []byte, curve ecc.ID) bool {
-	proofReader := bytes.NewReader(proofBytes)
-	proof := plonk.NewProof(curve)
-	if _, err := proof.ReadFrom(proofReader); err != nil {
-		o.Logger.Infof("Could not deserialize proof: %v", err)
-		return false
-	}
+// verify verifies a proof with the verifier registered for its proving system.
+// An error is returned if the proof is invalid or could not be verified.
+func (o *Operator) verify(ctx context.Context, verificationData VerificationData) (err error) {
+	defer o.metrics.IncOperatorTaskResponses()
 
-	pubInputReader := bytes.NewReader(pubInputBytes)
-	pubInput, err := witness.New(curve.ScalarField())
+	verifier, err := common.GetVerifier(verificationData.ProvingSystemId)
 	if err != nil {
-		o.Logger.Infof("Error instantiating witness: %v", err)
-		return false
-	}
-	if _, err = pubInput.ReadFrom(pubInputReader); err != nil {
-		o.Logger.Infof("Could not read PLONK public input: %v", err)
-		return false
+		return err
 	}
 
-	verificationKeyReader := bytes.NewReader(verificationKeyBytes)
-	verificationKey := plonk.NewVerifyingKey(curve)
-	if _, err = verificationKey.ReadFrom(verificationKeyReader); err != nil {
-		o.Logger.Infof("Could not read PLONK verifying key from bytes: %v", err)
-		return false
+	if err = common.CheckLimits(verifier, verificationData); err != nil {
+		return err
 	}
 
-	err = plonk.Verify(proof, verificationKey, pubInput)
-	return err == nil
-}
-
-// verifyGroth16Proof contains the common proof verification logic.
-func (o *Operator) verifyGroth16Proof(proofBytes []byte, pubInputBytes []byte, verificationKeyBytes []byte, curve ecc.ID) bool {
-	proofReader := bytes.NewReader(proofBytes)
-	proof := groth16.NewProof(curve)
-	if _, err := proof.ReadFrom(proofReader); err != nil {
-		o.Logger.Infof("Could not deserialize proof: %v", err)
-		return false
-	}
+	// Verifiers call into FFI libraries through Go wrappers, a panic there must
+	// only reject the proof and not bring the operator down
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %s: %v", common.ErrVerifierPanic, verifier.Name(), r)
+		}
+	}()
 
-	pubInputReader := bytes.NewReader(pubInputBytes)
-	pubInput, err := witness.New(curve.ScalarField())
+	verificationResult, err := verifier.Verify(ctx, verificationData)
 	if err != nil {
-		o.Logger.Infof("Error instantiating witness: %v", err)
-		return false
-	}
-	if _, err = pubInput.ReadFrom(pubInputReader); err != nil {
-		o.Logger.Infof("Could not read Groth16 public input: %v", err)
-		return false
+		return err
 	}
+	o.Logger.Infof("%s proof verification result: %t", verifier.Name(), verificationResult)
 
-	verificationKeyReader := bytes.NewReader(verificationKeyBytes)
-	verificationKey := groth16.NewVerifyingKey(curve)
-	if _, err = verificationKey.ReadFrom(verificationKeyReader); err != nil {
-		o.Logger.Infof("Could not read Groth16 verifying key from bytes: %v", err)
-		return false
+	if !verificationResult {
+		return fmt.Errorf("%w: %s", common.ErrInvalidProof, verifier.Name())
 	}
-
-	err = groth16.Verify(proof, verificationKey, pubInput)
-	return err == nil
+	return nil
 }
 
-func (o *Operator) SignTaskResponse(batchMerkleRoot [32]byte) *bls.Signature {
-	responseSignature := *o.Config.BlsConfig.KeyPair.SignMessage(batchMerkleRoot)
-	return &responseSignature
+// SignTaskResponse signs the batch merkle root with the BLS signer, which may be a remote signing service
+func (o *Operator) SignTaskResponse(ctx context.Context, batchMerkleRoot [32]byte) (*bls.Signature, error) {
+	return o.Config.BlsConfig.Signer.SignBatchMerkleRoot(ctx, batchMerkleRoot)
 }
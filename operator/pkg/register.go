@@ -2,15 +2,14 @@ package operator
 
 import (
 	"context"
-	"github.com/Layr-Labs/eigensdk-go/types"
 	"github.com/yetanotherco/aligned_layer/core/chainio"
 	"github.com/yetanotherco/aligned_layer/core/config"
 	"math/big"
 	"time"
 )
 
-// RegisterOperator operator registers the operator with the given public key for the given quorum IDs.
-// RegisterOperator registers a new operator with the given public key and socket with the provided quorum ids.
+// RegisterOperator registers a new operator with the given public key and socket in the quorums
+// the service manager checks.
 // If the operator is already registered with a given quorum id, the transaction will fail (noop) and an error
 // will be returned.
 func RegisterOperator(
@@ -27,11 +26,19 @@ func RegisterOperator(
 	operatorToAvsRegistrationSigExpiry := big.NewInt(time.Now().Add(10 * time.Minute).Unix())
 	socket := "Not Needed"
 
-	quorumNumbers := types.QuorumNums{0}
+	avsReader, err := chainio.NewAvsReaderFromConfig(configuration.BaseConfig)
+	if err != nil {
+		configuration.BaseConfig.Logger.Error("Failed to create AVS reader", "err", err)
+		return err
+	}
+	quorumNumbers, err := avsReader.GetQuorumNumbers(ctx)
+	if err != nil {
+		configuration.BaseConfig.Logger.Error("Failed to get quorum numbers", "err", err)
+		return err
+	}
 
-	_, err = writer.RegisterOperatorInQuorumWithAVSRegistryCoordinator(ctx, configuration.EcdsaConfig.PrivateKey,
-		operatorToAvsRegistrationSigSalt, operatorToAvsRegistrationSigExpiry, configuration.BlsConfig.KeyPair,
-		quorumNumbers, socket)
+	_, err = writer.RegisterOperator(ctx, operatorToAvsRegistrationSigSalt, operatorToAvsRegistrationSigExpiry,
+		configuration.BlsConfig.Signer, quorumNumbers, socket)
 
 	if err != nil {
 		configuration.BaseConfig.Logger.Error("Failed to register operator", "err", err)
@@ -1,19 +1,30 @@
 package operator
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
 	"net/rpc"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Layr-Labs/eigensdk-go/logging"
+	configpkg "github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/signer"
 	"github.com/yetanotherco/aligned_layer/core/types"
+	"github.com/yetanotherco/aligned_layer/core/utils"
 )
 
 // AggregatorRpcClient is the client to communicate with the aggregator via RPC
 type AggregatorRpcClient struct {
-	rpcClient            *rpc.Client
-	aggregatorIpPortAddr string
-	logger               logging.Logger
+	transport aggregatorTransport
+	logger    logging.Logger
 }
 
 const (
@@ -21,44 +32,313 @@ const (
 	RetryInterval = 10 * time.Second
 )
 
-func NewAggregatorRpcClient(aggregatorIpPortAddr string, logger logging.Logger) (*AggregatorRpcClient, error) {
+// The aggregator answers right away, asking to send the response again if it can't process it yet,
+// so each request is sent again if it takes longer, within the context of the caller
+const aggregatorRequestTimeout = 30 * time.Second
+
+// ErrRejectedByAggregator is returned when the aggregator answered the request
+// with an error, sending it again would get the same answer
+var ErrRejectedByAggregator = errors.New("rejected by aggregator")
+
+// aggregatorTransport sends the operator messages with one of the aggregator APIs
+type aggregatorTransport interface {
+	SendSignedTaskResponse(ctx context.Context, signedTaskResponse *types.SignedTaskResponse) (*types.SignedTaskResponseResult, error)
+	SendVerificationFailure(ctx context.Context, report *types.VerificationFailureReport) error
+}
+
+type AggregatorRpcClientConfig struct {
+	AggregatorIpPortAddr string
+	// One of the config.AggregatorRpcApi* values
+	Api string
+	// Connects with TLS when set, only supported by the JSON-RPC API
+	TlsConfig *tls.Config
+	// Signs the JSON-RPC requests, must hold the key of the operator registered address
+	Signer signer.Signer
+	// Deployment the signed requests are meant for
+	Domain types.RequestDomain
+}
+
+func NewAggregatorRpcClient(config AggregatorRpcClientConfig, logger logging.Logger) (*AggregatorRpcClient, error) {
+	var transport aggregatorTransport
+	switch config.Api {
+	case configpkg.AggregatorRpcApiJsonRpc:
+		transport = newJsonRpcTransport(config.AggregatorIpPortAddr, config.TlsConfig, config.Signer, config.Domain)
+	case configpkg.AggregatorRpcApiNetRpc:
+		if config.TlsConfig != nil {
+			return nil, errors.New("the net/rpc aggregator api does not support TLS")
+		}
+		netRpcTransport, err := newNetRpcTransport(config.AggregatorIpPortAddr)
+		if err != nil {
+			return nil, err
+		}
+		transport = netRpcTransport
+	default:
+		return nil, fmt.Errorf("unknown aggregator rpc api: %s", config.Api)
+	}
+
+	return &AggregatorRpcClient{
+		transport: transport,
+		logger:    logger,
+	}, nil
+}
+
+// SendSignedTaskResponseToAggregator is the method called by operators via RPC to send
+// their signed task response. It is sent again while the aggregator asks for it,
+// until ctx is done, and up to MaxRetries times if the aggregator can't be reached.
+func (c *AggregatorRpcClient) SendSignedTaskResponseToAggregator(ctx context.Context, signedTaskResponse *types.SignedTaskResponse) {
+	failedCalls := 0
+	for {
+		var retryAfter time.Duration
+		requestCtx, cancel := context.WithTimeout(ctx, aggregatorRequestTimeout)
+		result, err := c.transport.SendSignedTaskResponse(requestCtx, signedTaskResponse)
+		cancel()
+		switch {
+		case ctx.Err() != nil:
+			c.logger.Warn("Batch response window ended before the aggregator accepted the signed task response", "err", err)
+			return
+		case errors.Is(err, ErrRejectedByAggregator):
+			c.logger.Warn("Signed task response rejected by aggregator", "err", err)
+			return
+		case err != nil:
+			failedCalls++
+			if failedCalls >= MaxRetries {
+				c.logger.Error("Could not send signed task response to aggregator", "err", err)
+				return
+			}
+			c.logger.Infof("Received error from aggregator: %s. Retrying ProcessOperatorSignedTaskResponse RPC call...", err)
+			retryAfter = RetryInterval
+		case result.Delivered():
+			c.logger.Info("Signed task response header accepted by aggregator.", "status", result.Status)
+			return
+		case result.RetryAfterDuration() > 0:
+			retryAfter = result.RetryAfterDuration()
+			c.logger.Info("Aggregator could not process signed task response yet, sending it again",
+				"status", result.Status, "message", result.Message, "retryAfter", retryAfter)
+		default:
+			c.logger.Warn("Signed task response rejected by aggregator", "status", result.Status, "message", result.Message)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			c.logger.Warn("Batch response window ended before the aggregator accepted the signed task response")
+			return
+		case <-time.After(retryAfter):
+		}
+	}
+}
+
+// SendVerificationFailureToAggregator reports to the aggregator why the operator
+// refused to sign a batch. Reports are informational so they are not retried.
+func (c *AggregatorRpcClient) SendVerificationFailureToAggregator(report *types.VerificationFailureReport) {
+	ctx, cancel := context.WithTimeout(context.Background(), aggregatorRequestTimeout)
+	defer cancel()
+	err := c.transport.SendVerificationFailure(ctx, report)
+	if err != nil {
+		c.logger.Error("Could not report verification failure to aggregator", "err", err)
+	}
+}
+
+// jsonRpcTransport uses the aggregator JSON-RPC 2.0 API
+type jsonRpcTransport struct {
+	url        string
+	httpClient *http.Client
+	signer     signer.Signer
+	domain     types.RequestDomain
+	nextId     atomic.Uint64
+}
+
+func newJsonRpcTransport(aggregatorIpPortAddr string, tlsConfig *tls.Config, signer signer.Signer, domain types.RequestDomain) *jsonRpcTransport {
+	scheme := "http://"
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		scheme = "https://"
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &jsonRpcTransport{
+		url:        scheme + aggregatorIpPortAddr + types.AggregatorJsonRpcPath,
+		httpClient: &http.Client{Transport: transport},
+		signer:     signer,
+		domain:     domain,
+	}
+}
+
+func (t *jsonRpcTransport) SendSignedTaskResponse(ctx context.Context, signedTaskResponse *types.SignedTaskResponse) (*types.SignedTaskResponseResult, error) {
+	params, err := types.NewSignedTaskResponseParams(signedTaskResponse)
+	if err != nil {
+		return nil, err
+	}
+	params.Timestamp = uint64(time.Now().Unix())
+	if params.Signature, err = t.sign(ctx, params.SigningData(t.domain)); err != nil {
+		return nil, err
+	}
+	var result types.SignedTaskResponseResult
+	if err = t.call(ctx, types.MethodSubmitSignedTaskResponse, params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (t *jsonRpcTransport) SendVerificationFailure(ctx context.Context, report *types.VerificationFailureReport) error {
+	params := types.NewVerificationFailureParams(report)
+	params.Timestamp = uint64(time.Now().Unix())
+	var err error
+	if params.Signature, err = t.sign(ctx, params.SigningData(t.domain)); err != nil {
+		return err
+	}
+	var result types.VerificationFailureResult
+	return t.call(ctx, types.MethodReportVerificationFailure, params, &result)
+}
+
+// sign returns no signature if the transport has no signer
+func (t *jsonRpcTransport) sign(ctx context.Context, signingData []byte) ([]byte, error) {
+	if t.signer == nil {
+		return nil, nil
+	}
+	return t.signer.SignData(ctx, signingData)
+}
+
+func (t *jsonRpcTransport) call(ctx context.Context, method string, params any, result any) error {
+	encodedParams, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	request, err := json.Marshal(types.JsonRpcRequest{
+		JsonRpc: types.JsonRpcVersion,
+		Method:  method,
+		Params:  encodedParams,
+		Id:      json.RawMessage(strconv.FormatUint(t.nextId.Add(1), 10)),
+	})
+	if err != nil {
+		return err
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(request))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	httpResponse, err := t.httpClient.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected http status: %s", httpResponse.Status)
+	}
+
+	var response types.JsonRpcResponse
+	if err = json.NewDecoder(httpResponse.Body).Decode(&response); err != nil {
+		return err
+	}
+	if response.Error != nil {
+		// An aggregator shutting down for a restart or a redeploy answers the request once it is back
+		if response.Error.Code == types.AggregatorErrShuttingDown {
+			return response.Error
+		}
+		return fmt.Errorf("%w: %w", ErrRejectedByAggregator, response.Error)
+	}
+	return json.Unmarshal(response.Result, result)
+}
+
+// netRpcTransport uses the deprecated net/rpc API of the aggregator
+type netRpcTransport struct {
+	rpcClient            *rpc.Client
+	aggregatorIpPortAddr string
+	mutex                sync.Mutex
+}
+
+func newNetRpcTransport(aggregatorIpPortAddr string) (*netRpcTransport, error) {
 	client, err := rpc.DialHTTP("tcp", aggregatorIpPortAddr)
 	if err != nil {
 		return nil, err
 	}
 
-	return &AggregatorRpcClient{
+	return &netRpcTransport{
 		rpcClient:            client,
 		aggregatorIpPortAddr: aggregatorIpPortAddr,
-		logger:               logger,
 	}, nil
 }
 
-// SendSignedTaskResponseToAggregator is the method called by operators via RPC to send
-// their signed task response.
-func (c *AggregatorRpcClient) SendSignedTaskResponseToAggregator(signedTaskResponse *types.SignedTaskResponse) {
+// The net/rpc API only tells if the response was accepted. Errors that may be
+// solved by sending the response again are returned as RPC errors.
+// Returns:
+//   - 0: Success
+//   - 1: Error
+func (t *netRpcTransport) SendSignedTaskResponse(ctx context.Context, signedTaskResponse *types.SignedTaskResponse) (*types.SignedTaskResponseResult, error) {
 	var reply uint8
-	for retries := 0; retries < MaxRetries; retries++ {
-		err := c.rpcClient.Call("Aggregator.ProcessOperatorSignedTaskResponse", signedTaskResponse, &reply)
+	err := t.call(ctx, "Aggregator.ProcessOperatorSignedTaskResponse", signedTaskResponse, &reply)
+	if err != nil {
+		return nil, err
+	}
+	if reply != 0 {
+		return nil, fmt.Errorf("%w: reply %d", ErrRejectedByAggregator, reply)
+	}
+	return &types.SignedTaskResponseResult{Status: types.TaskResponseAccepted}, nil
+}
+
+func (t *netRpcTransport) SendVerificationFailure(ctx context.Context, report *types.VerificationFailureReport) error {
+	var reply uint8
+	return t.call(ctx, "Aggregator.ProcessOperatorVerificationFailure", report, &reply)
+}
+
+// call reconnects and calls again once if the aggregator closed the connection
+func (t *netRpcTransport) call(ctx context.Context, method string, args any, reply any) error {
+	t.mutex.Lock()
+	client := t.rpcClient
+	t.mutex.Unlock()
+
+	err := callWithContext(ctx, client, method, args, reply)
+	if !errors.Is(err, rpc.ErrShutdown) {
+		return err
+	}
+
+	client, err = rpc.DialHTTP("tcp", t.aggregatorIpPortAddr)
+	if err != nil {
+		return fmt.Errorf("could not reconnect to aggregator: %w", err)
+	}
+	t.mutex.Lock()
+	t.rpcClient = client
+	t.mutex.Unlock()
+
+	return callWithContext(ctx, client, method, args, reply)
+}
+
+// callWithContext stops waiting for the reply when ctx is done. net/rpc can't cancel
+// the call, its reply is discarded when it arrives
+func callWithContext(ctx context.Context, client *rpc.Client, method string, args any, reply any) error {
+	call := client.Go(method, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-call.Done:
+		return call.Error
+	}
+}
+
+// newAggregatorTlsConfig returns nil if TLS is not enabled for the aggregator connection
+func newAggregatorTlsConfig(configuration configpkg.OperatorConfig) (*tls.Config, error) {
+	if !configuration.Operator.AggregatorTls {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if configuration.Operator.AggregatorTlsCaFile != "" {
+		rootCAs, err := utils.LoadCertPool(configuration.Operator.AggregatorTlsCaFile)
 		if err != nil {
-			c.logger.Error("Received error from aggregator", "err", err)
-			if errors.Is(err, rpc.ErrShutdown) {
-				c.logger.Error("Aggregator is shutdown. Reconnecting...")
-				client, err := rpc.DialHTTP("tcp", c.aggregatorIpPortAddr)
-				if err != nil {
-					c.logger.Error("Could not reconnect to aggregator", "err", err)
-					time.Sleep(RetryInterval)
-				} else {
-					c.rpcClient = client
-					c.logger.Info("Reconnected to aggregator")
-				}
-			} else {
-				c.logger.Infof("Received error from aggregator: %s. Retrying ProcessOperatorSignedTaskResponse RPC call...", err)
-				time.Sleep(RetryInterval)
-			}
-		} else {
-			c.logger.Info("Signed task response header accepted by aggregator.", "reply", reply)
-			return
+			return nil, err
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if configuration.Operator.AggregatorTlsCertFile != "" {
+		certificate, err := tls.LoadX509KeyPair(configuration.Operator.AggregatorTlsCertFile, configuration.Operator.AggregatorTlsKeyFile)
+		if err != nil {
+			return nil, err
 		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
 	}
+
+	return tlsConfig, nil
 }
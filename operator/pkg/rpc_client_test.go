@@ -0,0 +1,58 @@
+package operator_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/types"
+	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+)
+
+func TestSendSignedTaskResponseStopsWithContext(t *testing.T) {
+	// The aggregator never answers
+	unblock := make(chan struct{})
+	received := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		select {
+		case <-r.Context().Done():
+		case <-unblock:
+		}
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	client, err := operator.NewAggregatorRpcClient(operator.AggregatorRpcClientConfig{
+		AggregatorIpPortAddr: strings.TrimPrefix(server.URL, "http://"),
+		Api:                  config.AggregatorRpcApiJsonRpc,
+	}, logging.NewNoopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		client.SendSignedTaskResponseToAggregator(ctx, &types.SignedTaskResponse{BlsSignature: *bls.NewZeroSignature()})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the response to stop being sent when its context is done")
+	}
+	select {
+	case <-received:
+	default:
+		t.Fatal("expected the response to be sent to the aggregator")
+	}
+}
@@ -0,0 +1,176 @@
+package operator
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+	"github.com/yetanotherco/aligned_layer/merkle"
+	"github.com/yetanotherco/aligned_layer/metrics"
+)
+
+const (
+	verificationCacheDbCacheMb     = 16
+	verificationCacheDbFileHandles = 16
+	// Expired proofs are removed from the on-disk store at most this often
+	verificationCachePruneInterval = time.Hour
+)
+
+// VerificationCache remembers the proofs that were already verified, so proofs
+// resubmitted in later batches are not verified again. Only valid proofs are
+// cached, as the result of a failed verification may depend on the operator
+// (e.g. a timeout). Proofs are forgotten ttl after they were verified.
+type VerificationCache struct {
+	// Time each proof was verified at
+	memory *lru.Cache[ethcommon.Hash, time.Time]
+	ttl    time.Duration
+	// Optional on-disk store that survives restarts, nil if not configured.
+	// Values are the unix time the proof was verified at, in seconds
+	db      ethdb.KeyValueStore
+	metrics *metrics.Metrics
+
+	// Protects lastPrune
+	pruneMutex sync.Mutex
+	lastPrune  time.Time
+}
+
+// NewVerificationCache creates a cache of the given size. If path is not empty
+// the verified proofs are also persisted in a LevelDB database in that directory,
+// whose expired proofs are removed when it is opened and every hour after that.
+func NewVerificationCache(size int, ttl time.Duration, path string, metrics *metrics.Metrics) (*VerificationCache, error) {
+	cache := &VerificationCache{
+		memory:  lru.NewCache[ethcommon.Hash, time.Time](size),
+		ttl:     ttl,
+		metrics: metrics,
+	}
+
+	if path != "" {
+		db, err := leveldb.New(path, verificationCacheDbCacheMb, verificationCacheDbFileHandles, "operator/verification_cache/", false)
+		if err != nil {
+			return nil, err
+		}
+		cache.db = db
+		if err = cache.prune(time.Now()); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+
+	return cache, nil
+}
+
+// VerificationCacheKey returns the hash of the proving system, proof, public
+// input, verification key and program code of the verification data
+func VerificationCacheKey(verificationData VerificationData) ethcommon.Hash {
+	provingSystemId := make([]byte, 2)
+	binary.BigEndian.PutUint16(provingSystemId, uint16(verificationData.ProvingSystemId))
+
+	proofCommitment := merkle.Commit(verificationData.Proof)
+	pubInputCommitment := merkle.Commit(verificationData.PubInput)
+	verificationKeyCommitment := merkle.Commit(verificationData.VerificationKey)
+	vmProgramCodeCommitment := merkle.Commit(verificationData.VmProgramCode)
+
+	return crypto.Keccak256Hash(
+		provingSystemId,
+		proofCommitment[:],
+		pubInputCommitment[:],
+		verificationKeyCommitment[:],
+		vmProgramCodeCommitment[:],
+	)
+}
+
+// IsVerified returns true if the proof was already verified successfully
+func (c *VerificationCache) IsVerified(verificationData VerificationData) bool {
+	key := VerificationCacheKey(verificationData)
+	now := time.Now()
+
+	verifiedAt, verified := c.memory.Get(key)
+	if !verified && c.db != nil {
+		value, err := c.db.Get(key[:])
+		if err == nil {
+			verifiedAt, verified = decodeVerifiedAt(value)
+		}
+	}
+	if verified && c.expired(verifiedAt, now) {
+		c.memory.Remove(key)
+		verified = false
+	} else if verified {
+		c.memory.Add(key, verifiedAt)
+	}
+
+	if verified {
+		c.metrics.IncOperatorVerificationCacheHits()
+	} else {
+		c.metrics.IncOperatorVerificationCacheMisses()
+	}
+	return verified
+}
+
+// AddVerified stores a successfully verified proof
+func (c *VerificationCache) AddVerified(verificationData VerificationData) error {
+	key := VerificationCacheKey(verificationData)
+	now := time.Now()
+	c.memory.Add(key, now)
+
+	if c.db == nil {
+		return nil
+	}
+	if err := c.db.Put(key[:], binary.BigEndian.AppendUint64(nil, uint64(now.Unix()))); err != nil {
+		return err
+	}
+	return c.prune(now)
+}
+
+// prune removes the expired proofs from the on-disk store, unless it was done less than
+// verificationCachePruneInterval ago
+func (c *VerificationCache) prune(now time.Time) error {
+	c.pruneMutex.Lock()
+	defer c.pruneMutex.Unlock()
+	if now.Sub(c.lastPrune) < verificationCachePruneInterval {
+		return nil
+	}
+	c.lastPrune = now
+
+	it := c.db.NewIterator(nil, nil)
+	defer it.Release()
+
+	batch := c.db.NewBatch()
+	for it.Next() {
+		verifiedAt, ok := decodeVerifiedAt(it.Value())
+		if ok && !c.expired(verifiedAt, now) {
+			continue
+		}
+		if err := batch.Delete(it.Key()); err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+func (c *VerificationCache) expired(verifiedAt time.Time, now time.Time) bool {
+	return now.Sub(verifiedAt) >= c.ttl
+}
+
+// decodeVerifiedAt returns false for values without a verification time,
+// stored before proofs expired
+func decodeVerifiedAt(value []byte) (time.Time, bool) {
+	if len(value) != 8 {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(binary.BigEndian.Uint64(value)), 0), true
+}
+
+func (c *VerificationCache) Close() error {
+	if c.db != nil {
+		return c.db.Close()
+	}
+	return nil
+}
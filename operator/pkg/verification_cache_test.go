@@ -0,0 +1,79 @@
+package operator_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yetanotherco/aligned_layer/common"
+	"github.com/yetanotherco/aligned_layer/metrics"
+	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+)
+
+func openTestVerificationCache(t *testing.T, ttl time.Duration, path string) *operator.VerificationCache {
+	cache, err := operator.NewVerificationCache(16, ttl, path, metrics.NewMetrics("", prometheus.NewRegistry(), logging.NewNoopLogger()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cache
+}
+
+func TestVerificationCachePersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "verification_cache")
+	verified := operator.VerificationData{ProvingSystemId: common.Groth16Bn254, Proof: []byte{1}}
+	unverified := operator.VerificationData{ProvingSystemId: common.Groth16Bn254, Proof: []byte{2}}
+
+	cache := openTestVerificationCache(t, time.Hour, path)
+	if cache.IsVerified(verified) {
+		t.Fatal("expected an empty cache")
+	}
+	if err := cache.AddVerified(verified); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cache = openTestVerificationCache(t, time.Hour, path)
+	defer cache.Close()
+	if !cache.IsVerified(verified) {
+		t.Error("expected the verified proof to be remembered after reopening the cache")
+	}
+	if cache.IsVerified(unverified) {
+		t.Error("expected a proof that was not verified to be a miss")
+	}
+}
+
+func TestVerificationCacheExpiresProofs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "verification_cache")
+	verified := operator.VerificationData{ProvingSystemId: common.Groth16Bn254, Proof: []byte{1}}
+
+	cache := openTestVerificationCache(t, time.Second, path)
+	if err := cache.AddVerified(verified); err != nil {
+		t.Fatal(err)
+	}
+	if !cache.IsVerified(verified) {
+		t.Fatal("expected the proof to be verified within its ttl")
+	}
+	// Verification times are stored in seconds
+	time.Sleep(2 * time.Second)
+	if cache.IsVerified(verified) {
+		t.Error("expected the proof to expire from memory")
+	}
+	if err := cache.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopening prunes it from the database, so it stays a miss with a longer ttl
+	cache = openTestVerificationCache(t, time.Second, path)
+	if err := cache.Close(); err != nil {
+		t.Fatal(err)
+	}
+	cache = openTestVerificationCache(t, time.Hour, path)
+	defer cache.Close()
+	if cache.IsVerified(verified) {
+		t.Error("expected the expired proof to be pruned from the database")
+	}
+}
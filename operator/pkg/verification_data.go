@@ -4,10 +4,6 @@ import (
 	"github.com/yetanotherco/aligned_layer/common"
 )
 
-type VerificationData struct {
-	ProvingSystemId common.ProvingSystemId `json:"proving_system"`
-	Proof           []byte                 `json:"proof"`
-	PubInput        []byte                 `json:"pub_input"`
-	VerificationKey []byte                 `json:"verification_key"`
-	VmProgramCode   []byte                 `json:"vm_program_code"`
-}
+// VerificationData is defined in common so verifiers can be implemented
+// in their own packages
+type VerificationData = common.VerificationData
@@ -0,0 +1,180 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/common"
+)
+
+type proofVerifier func(ctx context.Context, verificationData VerificationData) error
+
+// BatchEntry is an entry of a batch and its index in the batch
+type BatchEntry struct {
+	Index            int
+	VerificationData VerificationData
+}
+
+// VerificationPool bounds the resources used to verify the proofs of a batch.
+// At most `workers` proofs are verified at the same time, each proving system
+// can have a lower concurrency cap, and each proof has a verification timeout.
+// Once a proof fails the remaining proofs of the batch are not verified.
+type VerificationPool struct {
+	workers int
+	timeout time.Duration
+	// Semaphores of the proving systems with a concurrency cap. They are shared
+	// by all the batches verified with this pool.
+	provingSystemSlots map[common.ProvingSystemId]chan struct{}
+	// Proofs found in the cache are not verified again, nil if disabled
+	cache *VerificationCache
+}
+
+func NewVerificationPool(workers int, provingSystemLimits map[common.ProvingSystemId]int, timeout time.Duration, cache *VerificationCache) *VerificationPool {
+	provingSystemSlots := make(map[common.ProvingSystemId]chan struct{}, len(provingSystemLimits))
+	for provingSystemId, limit := range provingSystemLimits {
+		if limit > 0 {
+			provingSystemSlots[provingSystemId] = make(chan struct{}, limit)
+		}
+	}
+
+	return &VerificationPool{
+		workers:            workers,
+		timeout:            timeout,
+		provingSystemSlots: provingSystemSlots,
+		cache:              cache,
+	}
+}
+
+// NewVerificationPoolFromLimitsByName is like NewVerificationPool, with the
+// concurrency caps keyed by proving system name as in the operator config
+func NewVerificationPoolFromLimitsByName(workers int, limitsByName map[string]int, timeout time.Duration, cache *VerificationCache) (*VerificationPool, error) {
+	provingSystemLimits := make(map[common.ProvingSystemId]int, len(limitsByName))
+	for name, limit := range limitsByName {
+		provingSystemId, err := common.ProvingSystemIdFromString(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid verification concurrency limit: %w", err)
+		}
+		provingSystemLimits[provingSystemId] = limit
+	}
+	return NewVerificationPool(workers, provingSystemLimits, timeout, cache), nil
+}
+
+// VerifyBatch verifies every entry of the batch with the given verifier and
+// returns the failures sorted by batch index. Entries skipped because another
+// one failed first are not reported.
+func (p *VerificationPool) VerifyBatch(ctx context.Context, batch []VerificationData, verify proofVerifier) []*common.VerificationError {
+	entries := make(chan BatchEntry)
+	go func() {
+		defer close(entries)
+		for i, verificationData := range batch {
+			entries <- BatchEntry{Index: i, VerificationData: verificationData}
+		}
+	}()
+	return p.VerifyStream(ctx, entries, verify)
+}
+
+// VerifyStream verifies the entries received until the channel is closed, so
+// verification can start while the batch is still being downloaded. It returns
+// the failures sorted by batch index. After the first failure the remaining
+// entries are still consumed, so senders never block, but not verified.
+func (p *VerificationPool) VerifyStream(ctx context.Context, entries <-chan BatchEntry, verify proofVerifier) []*common.VerificationError {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var failuresMutex sync.Mutex
+	failures := make([]*common.VerificationError, 0)
+
+	var wg sync.WaitGroup
+	wg.Add(p.workers)
+	for i := 0; i < p.workers; i++ {
+		go func() {
+			defer wg.Done()
+			for entry := range entries {
+				running, err := p.verifyEntry(ctx, entry, verify)
+				// Proofs interrupted by the cancellation of the batch didn't fail on their own
+				if err != nil && !(ctx.Err() != nil && err == ctx.Err()) {
+					failuresMutex.Lock()
+					failures = append(failures, &common.VerificationError{
+						BatchIndex:      entry.Index,
+						ProvingSystemId: entry.VerificationData.ProvingSystemId,
+						Err:             err,
+					})
+					failuresMutex.Unlock()
+					cancel()
+				}
+				// FFI verifiers can't be interrupted, so the worker doesn't take another
+				// entry until the verification that timed out actually returns
+				if running != nil {
+					<-running
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].BatchIndex < failures[j].BatchIndex
+	})
+	return failures
+}
+
+// verifyEntry verifies the entry within the pool timeout. If it returns before the
+// verification does, the verification is still running and the returned channel
+// is closed once it returns.
+func (p *VerificationPool) verifyEntry(ctx context.Context, entry BatchEntry, verify proofVerifier) (<-chan struct{}, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	// Cached proofs don't wait for a proving system slot
+	if p.cache != nil && p.cache.IsVerified(entry.VerificationData) {
+		return nil, nil
+	}
+
+	slots, capped := p.provingSystemSlots[entry.VerificationData.ProvingSystemId]
+	if capped {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if ctx.Err() != nil {
+		if capped {
+			<-slots
+		}
+		return nil, ctx.Err()
+	}
+
+	proofCtx, cancel := context.WithTimeout(ctx, p.timeout)
+
+	// The verification runs in its own goroutine so a timeout is reported right
+	// away, and keeps its proving system slot until it actually returns
+	done := make(chan error, 1)
+	running := make(chan struct{})
+	go func() {
+		defer close(running)
+		defer cancel()
+		if capped {
+			defer func() { <-slots }()
+		}
+		done <- verify(proofCtx, entry.VerificationData)
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil && p.cache != nil {
+			// Failing to cache a valid proof only means it will be verified again
+			_ = p.cache.AddVerified(entry.VerificationData)
+		}
+		return nil, err
+	case <-proofCtx.Done():
+		if ctx.Err() != nil {
+			return running, ctx.Err()
+		}
+		return running, fmt.Errorf("%w after %s", common.ErrTimeout, p.timeout)
+	}
+}
@@ -0,0 +1,129 @@
+package operator_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/common"
+	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+)
+
+// concurrencyCounter keeps the maximum number of verifications running at the same time
+type concurrencyCounter struct {
+	running atomic.Int32
+	max     atomic.Int32
+}
+
+func (c *concurrencyCounter) start() {
+	running := c.running.Add(1)
+	for {
+		max := c.max.Load()
+		if running <= max || c.max.CompareAndSwap(max, running) {
+			return
+		}
+	}
+}
+
+func (c *concurrencyCounter) end() {
+	c.running.Add(-1)
+}
+
+func testBatch(size int, provingSystemId common.ProvingSystemId) []operator.VerificationData {
+	batch := make([]operator.VerificationData, size)
+	for i := range batch {
+		batch[i] = operator.VerificationData{ProvingSystemId: provingSystemId, Proof: []byte{byte(i)}}
+	}
+	return batch
+}
+
+func TestVerificationPoolBoundsConcurrency(t *testing.T) {
+	pool := operator.NewVerificationPool(3, map[common.ProvingSystemId]int{common.SP1: 1}, time.Second, nil)
+
+	var all, sp1 concurrencyCounter
+	batch := append(testBatch(12, common.Groth16Bn254), testBatch(6, common.SP1)...)
+	failures := pool.VerifyBatch(context.Background(), batch, func(ctx context.Context, verificationData operator.VerificationData) error {
+		all.start()
+		defer all.end()
+		if verificationData.ProvingSystemId == common.SP1 {
+			sp1.start()
+			defer sp1.end()
+		}
+		time.Sleep(5 * time.Millisecond)
+		return nil
+	})
+
+	if len(failures) != 0 {
+		t.Fatalf("unexpected failures %v", failures)
+	}
+	if all.max.Load() > 3 {
+		t.Errorf("expected at most 3 proofs verified at the same time, got %d", all.max.Load())
+	}
+	if sp1.max.Load() > 1 {
+		t.Errorf("expected at most 1 sp1 proof verified at the same time, got %d", sp1.max.Load())
+	}
+}
+
+func TestVerificationPoolHoldsWorkerUntilTimedOutVerificationReturns(t *testing.T) {
+	pool := operator.NewVerificationPool(1, nil, 10*time.Millisecond, nil)
+
+	release := make(chan struct{})
+	var returned atomic.Bool
+	result := make(chan []*common.VerificationError)
+	go func() {
+		result <- pool.VerifyBatch(context.Background(), testBatch(2, common.Groth16Bn254), func(ctx context.Context, verificationData operator.VerificationData) error {
+			if verificationData.Proof[0] == 0 {
+				// Like an FFI verifier, it ignores the cancellation of ctx
+				<-release
+				returned.Store(true)
+				return nil
+			}
+			if !returned.Load() {
+				t.Error("second proof verified while the first one is still running")
+			}
+			return nil
+		})
+	}()
+
+	select {
+	case <-result:
+		t.Fatal("batch finished while a verification is still running")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+
+	failures := <-result
+	if len(failures) != 1 || failures[0].BatchIndex != 0 || !errors.Is(failures[0].Err, common.ErrTimeout) {
+		t.Fatalf("expected the first proof to time out, got %v", failures)
+	}
+}
+
+func TestVerificationPoolVerifiesInBatchOrder(t *testing.T) {
+	pool := operator.NewVerificationPool(1, nil, time.Second, nil)
+
+	invalidErr := errors.New("invalid proof")
+	var verified []int
+	failures := pool.VerifyBatch(context.Background(), testBatch(6, common.Groth16Bn254), func(ctx context.Context, verificationData operator.VerificationData) error {
+		index := int(verificationData.Proof[0])
+		verified = append(verified, index)
+		if index == 3 {
+			return invalidErr
+		}
+		return nil
+	})
+
+	// The entries after the failure are not verified
+	if len(verified) != 4 {
+		t.Fatalf("expected entries 0 to 3 to be verified, got %v", verified)
+	}
+	for i, index := range verified {
+		if index != i {
+			t.Fatalf("entries verified out of order: %v", verified)
+		}
+	}
+	if len(failures) != 1 || failures[0].BatchIndex != 3 || !errors.Is(failures[0].Err, invalidErr) {
+		t.Fatalf("expected entry 3 to fail, got %v", failures)
+	}
+}
@@ -0,0 +1,11 @@
+package operator
+
+// Proving systems supported by the operator. Each package registers its
+// verifiers in the common verifier registry when imported.
+import (
+	_ "github.com/yetanotherco/aligned_layer/operator/gnark"
+	_ "github.com/yetanotherco/aligned_layer/operator/halo2ipa"
+	_ "github.com/yetanotherco/aligned_layer/operator/halo2kzg"
+	_ "github.com/yetanotherco/aligned_layer/operator/risc_zero"
+	_ "github.com/yetanotherco/aligned_layer/operator/sp1"
+)
@@ -0,0 +1,57 @@
+package risc_zero
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ImageIdSize is the size in bytes of a serialized image ID
+const ImageIdSize = 32
+
+// ImageIdFromBytes deserializes an image ID, encoded as 8 little endian u32 words
+func ImageIdFromBytes(imageIdBytes []byte) ([8]uint32, error) {
+	var imageId [8]uint32
+	if len(imageIdBytes) != ImageIdSize {
+		return imageId, fmt.Errorf("image id must be %d bytes long, got %d", ImageIdSize, len(imageIdBytes))
+	}
+
+	for i := range imageId {
+		imageId[i] = binary.LittleEndian.Uint32(imageIdBytes[4*i:])
+	}
+	return imageId, nil
+}
+
+// ImageIdToBytes serializes an image ID as 8 little endian u32 words
+func ImageIdToBytes(imageId [8]uint32) []byte {
+	imageIdBytes := make([]byte, ImageIdSize)
+	for i, word := range imageId {
+		binary.LittleEndian.PutUint32(imageIdBytes[4*i:], word)
+	}
+	return imageIdBytes
+}
+
+// ParseImageId parses an image ID in the format printed by the RISC Zero host,
+// e.g. "[3033834634, 3920660713, ...]"
+func ParseImageId(content string) ([8]uint32, error) {
+	var imageId [8]uint32
+
+	content = strings.TrimSpace(content)
+	content = strings.TrimPrefix(content, "[")
+	content = strings.TrimSuffix(content, "]")
+
+	words := strings.Split(content, ",")
+	if len(words) != len(imageId) {
+		return imageId, fmt.Errorf("image id must have %d words, got %d", len(imageId), len(words))
+	}
+
+	for i, word := range words {
+		value, err := strconv.ParseUint(strings.TrimSpace(word), 10, 32)
+		if err != nil {
+			return imageId, fmt.Errorf("could not parse image id: %w", err)
+		}
+		imageId[i] = uint32(value)
+	}
+	return imageId, nil
+}
@@ -53,3 +53,34 @@ func getImageIdsFromFile(t *testing.T, filename string) []uint32 {
 
 	return imageId
 }
+
+func TestFibonacciImageIdRoundTrip(t *testing.T) {
+	data, err := os.ReadFile("../../task_sender/test_examples/risc_zero/fibonacci_proof_generator/fibonacci_id.txt")
+	if err != nil {
+		t.Fatalf("could not open image id file: %s", err)
+	}
+	imageId, err := risc_zero.ParseImageId(string(data))
+	if err != nil {
+		t.Fatalf("could not parse image id: %s", err)
+	}
+	if expected := getImageIdsFromFile(t, "../../task_sender/test_examples/risc_zero/fibonacci_proof_generator/fibonacci_id.txt"); imageId != ([8]uint32)(expected) {
+		t.Fatalf("parsed image id %v does not match %v", imageId, expected)
+	}
+
+	imageIdBytes := risc_zero.ImageIdToBytes(imageId)
+	if len(imageIdBytes) != risc_zero.ImageIdSize {
+		t.Fatalf("unexpected image id size %d", len(imageIdBytes))
+	}
+
+	decodedImageId, err := risc_zero.ImageIdFromBytes(imageIdBytes)
+	if err != nil {
+		t.Fatalf("could not decode image id: %s", err)
+	}
+	if decodedImageId != imageId {
+		t.Errorf("decoded image id %v does not match %v", decodedImageId, imageId)
+	}
+
+	if _, err := risc_zero.ImageIdFromBytes(imageIdBytes[1:]); err == nil {
+		t.Errorf("expected error decoding a short image id")
+	}
+}
@@ -0,0 +1,40 @@
+package risc_zero
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yetanotherco/aligned_layer/common"
+)
+
+func init() {
+	common.RegisterVerifier(&verifier{})
+}
+
+type verifier struct{}
+
+func (v *verifier) ProvingSystemId() common.ProvingSystemId { return common.RiscZero }
+func (v *verifier) Name() string                            { return "RiscZero" }
+func (v *verifier) Alias() string                           { return "risc_zero" }
+
+func (v *verifier) Limits() common.VerificationDataLimits {
+	return common.VerificationDataLimits{
+		MaxProofSize:         MaxReceiptSize,
+		MaxVmProgramCodeSize: ImageIdSize,
+	}
+}
+
+// Verify verifies a RISC Zero receipt. The image ID of the guest program is
+// carried in the VM program code field of the verification data.
+func (v *verifier) Verify(_ context.Context, verificationData common.VerificationData) (bool, error) {
+	imageId, err := ImageIdFromBytes(verificationData.VmProgramCode)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", common.ErrDeserialization, err)
+	}
+
+	receiptBuffer := make([]byte, MaxReceiptSize)
+	copy(receiptBuffer, verificationData.Proof)
+	receiptLen := (uint32)(len(verificationData.Proof))
+
+	return VerifyRiscZeroReceipt(([MaxReceiptSize]byte)(receiptBuffer), receiptLen, imageId), nil
+}
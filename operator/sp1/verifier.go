@@ -0,0 +1,43 @@
+package sp1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yetanotherco/aligned_layer/common"
+)
+
+const (
+	MaxProofSize = 2 * 1024 * 1024
+	MaxElfSize   = 2 * 1024 * 1024
+)
+
+func init() {
+	common.RegisterVerifier(&verifier{})
+}
+
+type verifier struct{}
+
+func (v *verifier) ProvingSystemId() common.ProvingSystemId { return common.SP1 }
+func (v *verifier) Name() string                            { return "SP1" }
+func (v *verifier) Alias() string                           { return "sp1" }
+
+func (v *verifier) Limits() common.VerificationDataLimits {
+	return common.VerificationDataLimits{
+		MaxProofSize:         MaxProofSize,
+		MaxVmProgramCodeSize: MaxElfSize,
+	}
+}
+
+func (v *verifier) Verify(_ context.Context, verificationData common.VerificationData) (bool, error) {
+	if len(verificationData.Proof) == 0 {
+		return false, fmt.Errorf("%w: empty SP1 proof", common.ErrDeserialization)
+	}
+	if len(verificationData.VmProgramCode) == 0 {
+		return false, fmt.Errorf("%w: empty SP1 ELF", common.ErrDeserialization)
+	}
+
+	proofLen := (uint32)(len(verificationData.Proof))
+	elfLen := (uint32)(len(verificationData.VmProgramCode))
+	return VerifySp1Proof(verificationData.Proof, proofLen, verificationData.VmProgramCode, elfLen), nil
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	ecdsa2 "github.com/Layr-Labs/eigensdk-go/crypto/ecdsa"
+	sdklogging "github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/urfave/cli/v2"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/signer"
+	"github.com/yetanotherco/aligned_layer/core/utils"
+)
+
+var (
+	// Version is the version of the binary.
+	Version   string
+	GitCommit string
+	GitDate   string
+)
+
+var (
+	privateKeyStorePathFlag = &cli.PathFlag{
+		Name:  "private-key-store-path",
+		Usage: "path to the ECDSA `KEYSTORE FILE`",
+	}
+	privateKeyStorePasswordFlag = &cli.StringFlag{
+		Name:    "private-key-store-password",
+		Usage:   "`PASSWORD` of the keystore",
+		EnvVars: []string{"SIGNER_PRIVATE_KEY_STORE_PASSWORD"},
+	}
+	chainIdFlag = &cli.Int64Flag{
+		Name:  "chain-id",
+		Usage: "`CHAIN ID` the transactions are signed for, required with an ECDSA key",
+	}
+	allowedToAddressesFlag = &cli.StringSliceFlag{
+		Name:  "allowed-to-addresses",
+		Usage: "`ADDRESSES` the ECDSA key signs transactions to, any address if not set",
+	}
+	maxValueFlag = &cli.StringFlag{
+		Name:  "max-value",
+		Value: "0",
+		Usage: "max `WEI` transferred by the transactions signed with the ECDSA key",
+	}
+	allowedEip712DomainsFlag = &cli.StringSliceFlag{
+		Name:  "allowed-eip712-domains",
+		Usage: "`DOMAIN SEPARATORS` of the EIP-712 messages signed with the ECDSA key, e.g. the one of the AVS directory to register the operator",
+	}
+	blsPrivateKeyStorePathFlag = &cli.PathFlag{
+		Name:  "bls-private-key-store-path",
+		Usage: "path to the BLS `KEYSTORE FILE`",
+	}
+	blsPrivateKeyStorePasswordFlag = &cli.StringFlag{
+		Name:    "bls-private-key-store-password",
+		Usage:   "`PASSWORD` of the BLS keystore",
+		EnvVars: []string{"SIGNER_BLS_PRIVATE_KEY_STORE_PASSWORD"},
+	}
+	blsAllowedMessageTypesFlag = &cli.StringSliceFlag{
+		Name:  "bls-allowed-message-types",
+		Value: cli.NewStringSlice(signer.BlsMessageBatchMerkleRoot),
+		Usage: "`TYPES` of the messages signed with the BLS key. " + signer.BlsMessagePubkeyRegistration + " is only needed to register the operator",
+	}
+	ethRpcUrlFlag = &cli.StringFlag{
+		Name:  "eth-rpc-url",
+		Usage: "`URL` of the Ethereum RPC the batches are checked in before signing their merkle root with the BLS key",
+	}
+	serviceManagerAddressFlag = &cli.StringFlag{
+		Name:  "aligned-layer-service-manager-address",
+		Usage: "`ADDRESS` of the Aligned Layer service manager the batches are checked in",
+	}
+	blsAuditLogPathFlag = &cli.PathFlag{
+		Name:  "bls-audit-log-path",
+		Value: "./bls_signer_audit.log",
+		Usage: "`PATH` of the file the BLS sign requests are appended to",
+	}
+	socketPathFlag = &cli.PathFlag{
+		Name:  "socket-path",
+		Value: "./signer.sock",
+		Usage: "`PATH` of the unix socket the services connect to",
+	}
+	environmentFlag = &cli.StringFlag{
+		Name:  "environment",
+		Value: string(sdklogging.Production),
+		Usage: "'production' only prints info and above. 'development' also prints debug",
+	}
+)
+
+var flags = []cli.Flag{
+	privateKeyStorePathFlag,
+	privateKeyStorePasswordFlag,
+	chainIdFlag,
+	allowedToAddressesFlag,
+	maxValueFlag,
+	allowedEip712DomainsFlag,
+	blsPrivateKeyStorePathFlag,
+	blsPrivateKeyStorePasswordFlag,
+	blsAllowedMessageTypesFlag,
+	ethRpcUrlFlag,
+	serviceManagerAddressFlag,
+	blsAuditLogPathFlag,
+	socketPathFlag,
+	environmentFlag,
+}
+
+func main() {
+	app := cli.NewApp()
+
+	app.Flags = flags
+	app.Version = fmt.Sprintf("%s-%s-%s", Version, GitCommit, GitDate)
+	app.Name = "aligned-layer-signer-daemon"
+	app.Usage = "Aligned Layer Signer Daemon"
+	app.Description = "Keeps the ECDSA and BLS keys out of the services, signing for them over a unix socket. ECDSA signing uses a Web3Signer compatible API."
+	app.Action = signerDaemonMain
+
+	err := app.Run(os.Args)
+	if err != nil {
+		log.Fatalln("Application failed.", "Message:", err)
+	}
+}
+
+func signerDaemonMain(ctx *cli.Context) error {
+	logger, err := config.NewLogger(sdklogging.LogLevel(ctx.String(environmentFlag.Name)))
+	if err != nil {
+		return err
+	}
+
+	if !ctx.IsSet(privateKeyStorePathFlag.Name) && !ctx.IsSet(blsPrivateKeyStorePathFlag.Name) {
+		return errors.New("at least one of the ECDSA and BLS keystores is required")
+	}
+
+	mux := http.NewServeMux()
+	if ctx.IsSet(privateKeyStorePathFlag.Name) {
+		if !ctx.IsSet(chainIdFlag.Name) {
+			return errors.New("the chain id is required to sign with an ECDSA key")
+		}
+		privateKey, err := ecdsa2.ReadKey(ctx.Path(privateKeyStorePathFlag.Name), ctx.String(privateKeyStorePasswordFlag.Name))
+		if err != nil {
+			return fmt.Errorf("error reading ecdsa private key from file: %w", err)
+		}
+		policy, err := daemonPolicy(ctx)
+		if err != nil {
+			return err
+		}
+		chainId := big.NewInt(ctx.Int64(chainIdFlag.Name))
+		localSigner := signer.NewLocalSigner(privateKey, chainId)
+		signer.NewDaemon(localSigner, chainId, policy, logger).RegisterHandlers(mux)
+		logger.Info("Signing with ECDSA key", "address", localSigner.Address().Hex(),
+			"allowedToAddresses", ctx.StringSlice(allowedToAddressesFlag.Name), "maxValue", policy.MaxValue)
+	}
+	if ctx.IsSet(blsPrivateKeyStorePathFlag.Name) {
+		blsKeyPair, err := bls.ReadPrivateKeyFromFile(ctx.Path(blsPrivateKeyStorePathFlag.Name), ctx.String(blsPrivateKeyStorePasswordFlag.Name))
+		if err != nil {
+			return fmt.Errorf("error reading bls private key from file: %w", err)
+		}
+		allowedMessageTypes := ctx.StringSlice(blsAllowedMessageTypesFlag.Name)
+		var batchChecker signer.BlsBatchChecker
+		if slices.Contains(allowedMessageTypes, signer.BlsMessageBatchMerkleRoot) {
+			if batchChecker, err = newServiceManagerBatchChecker(ctx); err != nil {
+				return err
+			}
+		}
+		auditLog, err := os.OpenFile(ctx.Path(blsAuditLogPathFlag.Name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("error opening bls audit log: %w", err)
+		}
+		defer auditLog.Close()
+		blsDaemon, err := signer.NewBlsDaemon(signer.NewLocalBlsSigner(blsKeyPair), allowedMessageTypes, batchChecker, auditLog, logger)
+		if err != nil {
+			return err
+		}
+		blsDaemon.RegisterHandlers(mux)
+		logger.Info("Signing with BLS key", "allowedMessageTypes", allowedMessageTypes)
+	}
+
+	// A socket left by a previous run would make listening fail
+	socketPath := ctx.Path(socketPathFlag.Name)
+	if err = os.Remove(socketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	// Only the user running the daemon can ask it to sign. The socket is created without
+	// access for others, instead of changing its mode after it was already listening
+	umask := syscall.Umask(0077)
+	listener, err := net.Listen("unix", socketPath)
+	syscall.Umask(umask)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	shutdownCtx, stop := utils.NewShutdownContext()
+	defer stop()
+	go func() {
+		<-shutdownCtx.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}()
+
+	logger.Info("Signer daemon started", "socketPath", socketPath)
+	err = server.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// daemonPolicy reads the restrictions of the transactions and messages signed with the ECDSA key
+func daemonPolicy(ctx *cli.Context) (signer.DaemonPolicy, error) {
+	var policy signer.DaemonPolicy
+	for _, address := range ctx.StringSlice(allowedToAddressesFlag.Name) {
+		if !common.IsHexAddress(address) {
+			return policy, fmt.Errorf("invalid allowed to address: %s", address)
+		}
+		policy.AllowedToAddresses = append(policy.AllowedToAddresses, common.HexToAddress(address))
+	}
+	for _, domain := range ctx.StringSlice(allowedEip712DomainsFlag.Name) {
+		domainBytes, err := hexutil.Decode(domain)
+		if err != nil || len(domainBytes) != common.HashLength {
+			return policy, fmt.Errorf("invalid allowed eip-712 domain: %s", domain)
+		}
+		policy.AllowedEip712Domains = append(policy.AllowedEip712Domains, common.BytesToHash(domainBytes))
+	}
+	maxValue, ok := new(big.Int).SetString(ctx.String(maxValueFlag.Name), 10)
+	if !ok || maxValue.Sign() < 0 {
+		return policy, fmt.Errorf("invalid max value: %s", ctx.String(maxValueFlag.Name))
+	}
+	policy.MaxValue = maxValue
+	return policy, nil
+}
+
+// serviceManagerBatchChecker reads the batches from the batchesState of the service manager
+type serviceManagerBatchChecker struct {
+	serviceManager *servicemanager.ContractAlignedLayerServiceManagerCaller
+}
+
+func newServiceManagerBatchChecker(ctx *cli.Context) (*serviceManagerBatchChecker, error) {
+	if !ctx.IsSet(ethRpcUrlFlag.Name) || !common.IsHexAddress(ctx.String(serviceManagerAddressFlag.Name)) {
+		return nil, errors.New("the eth rpc url and the service manager address are required to sign batch merkle roots")
+	}
+	client, err := eth.NewClient(ctx.String(ethRpcUrlFlag.Name))
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to the eth rpc: %w", err)
+	}
+	serviceManager, err := servicemanager.NewContractAlignedLayerServiceManagerCaller(common.HexToAddress(ctx.String(serviceManagerAddressFlag.Name)), client)
+	if err != nil {
+		return nil, err
+	}
+	return &serviceManagerBatchChecker{serviceManager: serviceManager}, nil
+}
+
+func (c *serviceManagerBatchChecker) IsPendingBatch(ctx context.Context, batchMerkleRoot [32]byte) (bool, error) {
+	batchState, err := c.serviceManager.BatchesState(&bind.CallOpts{Context: ctx}, batchMerkleRoot)
+	if err != nil {
+		return false, err
+	}
+	return batchState.TaskCreatedBlock != 0 && !batchState.Responded, nil
+}
@@ -2,9 +2,7 @@ package main
 
 import (
 	"bytes"
-	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -17,6 +15,7 @@ import (
 	"github.com/yetanotherco/aligned_layer/core/chainio"
 	"github.com/yetanotherco/aligned_layer/core/config"
 	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+	"github.com/yetanotherco/aligned_layer/operator/risc_zero"
 	"github.com/yetanotherco/aligned_layer/task_sender/pkg"
 	generateproof "github.com/yetanotherco/aligned_layer/task_sender/test_examples/gnark_groth16_bn254_infinite_script/pkg"
 
@@ -33,7 +32,7 @@ var (
 		Name:     "proving-system",
 		Aliases:  []string{"s"},
 		Required: true,
-		Usage:    "the `PROVING SYSTEM` to use (e.g., plonk, groth16)",
+		Usage:    "the `PROVING SYSTEM` to use (e.g., plonk_bn254, groth16_bn254, sp1, risc_zero)",
 	}
 	proofFlag = &cli.PathFlag{
 		Name:     "proof",
@@ -44,14 +43,14 @@ var (
 	publicInputFlag = &cli.PathFlag{
 		Name:     "public-input",
 		Aliases:  []string{"i"},
-		Required: true,
-		Usage:    "path to the `PUBLIC INPUT FILE`",
+		Required: false,
+		Usage:    "path to the `PUBLIC INPUT FILE`, required except for risc_zero, whose journal is part of the receipt",
 	}
 	verificationKeyFlag = &cli.PathFlag{
 		Name:     "verification-key",
 		Aliases:  []string{"v"},
 		Required: false,
-		Usage:    "path to the `VERIFICATION KEY FILE`",
+		Usage:    "path to the `VERIFICATION KEY FILE` (the image id file for risc_zero)",
 	}
 	intervalFlag = &cli.IntFlag{
 		Name:    "interval",
@@ -66,11 +65,10 @@ var (
 		Usage:    "the `FEE` in wei to send when sending a task",
 	}
 
-	quorumThresholdFlag = &cli.UintFlag{
-		Name:    "quorum-threshold",
-		Aliases: []string{"q"},
-		Value:   100,
-		Usage:   "the `QUORUM THRESHOLD PERCENTAGE` for tasks",
+	batchFormatFlag = &cli.StringFlag{
+		Name:  "batch-format",
+		Value: "json",
+		Usage: "the `BATCH FORMAT` used to upload the batch (json or binary)",
 	}
 )
 
@@ -81,7 +79,7 @@ var sendTaskFlags = []cli.Flag{
 	verificationKeyFlag,
 	config.ConfigFileFlag,
 	feeFlag,
-	quorumThresholdFlag,
+	batchFormatFlag,
 }
 
 var loopTasksFlags = []cli.Flag{
@@ -92,7 +90,7 @@ var loopTasksFlags = []cli.Flag{
 	config.ConfigFileFlag,
 	intervalFlag,
 	feeFlag,
-	quorumThresholdFlag,
+	batchFormatFlag,
 }
 
 var infiniteTasksFlags = []cli.Flag{
@@ -100,7 +98,7 @@ var infiniteTasksFlags = []cli.Flag{
 	config.ConfigFileFlag,
 	intervalFlag,
 	feeFlag,
-	quorumThresholdFlag,
+	batchFormatFlag,
 }
 
 func main() {
@@ -175,6 +173,11 @@ func getAndUploadProofData(c *cli.Context, x int) ([32]byte, string, error) {
 		return [32]byte{}, "", err
 	}
 
+	batchFormat, err := common.ParseBatchFormat(c.String(batchFormatFlag.Name))
+	if err != nil {
+		return [32]byte{}, "", err
+	}
+
 	if x == 0 { //previous version, not generated by infinite-generator read proof from flag parameters
 		proofFile = c.String(proofFlag.Name)
 		pubInputFile = c.String(publicInputFlag.Name)
@@ -185,13 +188,19 @@ func getAndUploadProofData(c *cli.Context, x int) ([32]byte, string, error) {
 		pubInputFile = outputDir + "ineq_" + strconv.Itoa(x) + "_groth16.pub"
 		verificationKeyFile = outputDir + "ineq_" + strconv.Itoa(x) + "_groth16.vk"
 	}
+	if pubInputFile == "" && provingSystem != common.RiscZero {
+		return [32]byte{}, "", fmt.Errorf("the public input is required for %s proofs", c.String(provingSystemFlag.Name))
+	}
 	ProofByteArray, err := os.ReadFile(proofFile)
 	if err != nil {
 		return [32]byte{}, "", err
 	}
-	PubInputByteArray, err := os.ReadFile(pubInputFile)
-	if err != nil {
-		return [32]byte{}, "", err
+	var PubInputByteArray []byte
+	if pubInputFile != "" {
+		PubInputByteArray, err = os.ReadFile(pubInputFile)
+		if err != nil {
+			return [32]byte{}, "", err
+		}
 	}
 	VerificationKeyByteArray, err := os.ReadFile(verificationKeyFile)
 	if err != nil {
@@ -204,24 +213,37 @@ func getAndUploadProofData(c *cli.Context, x int) ([32]byte, string, error) {
 			ProvingSystemId: provingSystem,
 			Proof:           ProofByteArray,
 			PubInput:        PubInputByteArray,
-			VerificationKey: []byte(""),
 			VmProgramCode:   VerificationKeyByteArray,
 		}}
+	} else if provingSystem == common.RiscZero { // the image id is sent as the VM program code
+		imageId, err := risc_zero.ParseImageId(string(VerificationKeyByteArray))
+		if err != nil {
+			return [32]byte{}, "", err
+		}
+		data = []operator.VerificationData{{
+			ProvingSystemId: provingSystem,
+			Proof:           ProofByteArray,
+			PubInput:        PubInputByteArray,
+			VmProgramCode:   risc_zero.ImageIdToBytes(imageId),
+		}}
 	} else {
 		data = []operator.VerificationData{{
 			ProvingSystemId: provingSystem,
 			Proof:           ProofByteArray,
 			PubInput:        PubInputByteArray,
 			VerificationKey: VerificationKeyByteArray,
-			VmProgramCode:   []byte(""),
 		}}
 	}
-	byteArray, err := json.Marshal(data)
+	byteArray, err := common.MarshalBatch(data, batchFormat)
 	if err != nil {
 		return [32]byte{}, "", err
 	}
-	merkleRoot := sha256.Sum256(byteArray)
-	batchDataPointer, err := uploadObjectToS3(byteArray, merkleRoot)
+	// Operators check the downloaded batch against this root before signing it
+	merkleRoot, err := operator.BatchMerkleRoot(data)
+	if err != nil {
+		return [32]byte{}, "", err
+	}
+	batchDataPointer, err := uploadObjectToS3(byteArray, merkleRoot, batchFormat)
 	if err != nil {
 		return [32]byte{}, "", err
 	}
@@ -229,7 +251,7 @@ func getAndUploadProofData(c *cli.Context, x int) ([32]byte, string, error) {
 	return merkleRoot, batchDataPointer, nil
 }
 
-func uploadObjectToS3(byteArray []byte, merkleRoot [32]byte) (string, error) {
+func uploadObjectToS3(byteArray []byte, merkleRoot [32]byte, batchFormat common.BatchFormat) (string, error) {
 	// I want to upload the bytearray to my S3 bucket, with merkleRoot as the object name
 	err := godotenv.Load("./task_sender/.env")
 	if err != nil {
@@ -257,6 +279,9 @@ func uploadObjectToS3(byteArray []byte, merkleRoot [32]byte) (string, error) {
 
 	merkleRootHex := hex.EncodeToString(merkleRoot[:])
 	key := merkleRootHex + ".json"
+	if batchFormat == common.BatchFormatBinary {
+		key = merkleRootHex + ".bin"
+	}
 
 	// This uploads the contents to S3
 	_, err = svc.PutObject(&s3.PutObjectInput{
@@ -313,22 +338,10 @@ func taskSenderInfiniteMain(c *cli.Context) error {
 }
 
 func ParseProvingSystem(provingSystemStr string) (common.ProvingSystemId, error) {
-	provingSystemStr = strings.TrimSpace(provingSystemStr)
-	switch provingSystemStr {
-	case "plonk_bls12_381":
-		return common.GnarkPlonkBls12_381, nil
-	case "plonk_bn254":
-		return common.GnarkPlonkBn254, nil
-	case "groth16_bn254":
-		return common.Groth16Bn254, nil
-	case "sp1":
-		return common.SP1, nil
-	case "halo2_kzg":
-		return common.Halo2KZG, nil
-	case "halo2_ipa":
-		return common.Halo2IPA, nil
-	default:
+	verifier, err := common.GetVerifierByAlias(strings.TrimSpace(provingSystemStr))
+	if err != nil {
 		var unknownValue common.ProvingSystemId
-		return unknownValue, fmt.Errorf("unsupported proving system: %s", provingSystemStr)
+		return unknownValue, err
 	}
+	return verifier.ProvingSystemId(), nil
 }